@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog"
+
+	"github.com/bookingcom/shipper/pkg/client"
+	"github.com/bookingcom/shipper/pkg/webhook"
+)
+
+var (
+	masterURL  = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
+	kubeconfig = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+	certPath   = flag.String("webhook-cert", "", "Path to the TLS certificate for the webhook.")
+	keyPath    = flag.String("webhook-key", "", "Path to the TLS private key for the webhook.")
+	bindAddr   = flag.String("webhook-addr", "0.0.0.0", "Addr to bind the webhook.")
+	bindPort   = flag.String("webhook-port", "9443", "Port to bind the webhook.")
+)
+
+// shipper-pod-labeller runs in application clusters, and labels new
+// pods of a release according to the traffic decision the traffic controller
+// publishes on the release's anchor.
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	klog.Infof("Starting shipper-pod-labeller on %s:%s", *bindAddr, *bindPort)
+	defer klog.Info("Stopping shipper-pod-labeller")
+
+	restCfg, err := clientcmd.BuildConfigFromFlags(*masterURL, *kubeconfig)
+	if err != nil {
+		klog.Fatal(err)
+	}
+
+	kubeClient := client.NewKubeClientOrDie(restCfg, webhook.PodLabellingAgentName, nil)
+
+	stopCh := setupSignalHandler()
+
+	resync := time.Second * 0
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, resync)
+
+	c := webhook.NewPodLabellingWebhook(
+		*bindAddr,
+		*bindPort,
+		*keyPath,
+		*certPath,
+		kubeInformerFactory,
+	)
+
+	kubeInformerFactory.Start(stopCh)
+
+	c.Run(stopCh)
+}
+
+func setupSignalHandler() <-chan struct{} {
+	stopCh := make(chan struct{})
+
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigCh
+		close(stopCh)
+		<-sigCh
+		os.Exit(1) // Second signal. Exit directly.
+	}()
+
+	return stopCh
+}
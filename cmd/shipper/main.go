@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"net/http"
 	"os"
@@ -23,11 +24,14 @@ import (
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	kuberestmetrics "k8s.io/client-go/tools/metrics"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 
+	"github.com/bookingcom/shipper/pkg/analysis"
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	"github.com/bookingcom/shipper/pkg/chart/repo"
 	"github.com/bookingcom/shipper/pkg/client"
@@ -36,9 +40,11 @@ import (
 	"github.com/bookingcom/shipper/pkg/clusterclientstore"
 	"github.com/bookingcom/shipper/pkg/controller/application"
 	"github.com/bookingcom/shipper/pkg/controller/capacity"
+	"github.com/bookingcom/shipper/pkg/controller/cluster"
 	"github.com/bookingcom/shipper/pkg/controller/installation"
 	"github.com/bookingcom/shipper/pkg/controller/janitor"
 	"github.com/bookingcom/shipper/pkg/controller/metrics"
+	"github.com/bookingcom/shipper/pkg/controller/notification"
 	"github.com/bookingcom/shipper/pkg/controller/release"
 	"github.com/bookingcom/shipper/pkg/controller/rolloutblock"
 	"github.com/bookingcom/shipper/pkg/controller/traffic"
@@ -54,14 +60,29 @@ var controllers = []string{
 	"capacity",
 	"traffic",
 	"rolloutblock",
+	"cluster",
 	"janitor",
 	"webhook",
 	"metrics",
+	"notification",
 }
 
 const defaultRESTTimeout time.Duration = 10 * time.Second
 const defaultResync time.Duration = 0 * time.Second
 const defaultHeartbeat time.Duration = 5 * time.Second
+const defaultLeaseDuration time.Duration = 15 * time.Second
+const defaultRenewDeadline time.Duration = 10 * time.Second
+const defaultRetryPeriod time.Duration = 2 * time.Second
+const defaultClusterProbePeriod time.Duration = 30 * time.Second
+
+// Rebalancing modes. In dry-run mode, the release controller marks releases
+// that would move to other clusters, but the application controller does not
+// act on it.
+const (
+	rebalanceOff    = "off"
+	rebalanceDryRun = "dry-run"
+	rebalanceOn     = "on"
+)
 
 var (
 	masterURL           = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
@@ -78,7 +99,19 @@ var (
 	webhookKeyPath      = flag.String("webhook-key", "", "Path to the TLS private key for the webhook controller.")
 	webhookBindAddr     = flag.String("webhook-addr", "0.0.0.0", "Addr to bind the webhook controller.")
 	webhookBindPort     = flag.String("webhook-port", "9443", "Port to bind the webhook controller.")
+	webhookShipperUser  = flag.String("webhook-shipper-user", "system:serviceaccount:"+shipper.ShipperNamespace+":"+shipper.ShipperManagementServiceAccount, "The user Shipper makes requests to the management cluster as. The webhook lets it copy the audit trail of rollout block overrides from releases to applications.")
 	heartbeatPeriod     = flag.Duration("metrics-webhook-heartbeat-period", defaultHeartbeat, "time between two heartbeats of validating webhook")
+	leaderElect         = flag.Bool("leader-elect", false, "Run controllers only while holding the leader lease, so that several replicas of Shipper can run at the same time.")
+	leaseNamespace      = flag.String("leader-elect-namespace", shipper.ShipperNamespace, "Namespace of the lease object used for leader election.")
+	leaseName           = flag.String("leader-elect-name", "shipper", "Name of the lease object used for leader election.")
+	leaseDuration       = flag.Duration("leader-elect-lease-duration", defaultLeaseDuration, "Duration that non-leader replicas will wait before trying to acquire an expired lease.")
+	renewDeadline       = flag.Duration("leader-elect-renew-deadline", defaultRenewDeadline, "Duration that the leader will retry refreshing the lease before giving up leadership.")
+	retryPeriod         = flag.Duration("leader-elect-retry-period", defaultRetryPeriod, "Duration replicas should wait between attempts to acquire or renew the lease.")
+	prometheusURL       = flag.String("analysis-prometheus-url", "", "Address of the Prometheus server used to run strategy step analysis. Steps with analysis can not progress if unset.")
+	clusterProbePeriod  = flag.Duration("cluster-probe-period", defaultClusterProbePeriod, "Time between two health checks of an application cluster.")
+	notificationsConfig = flag.String("notifications-config", "", "Path to the configuration of the endpoints rollout notifications are sent to. No notifications are sent if unset.")
+	reportPodDetails    = flag.Bool("capacity-report-pod-details", false, "Attach the Warning events of pods that aren't ready, and the end of the logs of their crash-looping containers, to capacity target reports. Logs may hold secrets, and events are watched in every namespace of application clusters.")
+	rebalanceMode       = flag.String("rebalance", rebalanceOff, "Whether to move releases away from clusters that can no longer take them: \"off\", \"dry-run\" (only report the releases that would move) or \"on\".")
 )
 
 type metricsCfg struct {
@@ -108,6 +141,8 @@ type cfg struct {
 	chartVersionResolver repo.ChartVersionResolver
 	chartFetcher         repo.ChartFetcher
 
+	analysisClient analysis.QueryClient
+
 	certPath, keyPath string
 	ns                string
 	workers           int
@@ -118,13 +153,37 @@ type cfg struct {
 	wg     *sync.WaitGroup
 	stopCh <-chan struct{}
 
+	// electedControllers are only run while this replica holds the leader
+	// lease. They are started with a stop channel that gets closed as soon
+	// as leadership is lost.
+	electedControllers []func(stopCh <-chan struct{})
+	leaderElection     *leaderElectionCfg
+
 	metrics *metricsCfg
 }
 
+type leaderElectionCfg struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	identity  string
+
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+}
+
 func main() {
 	klog.InitFlags(nil)
 	flag.Parse()
 
+	switch *rebalanceMode {
+	case rebalanceOff, rebalanceDryRun, rebalanceOn:
+	default:
+		klog.Fatalf("invalid -rebalance mode %q: must be one of %q, %q or %q",
+			*rebalanceMode, rebalanceOff, rebalanceDryRun, rebalanceOn)
+	}
+
 	baseRestCfg, err := clientcmd.BuildConfigFromFlags(*masterURL, *kubeconfig)
 	if err != nil {
 		klog.Fatal(err)
@@ -222,6 +281,29 @@ func main() {
 		},
 	}
 
+	if *prometheusURL != "" {
+		klog.V(1).Infof("Strategy step analysis will query Prometheus at %q", *prometheusURL)
+		cfg.analysisClient = analysis.NewPrometheusClient(*prometheusURL, *restTimeout)
+	}
+
+	if *leaderElect {
+		identity, err := os.Hostname()
+		if err != nil {
+			klog.Fatalf("could not determine leader election identity: %s", err)
+		}
+
+		cfg.leaderElection = &leaderElectionCfg{
+			client:    client.NewKubeClientOrDie(baseRestCfg, "leader-election", restTimeout),
+			namespace: *leaseNamespace,
+			name:      *leaseName,
+			identity:  identity,
+
+			leaseDuration: *leaseDuration,
+			renewDeadline: *renewDeadline,
+			retryPeriod:   *retryPeriod,
+		}
+	}
+
 	go func() {
 		klog.V(1).Infof("Metrics will listen on %s", *metricsAddr)
 		<-metricsReadyCh
@@ -249,6 +331,9 @@ func runMetrics(cfg *metricsCfg) {
 	prometheus.MustRegister(cfg.certExpire.GetMetrics()...)
 	prometheus.MustRegister(instrumentedclient.GetMetrics()...)
 	prometheus.MustRegister(cfg.metricsBundle.TimeToInstallation)
+	prometheus.MustRegister(release.GetMetrics()...)
+	prometheus.MustRegister(notification.GetMetrics()...)
+	prometheus.MustRegister(cluster.GetMetrics()...)
 
 	srv := http.Server{
 		Addr: *metricsAddr,
@@ -314,7 +399,7 @@ func runControllers(cfg *cfg) {
 		started, err := initializer(cfg)
 		// TODO make it visible when some controller's aren't starting properly; all of the initializers return 'nil' ATM
 		if err != nil {
-			klog.Fatalf("%q failed to initialize", name)
+			klog.Fatalf("%q failed to initialize: %s", name, err)
 		}
 
 		if !started {
@@ -329,6 +414,19 @@ func runControllers(cfg *cfg) {
 	go cfg.kubeInformerFactory.Start(cfg.stopCh)
 	go cfg.shipperInformerFactory.Start(cfg.stopCh)
 
+	// Informers, the cluster client store and the webhook run on every
+	// replica, so a standby is ready to take over as soon as it acquires
+	// the lease. Everything that writes is only started by the leader.
+	cfg.wg.Add(1)
+	go func() {
+		if cfg.leaderElection == nil {
+			startElectedControllers(cfg, cfg.stopCh).Wait()
+		} else {
+			runLeaderElection(cfg)
+		}
+		cfg.wg.Done()
+	}()
+
 	doneCh := make(chan struct{})
 
 	go func() {
@@ -340,6 +438,99 @@ func runControllers(cfg *cfg) {
 	klog.Info("Controllers have shut down")
 }
 
+func startElectedControllers(cfg *cfg, stopCh <-chan struct{}) *sync.WaitGroup {
+	wg := &sync.WaitGroup{}
+	for _, run := range cfg.electedControllers {
+		wg.Add(1)
+		go func(run func(<-chan struct{})) {
+			run(stopCh)
+			wg.Done()
+		}(run)
+	}
+
+	return wg
+}
+
+func runLeaderElection(cfg *cfg) {
+	lecfg := cfg.leaderElection
+
+	lock, err := resourcelock.New(
+		resourcelock.LeasesResourceLock,
+		lecfg.namespace,
+		lecfg.name,
+		lecfg.client.CoreV1(),
+		lecfg.client.CoordinationV1(),
+		resourcelock.ResourceLockConfig{
+			Identity:      lecfg.identity,
+			EventRecorder: cfg.recorder("shipper-leader-election"),
+		},
+	)
+	if err != nil {
+		klog.Fatalf("could not create leader election lock: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-cfg.stopCh
+		cancel()
+	}()
+
+	// OnStartedLeading runs in its own goroutine, so it might only get
+	// scheduled after the elector has already given up. The mutex and the
+	// stopped flag make sure we never start controllers at that point, and
+	// that we always wait for the ones we did start.
+	var (
+		mu          sync.Mutex
+		stopped     bool
+		controllers *sync.WaitGroup
+	)
+
+	klog.Infof("Waiting to acquire lease %s/%s as %q", lecfg.namespace, lecfg.name, lecfg.identity)
+
+	// We deliberately do not set ReleaseOnCancel: the lease would then be
+	// released before our controllers have finished their last sync, and
+	// another replica could start writing while we still are.
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: lecfg.leaseDuration,
+		RenewDeadline: lecfg.renewDeadline,
+		RetryPeriod:   lecfg.retryPeriod,
+		Name:          lecfg.name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				if stopped {
+					return
+				}
+
+				klog.Infof("Acquired lease %s/%s, starting controllers", lecfg.namespace, lecfg.name)
+				controllers = startElectedControllers(cfg, leaderCtx.Done())
+			},
+			OnStoppedLeading: func() {
+				mu.Lock()
+				stopped = true
+				mu.Unlock()
+			},
+		},
+	})
+
+	if controllers != nil {
+		controllers.Wait()
+	}
+
+	select {
+	case <-cfg.stopCh:
+		klog.Info("Leader election has shut down")
+	default:
+		// Controllers and their workqueues can't be restarted once
+		// stopped, so the cleanest way back into the election is to start
+		// over as a fresh standby.
+		klog.Fatalf("Lost lease %s/%s, controllers have been stopped", lecfg.namespace, lecfg.name)
+	}
+}
+
 func setupSignalHandler() <-chan struct{} {
 	stopCh := make(chan struct{})
 
@@ -366,9 +557,11 @@ func buildInitializers() map[string]initFunc {
 	controllers["capacity"] = startCapacityController
 	controllers["traffic"] = startTrafficController
 	controllers["rolloutblock"] = startRolloutBlockController
+	controllers["cluster"] = startClusterController
 	controllers["janitor"] = startJanitorController
 	controllers["webhook"] = startWebhook
 	controllers["metrics"] = startMetricsController
+	controllers["notification"] = startNotificationController
 	return controllers
 }
 
@@ -382,14 +575,13 @@ func startApplicationController(cfg *cfg) (bool, error) {
 		client.NewShipperClientOrDie(cfg.restCfg, application.AgentName, cfg.restTimeout),
 		cfg.shipperInformerFactory,
 		cfg.chartVersionResolver,
+		*rebalanceMode == rebalanceOn,
 		cfg.recorder(application.AgentName),
 	)
 
-	cfg.wg.Add(1)
-	go func() {
-		c.Run(cfg.workers, cfg.stopCh)
-		cfg.wg.Done()
-	}()
+	cfg.electedControllers = append(cfg.electedControllers, func(stopCh <-chan struct{}) {
+		c.Run(cfg.workers, stopCh)
+	})
 
 	return true, nil
 }
@@ -404,14 +596,14 @@ func startReleaseController(cfg *cfg) (bool, error) {
 		client.NewShipperClientOrDie(cfg.restCfg, release.AgentName, cfg.restTimeout),
 		cfg.shipperInformerFactory,
 		cfg.chartFetcher,
+		cfg.analysisClient,
+		*rebalanceMode != rebalanceOff,
 		cfg.recorder(release.AgentName),
 	)
 
-	cfg.wg.Add(1)
-	go func() {
-		c.Run(cfg.workers, cfg.stopCh)
-		cfg.wg.Done()
-	}()
+	cfg.electedControllers = append(cfg.electedControllers, func(stopCh <-chan struct{}) {
+		c.Run(cfg.workers, stopCh)
+	})
 
 	return true, nil
 }
@@ -446,11 +638,9 @@ func startInstallationController(cfg *cfg) (bool, error) {
 		cfg.recorder(installation.AgentName),
 	)
 
-	cfg.wg.Add(1)
-	go func() {
-		c.Run(cfg.workers, cfg.stopCh)
-		cfg.wg.Done()
-	}()
+	cfg.electedControllers = append(cfg.electedControllers, func(stopCh <-chan struct{}) {
+		c.Run(cfg.workers, stopCh)
+	})
 
 	return true, nil
 }
@@ -466,12 +656,11 @@ func startCapacityController(cfg *cfg) (bool, error) {
 		cfg.shipperInformerFactory,
 		cfg.store,
 		cfg.recorder(capacity.AgentName),
+		*reportPodDetails,
 	)
-	cfg.wg.Add(1)
-	go func() {
-		c.Run(cfg.workers, cfg.stopCh)
-		cfg.wg.Done()
-	}()
+	cfg.electedControllers = append(cfg.electedControllers, func(stopCh <-chan struct{}) {
+		c.Run(cfg.workers, stopCh)
+	})
 	return true, nil
 }
 
@@ -481,18 +670,28 @@ func startTrafficController(cfg *cfg) (bool, error) {
 		return false, nil
 	}
 
+	dynamicClientBuilderFunc := func(clusterName string, restConfig *rest.Config) (dynamic.Interface, error) {
+		// The config is shared with every other client of the cluster,
+		// so it can't be changed in place.
+		config := rest.CopyConfig(restConfig)
+		if cfg.restTimeout != nil {
+			config.Timeout = *cfg.restTimeout
+		}
+
+		return dynamic.NewForConfig(config)
+	}
+
 	c := traffic.NewController(
 		client.NewShipperClientOrDie(cfg.restCfg, traffic.AgentName, cfg.restTimeout),
 		cfg.shipperInformerFactory,
 		cfg.store,
 		cfg.recorder(traffic.AgentName),
+		dynamicClientBuilderFunc,
 	)
 
-	cfg.wg.Add(1)
-	go func() {
-		c.Run(cfg.workers, cfg.stopCh)
-		cfg.wg.Done()
-	}()
+	cfg.electedControllers = append(cfg.electedControllers, func(stopCh <-chan struct{}) {
+		c.Run(cfg.workers, stopCh)
+	})
 
 	return true, nil
 }
@@ -509,11 +708,30 @@ func startRolloutBlockController(cfg *cfg) (bool, error) {
 		cfg.recorder(rolloutblock.AgentName),
 	)
 
-	cfg.wg.Add(1)
-	go func() {
-		c.Run(cfg.workers, cfg.stopCh)
-		cfg.wg.Done()
-	}()
+	cfg.electedControllers = append(cfg.electedControllers, func(stopCh <-chan struct{}) {
+		c.Run(cfg.workers, stopCh)
+	})
+
+	return true, nil
+}
+
+func startClusterController(cfg *cfg) (bool, error) {
+	enabled := cfg.enabledControllers["cluster"]
+	if !enabled {
+		return false, nil
+	}
+
+	c := cluster.NewController(
+		client.NewShipperClientOrDie(cfg.restCfg, cluster.AgentName, cfg.restTimeout),
+		cfg.shipperInformerFactory,
+		cfg.store,
+		cfg.recorder(cluster.AgentName),
+		*clusterProbePeriod,
+	)
+
+	cfg.electedControllers = append(cfg.electedControllers, func(stopCh <-chan struct{}) {
+		c.Run(cfg.workers, stopCh)
+	})
 
 	return true, nil
 }
@@ -530,11 +748,36 @@ func startMetricsController(cfg *cfg) (bool, error) {
 		cfg.metrics.metricsBundle,
 	)
 
-	cfg.wg.Add(1)
-	go func() {
-		c.Run(cfg.stopCh)
-		cfg.wg.Done()
-	}()
+	cfg.electedControllers = append(cfg.electedControllers, func(stopCh <-chan struct{}) {
+		c.Run(stopCh)
+	})
+
+	return true, nil
+}
+
+func startNotificationController(cfg *cfg) (bool, error) {
+	enabled := cfg.enabledControllers["notification"]
+	if !enabled || *notificationsConfig == "" {
+		return false, nil
+	}
+
+	config, err := notification.LoadConfig(*notificationsConfig)
+	if err != nil {
+		return false, err
+	}
+
+	c, err := notification.NewController(
+		cfg.shipperInformerFactory,
+		config,
+		instrumentedclient.DefaultClient,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	cfg.electedControllers = append(cfg.electedControllers, func(stopCh <-chan struct{}) {
+		c.Run(cfg.workers, stopCh)
+	})
 
 	return true, nil
 }
@@ -553,7 +796,10 @@ func startWebhook(cfg *cfg) (bool, error) {
 		client.NewShipperClientOrDie(cfg.restCfg, webhook.AgentName, cfg.restTimeout),
 		cfg.shipperInformerFactory,
 		*cfg.metrics.certExpire,
-		*heartbeatPeriod)
+		*heartbeatPeriod,
+		*webhookShipperUser,
+		cfg.chartVersionResolver,
+		cfg.chartFetcher)
 
 	cfg.wg.Add(1)
 	go func() {
@@ -577,11 +823,9 @@ func startJanitorController(cfg *cfg) (bool, error) {
 		cfg.recorder(janitor.AgentName),
 	)
 
-	cfg.wg.Add(1)
-	go func() {
-		c.Run(cfg.workers, cfg.stopCh)
-		cfg.wg.Done()
-	}()
+	cfg.electedControllers = append(cfg.electedControllers, func(stopCh <-chan struct{}) {
+		c.Run(cfg.workers, stopCh)
+	})
 
 	return true, nil
 }
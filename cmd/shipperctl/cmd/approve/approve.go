@@ -0,0 +1,88 @@
+package approve
+
+import (
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/bookingcom/shipper/cmd/shipperctl/config"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+const (
+	kubeConfigFlagName = "kubeconfig"
+)
+
+var (
+	kubeConfigFile           string
+	managementClusterContext string
+	namespace                string
+	step                     int32
+
+	Cmd = &cobra.Command{
+		Use:   "approve",
+		Short: "approve Shipper objects",
+	}
+
+	approveReleaseCmd = &cobra.Command{
+		Use:   "release NAME",
+		Short: "approve a strategy step of a release",
+		Long: "records your approval of a strategy step of a release, so that the " +
+			"release can progress to it once it has enough approvals. Shipper records " +
+			"you as the user Kubernetes authenticates you as.",
+		Args: cobra.ExactArgs(1),
+		RunE: runApproveReleaseCommand,
+	}
+)
+
+func init() {
+	config.RegisterFlag(Cmd.PersistentFlags(), &kubeConfigFile)
+	if err := Cmd.MarkPersistentFlagFilename(kubeConfigFlagName, "yaml"); err != nil {
+		Cmd.Printf("warning: could not mark %q for filename autocompletion: %s\n", kubeConfigFlagName, err)
+	}
+
+	Cmd.PersistentFlags().StringVar(&managementClusterContext, "management-cluster-context", "", "The name of the context to use to communicate with the management cluster. defaults to the current one")
+	Cmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "default", "The namespace of the object to approve")
+
+	approveReleaseCmd.Flags().Int32Var(&step, "step", -1, "The strategy step to approve. defaults to the step following the current target step")
+
+	Cmd.AddCommand(approveReleaseCmd)
+}
+
+func runApproveReleaseCommand(cmd *cobra.Command, args []string) error {
+	_, shipperClient, err := config.Load(kubeConfigFile, managementClusterContext)
+	if err != nil {
+		return err
+	}
+
+	name := args[0]
+	rel, err := shipperClient.ShipperV1alpha1().Releases(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	approvedStep := step
+	if approvedStep < 0 {
+		approvedStep = rel.Spec.TargetStep + 1
+	}
+
+	if err := releaseutil.AddApproval(rel, approvedStep, metav1.Now()); err != nil {
+		return err
+	}
+
+	// The webhook fills in who approved the step, so we only get to know
+	// it from the release it returns.
+	rel, err = shipperClient.ShipperV1alpha1().Releases(namespace).Update(rel)
+	if err != nil {
+		return err
+	}
+
+	strategyStep := releaseutil.Strategy(rel).Steps[approvedStep]
+	user := rel.Status.Approvals[len(rel.Status.Approvals)-1].User
+	cmd.Printf(
+		"step %q of release %s/%s approved by %s (%d/%d approvals)\n",
+		strategyStep.Name, namespace, name, user,
+		len(releaseutil.StepApprovers(rel, approvedStep)), strategyStep.Approvals,
+	)
+
+	return nil
+}
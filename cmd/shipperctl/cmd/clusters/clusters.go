@@ -34,6 +34,7 @@ var (
 	applicationClusterServiceAccount string
 
 	webhookFailurePolicyIgnore bool
+	podLabellingWebhookImage   string
 
 	setupCmd = &cobra.Command{
 		Use:   "setup",
@@ -63,7 +64,8 @@ const (
 	kubeConfigFlagName = "kubeconfig"
 	level1Padding      = "    "
 
-	validatingWebhookName = "shipper-validating-webhook"
+	validatingWebhookName   = "shipper-validating-webhook"
+	podLabellingWebhookName = configurator.PodLabellingWebhookName
 
 	managementClusterRoleName         = "shipper:management-cluster"
 	managementClusterRoleBindingName  = "shipper:management-cluster"
@@ -89,6 +91,8 @@ func init() {
 
 	joinCmd.Flags().StringVar(&applicationClusterServiceAccount, "application-cluster-service-account", shipper.ShipperApplicationServiceAccount, "the name of the service account Shipper will use for the application cluster")
 
+	joinCmd.Flags().StringVar(&podLabellingWebhookImage, "pod-labelling-webhook-image", "", "the Shipper image to run the pod labelling webhook from in application clusters. the webhook is not deployed if unset")
+
 	joinCmd.Flags().StringVarP(&clustersYaml, fileFlagName, "f", "clusters.yaml", "the path to an YAML file containing application cluster configuration")
 	err := joinCmd.MarkFlagFilename(fileFlagName, "yaml")
 	if err != nil {
@@ -160,6 +164,11 @@ func runJoinClustersCommand(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	err = updateFailurePolicyForMutatingWebhook(cmd, mgmtConfigurator)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -188,7 +197,7 @@ func setupManagementCluster(cmd *cobra.Command, configurator *configurator.Clust
 		return err
 	}
 
-	if err := createValidatingWebhookSecret(cmd, configurator); err != nil {
+	if err := createWebhookSecret(cmd, configurator, validatingWebhookName); err != nil {
 		return err
 	}
 
@@ -196,6 +205,10 @@ func setupManagementCluster(cmd *cobra.Command, configurator *configurator.Clust
 		return err
 	}
 
+	if err := createMutatingWebhookConfiguration(cmd, configurator); err != nil {
+		return err
+	}
+
 	if err := createValidatingWebhookService(cmd, configurator); err != nil {
 		return err
 	}
@@ -216,6 +229,18 @@ func setupApplicationCluster(cmd *cobra.Command, configurator *configurator.Clus
 		return err
 	}
 
+	if podLabellingWebhookImage == "" {
+		return nil
+	}
+
+	if err := createWebhookSecret(cmd, configurator, podLabellingWebhookName); err != nil {
+		return err
+	}
+
+	if err := createPodLabellingWebhook(cmd, configurator); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -335,10 +360,12 @@ func createManagementServiceAccount(cmd *cobra.Command, configurator *configurat
 	return nil
 }
 
-func createValidatingWebhookSecret(cmd *cobra.Command, configurator *configurator.Cluster) error {
-	cmd.Printf("Checking if a secret already exists for the validating webhook in the %s namespace... ", shipperNamespace)
+// createWebhookSecret has Kubernetes sign a TLS certificate for the Service
+// of a webhook, and stores it in a Secret named after it.
+func createWebhookSecret(cmd *cobra.Command, configurator *configurator.Cluster, webhookName string) error {
+	cmd.Printf("Checking if a secret already exists for %s in the %s namespace... ", webhookName, shipperNamespace)
 
-	exists, err := configurator.ValidatingWebhookSecretExists(shipperNamespace)
+	exists, err := configurator.WebhookSecretExists(webhookName, shipperNamespace)
 	if err != nil {
 		return err
 	}
@@ -349,7 +376,7 @@ func createValidatingWebhookSecret(cmd *cobra.Command, configurator *configurato
 	}
 	cmd.Println("no.")
 
-	cmd.Println("Creating a secret for the validating webhook:")
+	cmd.Printf("Creating a secret for %s:\n", webhookName)
 
 	cmd.Printf("%sGenerating a private key... ", level1Padding)
 	privatekey, err := rsa.GenerateKey(rand.Reader, 2048)
@@ -359,26 +386,26 @@ func createValidatingWebhookSecret(cmd *cobra.Command, configurator *configurato
 	cmd.Println("done")
 
 	cmd.Printf("%sCreating a TLS certificate signing request... ", level1Padding)
-	csr, err := tls.GenerateCSRForServiceInNamespace(privatekey, validatingWebhookName, shipperNamespace)
+	csr, err := tls.GenerateCSRForServiceInNamespace(privatekey, webhookName, shipperNamespace)
 	if err != nil {
 		return err
 	}
 	cmd.Println("done")
 
 	cmd.Printf("%sCreating a Kubernetes CertificateSigningRequest... ", level1Padding)
-	if err := configurator.CreateCertificateSigningRequest(csr); err != nil {
+	if err := configurator.CreateCertificateSigningRequest(webhookName, csr); err != nil {
 		return err
 	}
 	cmd.Println("done")
 
 	cmd.Printf("%sApproving the CertificateSigningRequest... ", level1Padding)
-	if err := configurator.ApproveShipperCSR(); err != nil {
+	if err := configurator.ApproveShipperCSR(webhookName); err != nil {
 		return err
 	}
 	cmd.Println("done")
 
 	cmd.Printf("%sFetching the certificate from the CertificateSigningRequest object... ", level1Padding)
-	certificate, err := configurator.FetchCertificateFromCSR()
+	certificate, err := configurator.FetchCertificateFromCSR(webhookName)
 	if err != nil {
 		return err
 	}
@@ -390,7 +417,7 @@ func createValidatingWebhookSecret(cmd *cobra.Command, configurator *configurato
 	privatekeyPEM := tls.EncodePrivateKeyAsPEM(x509.MarshalPKCS1PrivateKey(privatekey))
 
 	cmd.Printf("%sCreating the Secret using the private key and certificate in the %s namespace... ", level1Padding, shipperNamespace)
-	if err := configurator.CreateValidatingWebhookSecret(privatekeyPEM, certificate, shipperNamespace); err != nil {
+	if err := configurator.CreateWebhookSecret(webhookName, privatekeyPEM, certificate, shipperNamespace); err != nil {
 		return err
 	}
 	cmd.Println("done")
@@ -418,6 +445,36 @@ func createValidatingWebhookConfiguration(cmd *cobra.Command, configurator *conf
 	return nil
 }
 
+func createMutatingWebhookConfiguration(cmd *cobra.Command, configurator *configurator.Cluster) error {
+	cmd.Printf("Creating the MutatingWebhookConfiguration in %s namespace... ", shipperNamespace)
+	caBundle, err := configurator.FetchKubernetesCABundle()
+	if err != nil {
+		return err
+	}
+
+	if err := configurator.CreateOrUpdateMutatingWebhookConfiguration(caBundle, shipperNamespace, webhookFailurePolicyIgnore); err != nil {
+		if errors.IsAlreadyExists(err) {
+			cmd.Println("already exists. Skipping")
+			return nil
+		}
+
+		return err
+	}
+	cmd.Println("done")
+
+	return nil
+}
+
+func updateFailurePolicyForMutatingWebhook(cmd *cobra.Command, configurator *configurator.Cluster) error {
+	cmd.Printf("Updating the failure policy of the MutatingWebhookConfiguration in %s namespace... ", shipperNamespace)
+	if err := configurator.UpdateMutatingWebhookConfigurationFailurePolicyToFail(); err != nil {
+		return err
+	}
+	cmd.Println("done")
+
+	return nil
+}
+
 func updateFailurePolicyForValidatingWebhook(cmd *cobra.Command, configurator *configurator.Cluster) error {
 	cmd.Printf("Updating the failure policy of the ValidatingWebhookConfiguration in %s namespace... ", shipperNamespace)
 	if err := configurator.UpdateValidatingWebhookConfigurationFailurePolicyToFail(); err != nil {
@@ -443,6 +500,35 @@ func createValidatingWebhookService(cmd *cobra.Command, configurator *configurat
 	return nil
 }
 
+// createPodLabellingWebhook runs the pod labelling webhook in an application
+// cluster, using the application cluster service account.
+func createPodLabellingWebhook(cmd *cobra.Command, configurator *configurator.Cluster) error {
+	cmd.Printf("Creating a Deployment for the pod labelling webhook from %s... ", podLabellingWebhookImage)
+	if err := configurator.CreateOrUpdatePodLabellingWebhookDeployment(podLabellingWebhookImage, shipperNamespace, applicationClusterServiceAccount); err != nil {
+		return err
+	}
+	cmd.Println("done")
+
+	cmd.Print("Creating a Service object for the pod labelling webhook... ")
+	if err := configurator.CreateOrUpdatePodLabellingWebhookService(shipperNamespace); err != nil {
+		return err
+	}
+	cmd.Println("done")
+
+	cmd.Printf("Creating the MutatingWebhookConfiguration for the pod labelling webhook in %s namespace... ", shipperNamespace)
+	caBundle, err := configurator.FetchKubernetesCABundle()
+	if err != nil {
+		return err
+	}
+
+	if err := configurator.CreateOrUpdatePodLabellingWebhookConfiguration(caBundle, shipperNamespace); err != nil {
+		return err
+	}
+	cmd.Println("done")
+
+	return nil
+}
+
 func createApplicationServiceAccount(cmd *cobra.Command, configurator *configurator.Cluster) error {
 	cmd.Printf("Creating a service account called %s... ", applicationClusterServiceAccount)
 
@@ -54,12 +54,23 @@ var (
 		Short: "list Shipper *releases* that are scheduled *only* on given clusters",
 		RunE:  runCountReleasesCommand,
 	}
+
+	listRebalancingCmd = &cobra.Command{
+		Use:   "rebalancing",
+		Short: "list Shipper *contenders* that would be moved to other clusters when rebalancing",
+		Long: `list Shipper *contenders* that would be moved to other clusters when rebalancing.
+When --clusters is set, only contenders moving away from at least one of those clusters are listed.
+This works with Shipper running with -rebalance=dry-run as well as -rebalance=on.`,
+		RunE: runListRebalancingCommand,
+	}
 )
 
 type outputRelease struct {
 	Namespace string `json:"namespace"`
 	Name      string `json:"name"`
 	Clusters  string `json:"clusters"`
+
+	RebalancedClusters string `json:"rebalancedClusters,omitempty"`
 }
 
 func init() {
@@ -75,7 +86,8 @@ func init() {
 
 	Cmd.AddCommand(countContendersCmd)
 	Cmd.AddCommand(countReleasesCmd)
-	for _, command := range []*cobra.Command{countContendersCmd, countReleasesCmd} {
+	Cmd.AddCommand(listRebalancingCmd)
+	for _, command := range []*cobra.Command{countContendersCmd, countReleasesCmd, listRebalancingCmd} {
 		command.SetOutput(os.Stdout)
 	}
 }
@@ -172,6 +184,105 @@ func runCountReleasesCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runListRebalancingCommand(cmd *cobra.Command, args []string) error {
+	kubeClient, shipperClient, err := config.Load(kubeConfigFile, managementClusterContext)
+	if err != nil {
+		return err
+	}
+
+	namespaceList, err := kubeClient.CoreV1().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var errList []string
+	rebalancingReleases := []outputRelease{}
+	for _, ns := range namespaceList.Items {
+		applicationList, err := shipperClient.ShipperV1alpha1().Applications(ns.Name).List(metav1.ListOptions{})
+		if err != nil {
+			errList = append(errList, err.Error())
+			continue
+		}
+		for _, app := range applicationList.Items {
+			contender, err := release.GetContender(&app, shipperClient)
+			if err != nil {
+				errList = append(errList, err.Error())
+				continue
+			}
+			rebalancedAnnotation, ok := contender.Annotations[shipper.ReleaseRebalancedClustersAnnotation]
+			if !ok || rebalancedAnnotation == "" {
+				continue
+			}
+			clustersAnnotation := contender.Annotations[shipper.ReleaseClustersAnnotation]
+			if len(clusters) > 0 && !movesAwayFrom(
+				strings.Split(clustersAnnotation, ","),
+				strings.Split(rebalancedAnnotation, ","),
+				clusters,
+			) {
+				continue
+			}
+			rebalancingReleases = append(
+				rebalancingReleases,
+				outputRelease{
+					Namespace:          contender.Namespace,
+					Name:               contender.Name,
+					Clusters:           clustersAnnotation,
+					RebalancedClusters: rebalancedAnnotation,
+				})
+		}
+	}
+
+	printRebalancingReleases(cmd.OutOrStdout(), rebalancingReleases)
+
+	if len(errList) > 0 {
+		return fmt.Errorf(strings.Join(errList, ", "))
+	}
+	return nil
+}
+
+// movesAwayFrom returns whether any of the given clusters is in current but
+// not in rebalanced.
+func movesAwayFrom(current, rebalanced, clusters []string) bool {
+	for _, cluster := range clusters {
+		if contains(current, cluster) && !contains(rebalanced, cluster) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func printRebalancingReleases(stdout io.Writer, outputReleases []outputRelease) {
+	if printOption != "" {
+		printCountedRelease(stdout, outputReleases)
+		return
+	}
+
+	tbl := table.New(
+		"NAMESPACE",
+		"NAME",
+		"CLUSTERS ANNOTATION",
+		"REBALANCED CLUSTERS",
+	)
+	for _, release := range outputReleases {
+		tbl.AddRow(
+			release.Namespace,
+			release.Name,
+			release.Clusters,
+			release.RebalancedClusters,
+		)
+	}
+
+	tbl.Print()
+}
+
 func printCountedRelease(stdout io.Writer, outputReleases []outputRelease) {
 	var err error
 	var data []byte
@@ -26,11 +26,11 @@ import (
 )
 
 const (
-	shipperCSRName                      = "shipper-validating-webhook"
-	shipperValidatingWebhookSecretName  = "shipper-validating-webhook"
 	shipperValidatingWebhookName        = "shipper.booking.com"
 	shipperValidatingWebhookServiceName = "shipper-validating-webhook"
 	shipperValidatingWebhookServicePath = "/validate"
+	shipperMutatingWebhookName          = "shipper.booking.com"
+	shipperMutatingWebhookServicePath   = "/mutate"
 	MaximumRetries                      = 20
 	AgentName                           = "configurator"
 )
@@ -99,6 +99,11 @@ func (c *Cluster) CreateClusterRole(domain, name string) error {
 				APIGroups: []string{""},
 				Resources: []string{"namespaces"},
 			},
+			rbacv1.PolicyRule{
+				Verbs:     []string{"create", "get", "update"},
+				APIGroups: []string{"coordination.k8s.io"},
+				Resources: []string{"leases"},
+			},
 		},
 	}
 
@@ -291,10 +296,10 @@ func NewClusterConfigurator(restConfig *rest.Config) (*Cluster, error) {
 	return configurator, nil
 }
 
-func (c *Cluster) CreateCertificateSigningRequest(csr []byte) error {
+func (c *Cluster) CreateCertificateSigningRequest(name string, csr []byte) error {
 	certificateSigningRequest := &certificatesv1beta1.CertificateSigningRequest{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: shipperCSRName,
+			Name: name,
 		},
 		Spec: certificatesv1beta1.CertificateSigningRequestSpec{
 			Request: csr,
@@ -311,8 +316,8 @@ func (c *Cluster) CreateCertificateSigningRequest(csr []byte) error {
 	return err
 }
 
-func (c *Cluster) ApproveShipperCSR() error {
-	csr, err := c.KubeClient.CertificatesV1beta1().CertificateSigningRequests().Get(shipperCSRName, metav1.GetOptions{})
+func (c *Cluster) ApproveShipperCSR(name string) error {
+	csr, err := c.KubeClient.CertificatesV1beta1().CertificateSigningRequests().Get(name, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
@@ -329,14 +334,14 @@ func (c *Cluster) ApproveShipperCSR() error {
 	return err
 }
 
-// FetchCertificateFromCSR continually fetches the Shipper CSR until
+// FetchCertificateFromCSR continually fetches a Shipper CSR until
 // it is populated with a certificate and then returns the PEM-encoded
 // certificate from the Status. This is a blocking function.
 //
 // Note that the returned certificate is already PEM-encoded.
-func (c *Cluster) FetchCertificateFromCSR() ([]byte, error) {
+func (c *Cluster) FetchCertificateFromCSR(name string) ([]byte, error) {
 	for retries := 0; retries < MaximumRetries; retries++ {
-		csr, err := c.KubeClient.CertificatesV1beta1().CertificateSigningRequests().Get(shipperCSRName, metav1.GetOptions{})
+		csr, err := c.KubeClient.CertificatesV1beta1().CertificateSigningRequests().Get(name, metav1.GetOptions{})
 		if err != nil {
 			return nil, err
 		}
@@ -354,8 +359,8 @@ func (c *Cluster) FetchCertificateFromCSR() ([]byte, error) {
 	return nil, fmt.Errorf("certificate is not populated after %d retries", MaximumRetries)
 }
 
-func (c *Cluster) ValidatingWebhookSecretExists(namespace string) (bool, error) {
-	_, err := c.KubeClient.CoreV1().Secrets(namespace).Get(shipperValidatingWebhookSecretName, metav1.GetOptions{})
+func (c *Cluster) WebhookSecretExists(name, namespace string) (bool, error) {
+	_, err := c.KubeClient.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return false, nil
@@ -367,10 +372,10 @@ func (c *Cluster) ValidatingWebhookSecretExists(namespace string) (bool, error)
 	return true, nil
 }
 
-func (c *Cluster) CreateValidatingWebhookSecret(privateKey, certificate []byte, namespace string) error {
+func (c *Cluster) CreateWebhookSecret(name string, privateKey, certificate []byte, namespace string) error {
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: shipperValidatingWebhookSecretName,
+			Name: name,
 		},
 		Type: corev1.SecretTypeTLS,
 		Data: map[string][]byte{
@@ -473,6 +478,77 @@ func (c *Cluster) UpdateValidatingWebhookConfigurationFailurePolicyToFail() erro
 	return err
 }
 
+func (c *Cluster) CreateOrUpdateMutatingWebhookConfiguration(caBundle []byte, namespace string, setToIgnore bool) error {
+	path := shipperMutatingWebhookServicePath
+	sideEffectClassNone := admissionregistrationv1beta1.SideEffectClassNone
+	failurePolicy := admissionregistrationv1beta1.Fail
+	if setToIgnore {
+		failurePolicy = admissionregistrationv1beta1.Ignore
+	}
+	mutatingWebhookConfiguration := &admissionregistrationv1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: shipperMutatingWebhookName,
+		},
+		Webhooks: []admissionregistrationv1beta1.MutatingWebhook{
+			admissionregistrationv1beta1.MutatingWebhook{
+				Name: shipperMutatingWebhookName,
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					CABundle: caBundle,
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Name:      shipperValidatingWebhookServiceName,
+						Namespace: namespace,
+						Path:      &path,
+					},
+				},
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					admissionregistrationv1beta1.RuleWithOperations{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+							admissionregistrationv1beta1.Update,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups:   []string{shipper.SchemeGroupVersion.Group},
+							APIVersions: []string{shipper.SchemeGroupVersion.Version},
+							Resources:   []string{"applications", "releases"},
+						},
+					},
+				},
+				SideEffects:   &sideEffectClassNone,
+				FailurePolicy: &failurePolicy,
+			},
+		},
+	}
+
+	existingConfig, err := c.KubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(shipperMutatingWebhookName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			_, err = c.KubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Create(mutatingWebhookConfiguration)
+			return err
+		} else {
+			return err
+		}
+	}
+
+	existingConfig.Webhooks = mutatingWebhookConfiguration.Webhooks
+	_, err = c.KubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Update(existingConfig)
+	return err
+}
+
+func (c *Cluster) UpdateMutatingWebhookConfigurationFailurePolicyToFail() error {
+	policyTypeFail := admissionregistrationv1beta1.Fail
+	existingConfig, err := c.KubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(shipperMutatingWebhookName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	for i := range existingConfig.Webhooks {
+		existingConfig.Webhooks[i].FailurePolicy = &policyTypeFail
+	}
+
+	_, err = c.KubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Update(existingConfig)
+	return err
+}
+
 func (c *Cluster) CreateOrUpdateValidatingWebhookService(namespace string) error {
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
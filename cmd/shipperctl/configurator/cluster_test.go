@@ -81,6 +81,68 @@ func TestUpdateValidatingWebhookConfiguration(t *testing.T) {
 	shippertesting.CheckActions(f.actions, actualActions, f.t)
 }
 
+func TestCreateMutatingWebhookConfiguration(t *testing.T) {
+	f := newFixture(t)
+	caBundle := []byte{}
+	if err := f.configurator.CreateOrUpdateMutatingWebhookConfiguration(caBundle, shipperSystemNamespace, true); err != nil {
+		t.Fatal(err)
+	}
+
+	clientSet, ok := f.configurator.KubeClient.(*kubefake.Clientset)
+	if !ok {
+		t.Fatalf("not a *kubefake.Clientset: %#v", f.configurator.KubeClient)
+	}
+	actualActions := shippertesting.FilterActions(clientSet.Actions())
+
+	configuration, err := clientSet.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(shipperMutatingWebhookName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gvr := admissionregistrationv1beta1.SchemeGroupVersion.WithResource("mutatingwebhookconfigurations")
+	getAction := kubetesting.NewGetAction(gvr, "", shipperMutatingWebhookName)
+	createAction := kubetesting.NewCreateAction(gvr, "", configuration)
+	f.actions = append(f.actions, getAction, createAction)
+	shippertesting.CheckActions(f.actions, actualActions, f.t)
+
+	webhook := configuration.Webhooks[0]
+	if path := *webhook.ClientConfig.Service.Path; path != shipperMutatingWebhookServicePath {
+		t.Errorf("expected the webhook to be served at %q, got %q", shipperMutatingWebhookServicePath, path)
+	}
+	if *webhook.FailurePolicy != admissionregistrationv1beta1.Ignore {
+		t.Errorf("expected failure policy %q, got %q", admissionregistrationv1beta1.Ignore, *webhook.FailurePolicy)
+	}
+}
+
+func TestCreatePodLabellingWebhookConfiguration(t *testing.T) {
+	f := newFixture(t)
+	caBundle := []byte{}
+	if err := f.configurator.CreateOrUpdatePodLabellingWebhookConfiguration(caBundle, shipperSystemNamespace); err != nil {
+		t.Fatal(err)
+	}
+
+	clientSet, ok := f.configurator.KubeClient.(*kubefake.Clientset)
+	if !ok {
+		t.Fatalf("not a *kubefake.Clientset: %#v", f.configurator.KubeClient)
+	}
+
+	configuration, err := clientSet.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(shipperPodLabellingWebhookConfigurationName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	webhook := configuration.Webhooks[0]
+	if service := webhook.ClientConfig.Service.Name; service != PodLabellingWebhookName {
+		t.Errorf("expected the webhook to be served by service %q, got %q", PodLabellingWebhookName, service)
+	}
+	if resources := webhook.Rules[0].Resources; len(resources) != 1 || resources[0] != "pods" {
+		t.Errorf("expected the webhook to only apply to pods, got %v", resources)
+	}
+	if *webhook.FailurePolicy != admissionregistrationv1beta1.Ignore {
+		t.Errorf("expected failure policy %q, got %q", admissionregistrationv1beta1.Ignore, *webhook.FailurePolicy)
+	}
+}
+
 func TestCreateValidatingWebhookService(t *testing.T) {
 	f := newFixture(t)
 	if err := f.configurator.CreateOrUpdateValidatingWebhookService(shipperSystemNamespace); err != nil {
@@ -0,0 +1,203 @@
+package configurator
+
+import (
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+const (
+	// PodLabellingWebhookName names the Deployment, Service, Secret and
+	// CertificateSigningRequest of the pod labelling webhook in an
+	// application cluster.
+	PodLabellingWebhookName = "shipper-pod-labelling-webhook"
+
+	shipperPodLabellingWebhookConfigurationName = "pod-labelling.shipper.booking.com"
+	shipperPodLabellingWebhookServicePath       = "/mutate"
+	shipperPodLabellingWebhookCertsPath         = "/etc/webhook/certs"
+)
+
+// CreateOrUpdatePodLabellingWebhookDeployment runs the pod labelling webhook
+// from image, as serviceAccount, which needs to be able to watch ConfigMaps
+// in every namespace.
+func (c *Cluster) CreateOrUpdatePodLabellingWebhookDeployment(image, namespace, serviceAccount string) error {
+	labels := map[string]string{
+		"app": PodLabellingWebhookName,
+	}
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PodLabellingWebhookName,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						corev1.Container{
+							Name:    "shipper-pod-labeller",
+							Image:   image,
+							Command: []string{"shipper-pod-labeller"},
+							Args: []string{
+								"-webhook-cert", shipperPodLabellingWebhookCertsPath + "/" + corev1.TLSCertKey,
+								"-webhook-key", shipperPodLabellingWebhookCertsPath + "/" + corev1.TLSPrivateKeyKey,
+								"-webhook-port", "9443",
+								"-logtostderr",
+							},
+							Ports: []corev1.ContainerPort{
+								corev1.ContainerPort{
+									Name:          "webhook",
+									ContainerPort: 9443,
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								corev1.VolumeMount{
+									Name:      "webhook-certs",
+									MountPath: shipperPodLabellingWebhookCertsPath,
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					ServiceAccountName: serviceAccount,
+					Volumes: []corev1.Volume{
+						corev1.Volume{
+							Name: "webhook-certs",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: PodLabellingWebhookName,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	existingDeployment, err := c.KubeClient.AppsV1().Deployments(namespace).Get(PodLabellingWebhookName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			_, err = c.KubeClient.AppsV1().Deployments(namespace).Create(deployment)
+			return err
+		} else {
+			return err
+		}
+	}
+
+	existingDeployment.Spec = deployment.Spec
+	_, err = c.KubeClient.AppsV1().Deployments(namespace).Update(existingDeployment)
+	return err
+}
+
+func (c *Cluster) CreateOrUpdatePodLabellingWebhookService(namespace string) error {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PodLabellingWebhookName,
+			Namespace: namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				"app": PodLabellingWebhookName,
+			},
+			Ports: []corev1.ServicePort{
+				corev1.ServicePort{
+					Port:       443,
+					TargetPort: intstr.FromInt(9443),
+				},
+			},
+		},
+	}
+
+	existingService, err := c.KubeClient.CoreV1().Services(namespace).Get(PodLabellingWebhookName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			_, err = c.KubeClient.CoreV1().Services(namespace).Create(service)
+			return err
+		} else {
+			return err
+		}
+	}
+
+	existingService.Spec.Selector = service.Spec.Selector
+	existingService.Spec.Ports = service.Spec.Ports
+	_, err = c.KubeClient.CoreV1().Services(namespace).Update(existingService)
+	return err
+}
+
+// CreateOrUpdatePodLabellingWebhookConfiguration sends new pods of releases to
+// the pod labelling webhook. Its failure policy is always Ignore: pods must
+// keep being created when the webhook is not around, they'll just be left for
+// the traffic controller to label.
+func (c *Cluster) CreateOrUpdatePodLabellingWebhookConfiguration(caBundle []byte, namespace string) error {
+	path := shipperPodLabellingWebhookServicePath
+	sideEffectClassNone := admissionregistrationv1beta1.SideEffectClassNone
+	failurePolicy := admissionregistrationv1beta1.Ignore
+	mutatingWebhookConfiguration := &admissionregistrationv1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: shipperPodLabellingWebhookConfigurationName,
+		},
+		Webhooks: []admissionregistrationv1beta1.MutatingWebhook{
+			admissionregistrationv1beta1.MutatingWebhook{
+				Name: shipperPodLabellingWebhookConfigurationName,
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					CABundle: caBundle,
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Name:      PodLabellingWebhookName,
+						Namespace: namespace,
+						Path:      &path,
+					},
+				},
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					admissionregistrationv1beta1.RuleWithOperations{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups:   []string{corev1.SchemeGroupVersion.Group},
+							APIVersions: []string{corev1.SchemeGroupVersion.Version},
+							Resources:   []string{"pods"},
+						},
+					},
+				},
+				ObjectSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						metav1.LabelSelectorRequirement{
+							Key:      shipper.ReleaseLabel,
+							Operator: metav1.LabelSelectorOpExists,
+						},
+					},
+				},
+				SideEffects:   &sideEffectClassNone,
+				FailurePolicy: &failurePolicy,
+			},
+		},
+	}
+
+	existingConfig, err := c.KubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(shipperPodLabellingWebhookConfigurationName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			_, err = c.KubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Create(mutatingWebhookConfiguration)
+			return err
+		} else {
+			return err
+		}
+	}
+
+	existingConfig.Webhooks = mutatingWebhookConfiguration.Webhooks
+	_, err = c.KubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Update(existingConfig)
+	return err
+}
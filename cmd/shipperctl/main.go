@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"github.com/bookingcom/shipper/cmd/shipperctl/cmd/approve"
 	"github.com/bookingcom/shipper/cmd/shipperctl/cmd/chart"
 	"github.com/bookingcom/shipper/cmd/shipperctl/cmd/clean"
 	"github.com/bookingcom/shipper/cmd/shipperctl/cmd/clusters"
@@ -26,6 +27,7 @@ func init() {
 	rootCmd.AddCommand(clean.Cmd)
 	rootCmd.AddCommand(backup.Cmd)
 	rootCmd.AddCommand(chart.Command)
+	rootCmd.AddCommand(approve.Cmd)
 }
 
 func main() {
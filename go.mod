@@ -9,6 +9,7 @@ require (
 	github.com/aokoli/goutils v1.0.1 // indirect
 	github.com/cespare/xxhash v1.1.0
 	github.com/cyphar/filepath-securejoin v0.2.2 // indirect
+	github.com/evanphx/json-patch v4.9.0+incompatible
 	github.com/gobwas/glob v0.2.2 // indirect
 	github.com/google/go-cmp v0.4.0
 	github.com/huandu/xstrings v0.0.0-20171208101919-37469d0c81a7 // indirect
@@ -0,0 +1,211 @@
+package analysis
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+const (
+	DefaultInterval = time.Minute
+	DefaultCount    = 1
+)
+
+// QueryClient evaluates queries against a metrics provider. Queries are
+// expected to evaluate to a single value at the given time.
+type QueryClient interface {
+	Query(query string, ts time.Time) (float64, error)
+}
+
+// Result summarizes the state of all the analysis checks of a strategy step.
+type Result struct {
+	// Passed is true when every check has succeeded.
+	Passed bool
+	// Failed points to the status of the first check that failed, if any.
+	Failed *shipper.AnalysisStatus
+	// NextMeasurement is how long until another measurement is due.
+	NextMeasurement time.Duration
+}
+
+// Validate checks that an analysis is well formed.
+func Validate(check shipper.RolloutStepAnalysis) error {
+	if check.Name == "" {
+		return shippererrors.NewInvalidAnalysisError(check.Name, fmt.Errorf("name must not be empty"))
+	}
+
+	if check.Query == "" {
+		return shippererrors.NewInvalidAnalysisError(check.Name, fmt.Errorf("query must not be empty"))
+	}
+
+	switch check.Operator {
+	case shipper.AnalysisOperatorLessThan,
+		shipper.AnalysisOperatorLessThanOrEqual,
+		shipper.AnalysisOperatorGreaterThan,
+		shipper.AnalysisOperatorGreaterThanOrEqual:
+	default:
+		return shippererrors.NewInvalidAnalysisError(check.Name, fmt.Errorf("unknown operator %q", check.Operator))
+	}
+
+	if _, err := interval(check); err != nil {
+		return err
+	}
+
+	if check.Count < 0 || check.FailureLimit < 0 {
+		return shippererrors.NewInvalidAnalysisError(check.Name, fmt.Errorf("count and failureLimit must not be negative"))
+	}
+
+	return nil
+}
+
+// Evaluate compares a measurement with the threshold of a check.
+func Evaluate(check shipper.RolloutStepAnalysis, value float64) bool {
+	switch check.Operator {
+	case shipper.AnalysisOperatorLessThan:
+		return value < check.Threshold
+	case shipper.AnalysisOperatorLessThanOrEqual:
+		return value <= check.Threshold
+	case shipper.AnalysisOperatorGreaterThan:
+		return value > check.Threshold
+	case shipper.AnalysisOperatorGreaterThanOrEqual:
+		return value >= check.Threshold
+	}
+
+	return false
+}
+
+// Run takes a measurement for every check of a strategy step that is due
+// one at the given time, and returns the updated statuses along with the
+// overall result for the step. Statuses of other steps are preserved.
+func Run(
+	client QueryClient,
+	step int32,
+	checks []shipper.RolloutStepAnalysis,
+	statuses []shipper.AnalysisStatus,
+	now time.Time,
+) (Result, []shipper.AnalysisStatus, error) {
+	result := Result{Passed: true}
+	newStatuses := make([]shipper.AnalysisStatus, len(statuses))
+	copy(newStatuses, statuses)
+
+	for _, check := range checks {
+		if err := Validate(check); err != nil {
+			return Result{}, statuses, err
+		}
+
+		i := findStatus(newStatuses, step, check.Name)
+		if i < 0 {
+			newStatuses = append(newStatuses, shipper.AnalysisStatus{
+				Step:  step,
+				Name:  check.Name,
+				Phase: shipper.AnalysisPhaseRunning,
+			})
+			i = len(newStatuses) - 1
+		}
+		status := &newStatuses[i]
+
+		if status.Phase == shipper.AnalysisPhaseRunning {
+			next, err := measure(client, check, status, now)
+			if err != nil {
+				return Result{}, statuses, err
+			}
+
+			if next > 0 && (result.NextMeasurement == 0 || next < result.NextMeasurement) {
+				result.NextMeasurement = next
+			}
+		}
+
+		switch status.Phase {
+		case shipper.AnalysisPhaseFailed:
+			result.Passed = false
+			if result.Failed == nil {
+				failed := *status
+				result.Failed = &failed
+			}
+		case shipper.AnalysisPhaseRunning:
+			result.Passed = false
+		}
+	}
+
+	return result, newStatuses, nil
+}
+
+// measure queries the metrics provider for a running check, if its
+// interval has elapsed since the last measurement, and moves it to a final
+// phase once it has succeeded or failed enough times. It returns how long
+// until the next measurement is due.
+func measure(client QueryClient, check shipper.RolloutStepAnalysis, status *shipper.AnalysisStatus, now time.Time) (time.Duration, error) {
+	interval, err := interval(check)
+	if err != nil {
+		return 0, err
+	}
+
+	if !status.LastMeasurementTime.IsZero() {
+		if elapsed := now.Sub(status.LastMeasurementTime.Time); elapsed < interval {
+			return interval - elapsed, nil
+		}
+	}
+
+	value, err := client.Query(check.Query, now)
+	if err != nil {
+		return 0, shippererrors.NewAnalysisQueryError(check.Query, err)
+	}
+
+	status.LastValue = strconv.FormatFloat(value, 'g', -1, 64)
+	status.LastMeasurementTime = metav1.NewTime(now)
+
+	if Evaluate(check, value) {
+		status.Successes++
+	} else {
+		status.Failures++
+		status.Message = fmt.Sprintf(
+			"query %q returned %s, expected %s %s",
+			check.Query, status.LastValue, check.Operator,
+			strconv.FormatFloat(check.Threshold, 'g', -1, 64),
+		)
+	}
+
+	count := check.Count
+	if count == 0 {
+		count = DefaultCount
+	}
+
+	if status.Failures > check.FailureLimit {
+		status.Phase = shipper.AnalysisPhaseFailed
+		return 0, nil
+	} else if status.Successes >= count {
+		status.Phase = shipper.AnalysisPhaseSuccessful
+		return 0, nil
+	}
+
+	return interval, nil
+}
+
+func interval(check shipper.RolloutStepAnalysis) (time.Duration, error) {
+	if check.Interval == "" {
+		return DefaultInterval, nil
+	}
+
+	d, err := time.ParseDuration(check.Interval)
+	if err != nil {
+		return 0, shippererrors.NewInvalidAnalysisError(check.Name, err)
+	} else if d <= 0 {
+		return 0, shippererrors.NewInvalidAnalysisError(check.Name, fmt.Errorf("interval must be positive"))
+	}
+
+	return d, nil
+}
+
+func findStatus(statuses []shipper.AnalysisStatus, step int32, name string) int {
+	for i, status := range statuses {
+		if status.Step == step && status.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
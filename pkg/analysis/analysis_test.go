@@ -0,0 +1,166 @@
+package analysis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+type fakeQueryClient struct {
+	values  map[string]float64
+	queries int
+}
+
+func (c *fakeQueryClient) Query(query string, ts time.Time) (float64, error) {
+	c.queries++
+	value, ok := c.values[query]
+	if !ok {
+		return 0, fmt.Errorf("unknown query %q", query)
+	}
+	return value, nil
+}
+
+func buildCheck(name, query string, count, failureLimit int32) shipper.RolloutStepAnalysis {
+	return shipper.RolloutStepAnalysis{
+		Name:         name,
+		Query:        query,
+		Operator:     shipper.AnalysisOperatorLessThan,
+		Threshold:    0.1,
+		Interval:     "1m",
+		Count:        count,
+		FailureLimit: failureLimit,
+	}
+}
+
+func TestRunPassesAfterCount(t *testing.T) {
+	client := &fakeQueryClient{values: map[string]float64{"errors": 0.05}}
+	checks := []shipper.RolloutStepAnalysis{buildCheck("errors", "errors", 2, 0)}
+	now := time.Now()
+
+	result, statuses, err := Run(client, 0, checks, nil, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Passed || result.Failed != nil || result.NextMeasurement != time.Minute {
+		t.Fatalf("expected analysis to be running after one measurement, got %+v", result)
+	}
+
+	// Not enough time has passed for another measurement.
+	result, statuses, err = Run(client, 0, checks, statuses, now.Add(30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.queries != 1 || result.NextMeasurement != 30*time.Second {
+		t.Fatalf("expected no measurement to be taken, got %d queries and %+v", client.queries, result)
+	}
+
+	result, statuses, err = Run(client, 0, checks, statuses, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Passed {
+		t.Fatalf("expected analysis to pass, got %+v", result)
+	}
+	if statuses[0].Phase != shipper.AnalysisPhaseSuccessful || statuses[0].Successes != 2 {
+		t.Fatalf("unexpected status %+v", statuses[0])
+	}
+}
+
+func TestRunFailsAfterFailureLimit(t *testing.T) {
+	client := &fakeQueryClient{values: map[string]float64{"errors": 0.5}}
+	checks := []shipper.RolloutStepAnalysis{buildCheck("errors", "errors", 1, 1)}
+	now := time.Now()
+
+	result, statuses, err := Run(client, 0, checks, nil, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Passed || result.Failed != nil {
+		t.Fatalf("expected a failure to be tolerated, got %+v", result)
+	}
+
+	result, statuses, err = Run(client, 0, checks, statuses, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Failed == nil {
+		t.Fatalf("expected analysis to fail, got %+v", result)
+	}
+
+	expectedMsg := `query "errors" returned 0.5, expected < 0.1`
+	if result.Failed.Message != expectedMsg {
+		t.Fatalf("expected message %q, got %q", expectedMsg, result.Failed.Message)
+	}
+	if statuses[0].Phase != shipper.AnalysisPhaseFailed || statuses[0].Failures != 2 {
+		t.Fatalf("unexpected status %+v", statuses[0])
+	}
+}
+
+func TestRunKeepsOtherSteps(t *testing.T) {
+	client := &fakeQueryClient{values: map[string]float64{"errors": 0.05}}
+	checks := []shipper.RolloutStepAnalysis{buildCheck("errors", "errors", 1, 0)}
+	previous := []shipper.AnalysisStatus{
+		{
+			Step:                0,
+			Name:                "errors",
+			Phase:               shipper.AnalysisPhaseSuccessful,
+			Successes:           1,
+			LastMeasurementTime: metav1.Now(),
+		},
+	}
+
+	result, statuses, err := Run(client, 1, checks, previous, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Passed || len(statuses) != 2 || statuses[1].Step != 1 {
+		t.Fatalf("expected a new status for step 1, got %+v and %+v", result, statuses)
+	}
+}
+
+func TestRunQueryError(t *testing.T) {
+	client := &fakeQueryClient{values: map[string]float64{}}
+	checks := []shipper.RolloutStepAnalysis{buildCheck("errors", "errors", 1, 0)}
+
+	_, statuses, err := Run(client, 0, checks, nil, time.Now())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(statuses) != 0 {
+		t.Fatalf("expected statuses to be left alone, got %+v", statuses)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := buildCheck("errors", "errors", 1, 0)
+
+	noOperator := valid
+	noOperator.Operator = ""
+
+	badInterval := valid
+	badInterval.Interval = "-1m"
+
+	noQuery := valid
+	noQuery.Query = ""
+
+	tests := []struct {
+		name        string
+		check       shipper.RolloutStepAnalysis
+		expectedErr bool
+	}{
+		{"valid", valid, false},
+		{"no operator", noOperator, true},
+		{"bad interval", badInterval, true},
+		{"no query", noQuery, true},
+	}
+
+	for _, tt := range tests {
+		if err := Validate(tt.check); (err != nil) != tt.expectedErr {
+			t.Fatalf("%s: expected error %t, got %v", tt.name, tt.expectedErr, err)
+		}
+	}
+}
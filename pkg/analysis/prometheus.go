@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusClient is a QueryClient backed by the HTTP API of Prometheus.
+type PrometheusClient struct {
+	address string
+	client  *http.Client
+}
+
+var _ QueryClient = (*PrometheusClient)(nil)
+
+func NewPrometheusClient(address string, timeout time.Duration) *PrometheusClient {
+	return &PrometheusClient{
+		address: strings.TrimSuffix(address, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+type prometheusSample struct {
+	Value []interface{} `json:"value"`
+}
+
+// Query runs an instant query. The query has to evaluate either to a scalar
+// or to a vector holding exactly one sample.
+func (c *PrometheusClient) Query(query string, ts time.Time) (float64, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("time", strconv.FormatInt(ts.Unix(), 10))
+
+	resp, err := c.client.Get(fmt.Sprintf("%s/api/v1/query?%s", c.address, params.Encode()))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	var promResp prometheusResponse
+	if err := json.Unmarshal(body, &promResp); err != nil {
+		return 0, fmt.Errorf("unexpected response from prometheus (HTTP %d): %s", resp.StatusCode, err)
+	}
+
+	if promResp.Status != "success" {
+		return 0, fmt.Errorf("prometheus returned %s: %s", promResp.ErrorType, promResp.Error)
+	}
+
+	var value []interface{}
+	switch promResp.Data.ResultType {
+	case "scalar":
+		if err := json.Unmarshal(promResp.Data.Result, &value); err != nil {
+			return 0, err
+		}
+	case "vector":
+		var samples []prometheusSample
+		if err := json.Unmarshal(promResp.Data.Result, &samples); err != nil {
+			return 0, err
+		}
+		if len(samples) != 1 {
+			return 0, fmt.Errorf("expected query to return exactly one sample, got %d", len(samples))
+		}
+		value = samples[0].Value
+	default:
+		return 0, fmt.Errorf("unsupported result type %q", promResp.Data.ResultType)
+	}
+
+	// Values come as a [timestamp, "value"] pair.
+	if len(value) != 2 {
+		return 0, fmt.Errorf("malformed sample %v", value)
+	}
+
+	str, ok := value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("malformed sample value %v", value[1])
+	}
+
+	return strconv.ParseFloat(str, 64)
+}
@@ -0,0 +1,64 @@
+package analysis
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newFakePrometheus(t *testing.T, responses map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			t.Errorf("unexpected request path %q", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		resp, ok := responses[r.URL.Query().Get("query")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"unknown query"}`)
+			return
+		}
+
+		fmt.Fprint(w, resp)
+	}))
+}
+
+func TestPrometheusClientQuery(t *testing.T) {
+	srv := newFakePrometheus(t, map[string]string{
+		"scalar(error_rate)": `{"status":"success","data":{"resultType":"scalar","result":[1600000000,"0.25"]}}`,
+		"error_rate":         `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000,"0.5"]}]}}`,
+		"empty":              `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		"many":               `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000,"1"]},{"metric":{},"value":[1600000000,"2"]}]}}`,
+		"matrix":             `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+	})
+	defer srv.Close()
+
+	client := NewPrometheusClient(srv.URL+"/", time.Second)
+
+	tests := []struct {
+		query       string
+		expected    float64
+		expectedErr bool
+	}{
+		{"scalar(error_rate)", 0.25, false},
+		{"error_rate", 0.5, false},
+		{"empty", 0, true},
+		{"many", 0, true},
+		{"matrix", 0, true},
+		{"unknown", 0, true},
+	}
+
+	for _, tt := range tests {
+		value, err := client.Query(tt.query, time.Now())
+		if (err != nil) != tt.expectedErr {
+			t.Fatalf("query %q: expected error %t, got %v", tt.query, tt.expectedErr, err)
+		}
+		if value != tt.expected {
+			t.Fatalf("query %q: expected %g, got %g", tt.query, tt.expected, value)
+		}
+	}
+}
@@ -30,10 +30,35 @@ const (
 	ReleaseTemplateIterationAnnotation = "shipper.booking.com/release.template.iteration"
 	ReleaseClustersAnnotation          = "shipper.booking.com/release.clusters"
 
+	// ReleaseRebalancedClustersAnnotation is set on complete releases that
+	// would be scheduled on other clusters if they were created now, e.g.
+	// because one of their clusters was drained or reweighted.
+	ReleaseRebalancedClustersAnnotation = "shipper.booking.com/release.clusters.rebalanced"
+
+	// AppRebalanceAnnotation opts an application into having its
+	// releases moved to other clusters when rebalancing is enabled. Its
+	// only meaningful value is "true".
+	AppRebalanceAnnotation = "shipper.booking.com/app.rebalance"
+
 	SecretClusterSkipTlsVerifyAnnotation = "shipper.booking.com/cluster-secret.insecure-tls-skip-verify"
 
+	// HPAChartMinReplicasAnnotation and HPAChartMaxReplicasAnnotation
+	// record the replica bounds of a HorizontalPodAutoscaler as rendered
+	// from the chart. The capacity controller scales the actual bounds
+	// in proportion to the capacity of the release.
+	HPAChartMinReplicasAnnotation = "shipper.booking.com/hpa.chart.minReplicas"
+	HPAChartMaxReplicasAnnotation = "shipper.booking.com/hpa.chart.maxReplicas"
+
 	RolloutBlocksOverrideAnnotation = "shipper.booking.com/rollout-block.override"
 
+	// RolloutBlocksOverrideJustificationAnnotation explains why rollout
+	// blocks are being overridden, and is required to add overrides.
+	// The author and timestamp annotations are set by the webhook
+	// whenever overrides are added.
+	RolloutBlocksOverrideJustificationAnnotation = "shipper.booking.com/rollout-block.override.justification"
+	RolloutBlocksOverrideAuthorAnnotation        = "shipper.booking.com/rollout-block.override.author"
+	RolloutBlocksOverrideTimestampAnnotation     = "shipper.booking.com/rollout-block.override.timestamp"
+
 	LBLabel         = "shipper-lb"
 	LBForProduction = "production"
 
@@ -147,6 +172,7 @@ type ClusterList struct {
 type ClusterSpec struct {
 	Capabilities []string                 `json:"capabilities"`
 	Region       string                   `json:"region"`
+	Zone         string                   `json:"zone,omitempty"`
 	APIMaster    string                   `json:"apiMaster"`
 	Scheduler    ClusterSchedulerSettings `json:"scheduler"`
 }
@@ -157,10 +183,35 @@ type ClusterSchedulerSettings struct {
 	Identity      *string `json:"identity,omitempty"`
 }
 
-// NOTE(btyler) when we introduce capacity based scheduling, the capacity can
-// be collected by a cluster controller and stored in cluster.status
 type ClusterStatus struct {
+	// InService is whether the cluster is healthy enough for new releases
+	// to be scheduled on it, as last probed by the cluster controller.
 	InService bool `json:"inService"`
+
+	// ServerVersion is the Kubernetes version the cluster runs.
+	ServerVersion string `json:"serverVersion,omitempty"`
+
+	// Capacity is the amount of resources in the cluster, as last
+	// aggregated by the cluster controller. It is used by the scheduler to
+	// avoid placing releases on clusters they wouldn't fit in.
+	Capacity *ClusterCapacity `json:"capacity,omitempty"`
+
+	Conditions []ClusterCondition `json:"conditions,omitempty"`
+}
+
+// ClusterCapacity is the sum of the resources of the schedulable nodes in a
+// cluster, and of the resources requested by the pods running on them.
+type ClusterCapacity struct {
+	Allocatable corev1.ResourceList `json:"allocatable,omitempty"`
+	Requested   corev1.ResourceList `json:"requested,omitempty"`
+}
+
+type ClusterCondition struct {
+	Type               ClusterConditionType   `json:"type"`
+	Status             corev1.ConditionStatus `json:"status"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
 }
 
 // +genclient
@@ -196,20 +247,59 @@ type ReleaseStatus struct {
 	AchievedStep *AchievedStep          `json:"achievedStep,omitempty"`
 	Strategy     *ReleaseStrategyStatus `json:"strategy,omitempty"`
 	Conditions   []ReleaseCondition     `json:"conditions,omitempty"`
+	Analysis     []AnalysisStatus       `json:"analysis,omitempty"`
+	Approvals    []StepApproval         `json:"approvals,omitempty"`
+}
+
+// StepApproval records that a user approved a strategy step. The user is
+// recorded by the webhook as whoever added the approval, and approvals are
+// never removed.
+type StepApproval struct {
+	Step int32       `json:"step"`
+	User string      `json:"user"`
+	Time metav1.Time `json:"time,omitempty"`
+}
+
+type AnalysisPhase string
+
+const (
+	AnalysisPhaseRunning    AnalysisPhase = "Running"
+	AnalysisPhaseSuccessful AnalysisPhase = "Successful"
+	AnalysisPhaseFailed     AnalysisPhase = "Failed"
+)
+
+// AnalysisStatus reports the measurements taken so far for one of the
+// analysis checks of a strategy step.
+type AnalysisStatus struct {
+	Step  int32         `json:"step"`
+	Name  string        `json:"name"`
+	Phase AnalysisPhase `json:"phase"`
+
+	Successes int32 `json:"successes"`
+	Failures  int32 `json:"failures"`
+
+	LastValue           string      `json:"lastValue,omitempty"`
+	LastMeasurementTime metav1.Time `json:"lastMeasurementTime,omitempty"`
+	Message             string      `json:"message,omitempty"`
 }
 
 type AchievedStep struct {
 	Step int32  `json:"step"`
 	Name string `json:"name"`
+
+	// AchievedTime is when the release first achieved this step.
+	AchievedTime metav1.Time `json:"achievedTime,omitempty"`
 }
 
 type ReleaseConditionType string
 
 const (
-	ReleaseConditionTypeScheduled        ReleaseConditionType = "Scheduled"
-	ReleaseConditionTypeStrategyExecuted ReleaseConditionType = "StrategyExecuted"
-	ReleaseConditionTypeComplete         ReleaseConditionType = "Complete"
-	ReleaseConditionTypeBlocked          ReleaseConditionType = "Blocked"
+	ReleaseConditionTypeScheduled                ReleaseConditionType = "Scheduled"
+	ReleaseConditionTypeStrategyExecuted         ReleaseConditionType = "StrategyExecuted"
+	ReleaseConditionTypeComplete                 ReleaseConditionType = "Complete"
+	ReleaseConditionTypeBlocked                  ReleaseConditionType = "Blocked"
+	ReleaseConditionTypeAborted                  ReleaseConditionType = "Aborted"
+	ReleaseConditionTypeProgressDeadlineExceeded ReleaseConditionType = "ProgressDeadlineExceeded"
 )
 
 type ReleaseCondition struct {
@@ -231,32 +321,193 @@ type ReleaseEnvironment struct {
 	ClusterRequirements ClusterRequirements `json:"clusterRequirements"`
 
 	Strategy *RolloutStrategy `json:"strategy,omitempty"`
+
+	// TrafficBackend is how traffic is shifted between the releases of
+	// the application. Pod labels are used if unset.
+	TrafficBackend TrafficBackendType `json:"trafficBackend,omitempty"`
 }
 
 type ClusterRequirements struct {
 	// it is an error to not specify any regions
 	Regions      []RegionRequirement `json:"regions"`
 	Capabilities []string            `json:"capabilities,omitempty"`
+
+	// Affinity selects the clusters a release can be scheduled on, or
+	// should preferably be scheduled on, by their labels.
+	Affinity *ClusterAffinity `json:"affinity,omitempty"`
+
+	// AntiAffinity selects the clusters a release can't be scheduled on,
+	// or should preferably not be scheduled on, by their labels.
+	AntiAffinity *ClusterAffinity `json:"antiAffinity,omitempty"`
+}
+
+type ClusterAffinity struct {
+	// Required selectors must all match a cluster for it to be chosen.
+	// For anti-affinity, a cluster matching any of them is never chosen.
+	Required []metav1.LabelSelector `json:"required,omitempty"`
+
+	// Preferred selectors move the clusters they match towards the front
+	// of the preference list or, for anti-affinity, towards its end.
+	Preferred []PreferredClusterSelector `json:"preferred,omitempty"`
+}
+
+type PreferredClusterSelector struct {
+	// Weight is how much matching Selector counts, between 1 and 100.
+	Weight   int32                `json:"weight"`
+	Selector metav1.LabelSelector `json:"selector"`
 }
 
 type RegionRequirement struct {
 	Name     string `json:"name"`
 	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Zones is the minimum number of distinct zones the clusters chosen
+	// in this region must be spread over. It can't be higher than
+	// Replicas.
+	Zones *int32 `json:"zones,omitempty"`
+
+	// ReplicaDistribution is how the replicas of a release are split
+	// between the clusters chosen in this region. Every cluster runs the
+	// number of replicas in the chart if unset.
+	ReplicaDistribution *ReplicaDistribution `json:"replicaDistribution,omitempty"`
+}
+
+type ReplicaDistributionMode string
+
+const (
+	// ReplicaDistributionModeEven gives every cluster the number of
+	// replicas in the chart.
+	ReplicaDistributionModeEven ReplicaDistributionMode = "even"
+
+	// ReplicaDistributionModeWeight splits the replicas of the region in
+	// proportion to the scheduler weight of its clusters.
+	ReplicaDistributionModeWeight ReplicaDistributionMode = "weight"
+
+	// ReplicaDistributionModeRatio splits the replicas of the region in
+	// proportion to the ratios given for its clusters.
+	ReplicaDistributionModeRatio ReplicaDistributionMode = "ratio"
+)
+
+type ReplicaDistribution struct {
+	Mode ReplicaDistributionMode `json:"mode"`
+
+	// Ratios maps cluster names to their share of the replicas of the
+	// region, in ratio mode. Clusters that are not listed have a ratio of
+	// 1.
+	Ratios map[string]int32 `json:"ratios,omitempty"`
 }
 
 type RolloutStrategy struct {
-	Steps []RolloutStrategyStep `json:"steps"`
+	Steps []RolloutStrategyStep `json:"steps,omitempty"`
+
+	// BlueGreen rolls releases out in blue/green mode instead of going
+	// through Steps, which must then be empty.
+	BlueGreen *BlueGreenStrategy `json:"blueGreen,omitempty"`
+
+	// ProgressDeadlineSeconds is how long a release can take to achieve a
+	// strategy step before it is considered stuck. No deadline is enforced
+	// if unset.
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// RollbackOnProgressDeadline aborts a release that exceeded its
+	// progress deadline, rolling the application back to the incumbent.
+	RollbackOnProgressDeadline bool `json:"rollbackOnProgressDeadline,omitempty"`
 }
 
 type RolloutStrategyStep struct {
 	Name     string                   `json:"name"`
 	Capacity RolloutStrategyStepValue `json:"capacity"`
 	Traffic  RolloutStrategyStepValue `json:"traffic"`
+
+	// Pause is how long the release controller holds a step once it has
+	// been achieved before moving on to the next one on its own. It is
+	// either a duration such as "15m", or "manual" (the default), in which
+	// case the rollout waits for spec.targetStep to be bumped.
+	Pause string `json:"pause,omitempty"`
+
+	// Analysis lists the checks that have to pass before the step is
+	// considered achieved. A failing check aborts the rollout.
+	Analysis []RolloutStepAnalysis `json:"analysis,omitempty"`
+
+	// Clusters restricts the step to a subset of the clusters the release
+	// is scheduled on. Clusters left out keep the values of the latest
+	// earlier step that applied to them, so a strategy can roll out in
+	// waves. An empty value applies the step to all clusters.
+	Clusters *RolloutStrategyStepClusters `json:"clusters,omitempty"`
+
+	// Approvals is the number of distinct users that have to approve the
+	// step before a release can target it.
+	Approvals int32 `json:"approvals,omitempty"`
+}
+
+// RolloutStrategyStepClusters selects the clusters a strategy step applies
+// to, either by name or by cluster labels. A cluster matching either of them
+// is selected.
+type RolloutStrategyStepClusters struct {
+	Names    []string              `json:"names,omitempty"`
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+const RolloutStrategyStepPauseManual = "manual"
+
+// BlueGreenStrategy brings the contender to full capacity without any
+// traffic, then switches traffic from the incumbent to the contender in all
+// clusters at once. The incumbent keeps its full capacity for the retention
+// period, during which traffic can be switched back to it instantly by
+// setting spec.targetStep to 0, before being scaled down.
+type BlueGreenStrategy struct {
+	// IncumbentRetention is how long the incumbent is kept at full
+	// capacity after traffic has been switched to the contender. It is
+	// either a duration such as "30m", or "manual" (the default), in which
+	// case the incumbent is kept until spec.targetStep is bumped to the
+	// last step.
+	IncumbentRetention string `json:"incumbentRetention,omitempty"`
+}
+
+type AnalysisOperator string
+
+const (
+	AnalysisOperatorLessThan           AnalysisOperator = "<"
+	AnalysisOperatorLessThanOrEqual    AnalysisOperator = "<="
+	AnalysisOperatorGreaterThan        AnalysisOperator = ">"
+	AnalysisOperatorGreaterThanOrEqual AnalysisOperator = ">="
+)
+
+// RolloutStepAnalysis is a check run against a metrics provider. Every
+// interval, the query is evaluated and its result compared to the
+// threshold: the check passes after count successful measurements, and
+// fails once more than failureLimit measurements did not succeed.
+type RolloutStepAnalysis struct {
+	Name string `json:"name"`
+	// Query is expected to evaluate to a single value.
+	Query     string           `json:"query"`
+	Operator  AnalysisOperator `json:"operator"`
+	Threshold float64          `json:"threshold"`
+	// Interval between two measurements, "1m" by default.
+	Interval     string `json:"interval,omitempty"`
+	Count        int32  `json:"count,omitempty"`
+	FailureLimit int32  `json:"failureLimit,omitempty"`
 }
 
 type RolloutStrategyStepValue struct {
 	Incumbent int32 `json:"incumbent"`
 	Contender int32 `json:"contender"`
+
+	// ContenderReplicas sets the capacity of the contender as an absolute
+	// number of replicas instead of a percentage, in which case Contender
+	// is ignored. It is only valid for capacity.
+	ContenderReplicas *StepReplicaCount `json:"contenderReplicas,omitempty"`
+}
+
+// StepReplicaCount is an absolute number of replicas, either in every
+// cluster or in total. Exactly one of them must be set.
+type StepReplicaCount struct {
+	// PerCluster is the number of replicas in every cluster.
+	PerCluster *int32 `json:"perCluster,omitempty"`
+
+	// Total is the number of replicas across all clusters, spread as
+	// evenly as possible between them.
+	Total *int32 `json:"total,omitempty"`
 }
 
 type TargetConditionType string
@@ -381,9 +632,37 @@ type ClusterCapacityReportOwner struct {
 	Name string `json:"name"`
 }
 
+// ClusterCapacityReportPodEvent is a Warning event about one of the pods
+// reported as sad for a cluster. It leaves out how many times the event
+// happened and when it last did, so that an event happening again doesn't
+// change the report.
+type ClusterCapacityReportPodEvent struct {
+	Pod            string      `json:"pod"`
+	Reason         string      `json:"reason"`
+	Message        string      `json:"message"`
+	FirstTimestamp metav1.Time `json:"firstTimestamp"`
+}
+
+// ClusterCapacityReportContainerLog is the end of the logs of the previous
+// run of a container that keeps crashing in one of the pods reported as sad
+// for a cluster.
+type ClusterCapacityReportContainerLog struct {
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Tail      string `json:"tail"`
+}
+
 type ClusterCapacityReport struct {
 	Owner     ClusterCapacityReportOwner       `json:"owner"`
 	Breakdown []ClusterCapacityReportBreakdown `json:"breakdown,omitempty"`
+
+	// Events are Warning events of sad pods, the ones that first happened
+	// the latest first.
+	Events []ClusterCapacityReportPodEvent `json:"events,omitempty"`
+
+	// Logs are excerpts of the logs of crash-looping containers of sad
+	// pods.
+	Logs []ClusterCapacityReportContainerLog `json:"logs,omitempty"`
 }
 
 type ClusterCapacityStatus struct {
@@ -400,6 +679,10 @@ type ClusterConditionType string
 const (
 	ClusterConditionTypeOperational ClusterConditionType = "Operational"
 	ClusterConditionTypeReady       ClusterConditionType = "Ready"
+
+	// Conditions of Cluster objects.
+	ClusterConditionTypeReachable       ClusterConditionType = "Reachable"
+	ClusterConditionTypeInformersSynced ClusterConditionType = "InformersSynced"
 )
 
 type ClusterCapacityCondition struct {
@@ -430,6 +713,11 @@ type ClusterCapacityTarget struct {
 	Name              string `json:"name"`
 	Percent           int32  `json:"percent"`
 	TotalReplicaCount int32  `json:"totalReplicaCount"`
+
+	// Replicas is the absolute number of replicas the cluster should
+	// run. It takes precedence over Percent when set, and can't be higher
+	// than TotalReplicaCount.
+	Replicas *int32 `json:"replicas,omitempty"`
 }
 
 // +genclient
@@ -478,8 +766,26 @@ type ClusterTrafficCondition struct {
 
 type TrafficTargetSpec struct {
 	Clusters []ClusterTrafficTarget `json:"clusters"`
+
+	// Backend is how traffic is shifted to the release. Pod labels are
+	// used if unset.
+	Backend TrafficBackendType `json:"backend,omitempty"`
 }
 
+type TrafficBackendType string
+
+const (
+	// TrafficBackendPodLabels shifts traffic by labelling just enough of
+	// the pods of each release to be selected by the application's
+	// production Service.
+	TrafficBackendPodLabels TrafficBackendType = "podLabels"
+
+	// TrafficBackendIstio shifts traffic with weighted routes in an Istio
+	// VirtualService, to subsets of a DestinationRule matching the pods
+	// of each release.
+	TrafficBackendIstio TrafficBackendType = "istio"
+)
+
 type ClusterTrafficTarget struct {
 	Name string `json:"name"`
 	// apimachinery intstr for percentages?
@@ -549,6 +855,42 @@ type RolloutBlockList struct {
 
 type RolloutBlockStatus struct {
 	Overrides RolloutBlockOverrides `json:"overrides"`
+
+	// Active is whether the block is currently in effect, as last
+	// observed by the rolloutblock controller.
+	Active bool `json:"active,omitempty"`
+
+	// NextTransition is when the block is next going to come into or go
+	// out of effect. It is unset if that is never going to happen.
+	NextTransition *metav1.Time `json:"nextTransition,omitempty"`
+
+	// ScheduleError is why the schedule of the block can't be evaluated,
+	// in which case the block stays in effect. It is unset if the
+	// schedule is valid.
+	ScheduleError string `json:"scheduleError,omitempty"`
+
+	// OverrideHistory records the latest times applications and releases
+	// started overriding the block, oldest first.
+	OverrideHistory []RolloutBlockOverrideRecord `json:"overrideHistory,omitempty"`
+}
+
+// RolloutBlockOverrideRecord is an audit record of an application or release
+// overriding a rollout block.
+type RolloutBlockOverrideRecord struct {
+	// Kind is either Application or Release.
+	Kind string `json:"kind"`
+
+	// Name is the namespace and name of the overriding object.
+	Name string `json:"name"`
+
+	// Author is the user that added the override.
+	Author string `json:"author"`
+
+	// Timestamp is when the override was added.
+	Timestamp metav1.Time `json:"timestamp"`
+
+	// Justification is why the override was added.
+	Justification string `json:"justification"`
 }
 
 type RolloutBlockOverrides struct {
@@ -559,6 +901,59 @@ type RolloutBlockOverrides struct {
 type RolloutBlockSpec struct {
 	Message string             `json:"message"`
 	Author  RolloutBlockAuthor `json:"author"`
+
+	// StartTime and EndTime bound the period in which the block is in
+	// effect. A block without them is in effect from its creation until
+	// it is deleted.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	EndTime   *metav1.Time `json:"endTime,omitempty"`
+
+	// Schedule restricts the block to recurring windows, so a change
+	// freeze can be declared once.
+	Schedule *RolloutBlockSchedule `json:"schedule,omitempty"`
+
+	// Scope restricts the block to a subset of the applications and
+	// releases it would otherwise apply to. A block without a scope
+	// applies to everything in its namespace, or to everything if it is a
+	// global block.
+	Scope *RolloutBlockScope `json:"scope,omitempty"`
+}
+
+// RolloutBlockScope selects the applications and releases a rollout block
+// applies to. All of the criteria that are set must match for the block to
+// apply, and lists match if any of their items does.
+type RolloutBlockScope struct {
+	// Selector matches the labels of applications and releases.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Clusters matches releases scheduled on any of these clusters.
+	// Applications, and releases that are yet to be scheduled, are never
+	// considered to be on a cluster.
+	Clusters []string `json:"clusters,omitempty"`
+
+	// Regions matches applications and releases requiring any of these
+	// regions.
+	Regions []string `json:"regions,omitempty"`
+
+	// Charts matches applications and releases using a chart by any of
+	// these names.
+	Charts []string `json:"charts,omitempty"`
+}
+
+// RolloutBlockSchedule describes recurring windows in which a rollout block
+// is in effect. For instance, a block from every Friday 16:00 until Monday
+// 08:00 would start at "0 16 * * FRI" and last "64h".
+type RolloutBlockSchedule struct {
+	// Start is a cron expression, in the usual minute, hour, day of
+	// month, month and day of week format, of when every window starts.
+	Start string `json:"start"`
+
+	// Duration is how long every window lasts, such as "64h".
+	Duration string `json:"duration"`
+
+	// TimeZone is the name of the time zone Start is expressed in, such as
+	// "Europe/Amsterdam". Defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
 }
 
 type RolloutBlockAuthor struct {
@@ -5,13 +5,15 @@
 package v1alpha1
 
 import (
-	v1 "k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AchievedStep) DeepCopyInto(out *AchievedStep) {
 	*out = *in
+	in.AchievedTime.DeepCopyInto(&out.AchievedTime)
 	return
 }
 
@@ -25,6 +27,23 @@ func (in *AchievedStep) DeepCopy() *AchievedStep {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnalysisStatus) DeepCopyInto(out *AnalysisStatus) {
+	*out = *in
+	in.LastMeasurementTime.DeepCopyInto(&out.LastMeasurementTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnalysisStatus.
+func (in *AnalysisStatus) DeepCopy() *AnalysisStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AnalysisStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Application) DeepCopyInto(out *Application) {
 	*out = *in
@@ -153,6 +172,22 @@ func (in *ApplicationStatus) DeepCopy() *ApplicationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueGreenStrategy) DeepCopyInto(out *BlueGreenStrategy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueGreenStrategy.
+func (in *BlueGreenStrategy) DeepCopy() *BlueGreenStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueGreenStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CapacityTarget) DeepCopyInto(out *CapacityTarget) {
 	*out = *in
@@ -220,7 +255,9 @@ func (in *CapacityTargetSpec) DeepCopyInto(out *CapacityTargetSpec) {
 	if in.Clusters != nil {
 		in, out := &in.Clusters, &out.Clusters
 		*out = make([]ClusterCapacityTarget, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -297,7 +334,7 @@ func (in *Cluster) DeepCopyInto(out *Cluster) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -319,6 +356,66 @@ func (in *Cluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAffinity) DeepCopyInto(out *ClusterAffinity) {
+	*out = *in
+	if in.Required != nil {
+		in, out := &in.Required, &out.Required
+		*out = make([]v1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Preferred != nil {
+		in, out := &in.Preferred, &out.Preferred
+		*out = make([]PreferredClusterSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAffinity.
+func (in *ClusterAffinity) DeepCopy() *ClusterAffinity {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAffinity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCapacity) DeepCopyInto(out *ClusterCapacity) {
+	*out = *in
+	if in.Allocatable != nil {
+		in, out := &in.Allocatable, &out.Allocatable
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Requested != nil {
+		in, out := &in.Requested, &out.Requested
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCapacity.
+func (in *ClusterCapacity) DeepCopy() *ClusterCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterCapacityCondition) DeepCopyInto(out *ClusterCapacityCondition) {
 	*out = *in
@@ -347,6 +444,18 @@ func (in *ClusterCapacityReport) DeepCopyInto(out *ClusterCapacityReport) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]ClusterCapacityReportPodEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Logs != nil {
+		in, out := &in.Logs, &out.Logs
+		*out = make([]ClusterCapacityReportContainerLog, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -427,6 +536,22 @@ func (in *ClusterCapacityReportContainerBreakdownExample) DeepCopy() *ClusterCap
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCapacityReportContainerLog) DeepCopyInto(out *ClusterCapacityReportContainerLog) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCapacityReportContainerLog.
+func (in *ClusterCapacityReportContainerLog) DeepCopy() *ClusterCapacityReportContainerLog {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCapacityReportContainerLog)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterCapacityReportContainerStateBreakdown) DeepCopyInto(out *ClusterCapacityReportContainerStateBreakdown) {
 	*out = *in
@@ -460,6 +585,23 @@ func (in *ClusterCapacityReportOwner) DeepCopy() *ClusterCapacityReportOwner {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCapacityReportPodEvent) DeepCopyInto(out *ClusterCapacityReportPodEvent) {
+	*out = *in
+	in.FirstTimestamp.DeepCopyInto(&out.FirstTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCapacityReportPodEvent.
+func (in *ClusterCapacityReportPodEvent) DeepCopy() *ClusterCapacityReportPodEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCapacityReportPodEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterCapacityStatus) DeepCopyInto(out *ClusterCapacityStatus) {
 	*out = *in
@@ -500,6 +642,11 @@ func (in *ClusterCapacityStatus) DeepCopy() *ClusterCapacityStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterCapacityTarget) DeepCopyInto(out *ClusterCapacityTarget) {
 	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -513,6 +660,23 @@ func (in *ClusterCapacityTarget) DeepCopy() *ClusterCapacityTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCondition) DeepCopyInto(out *ClusterCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCondition.
+func (in *ClusterCondition) DeepCopy() *ClusterCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterInstallationCondition) DeepCopyInto(out *ClusterInstallationCondition) {
 	*out = *in
@@ -601,6 +765,16 @@ func (in *ClusterRequirements) DeepCopyInto(out *ClusterRequirements) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(ClusterAffinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AntiAffinity != nil {
+		in, out := &in.AntiAffinity, &out.AntiAffinity
+		*out = new(ClusterAffinity)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -665,6 +839,18 @@ func (in *ClusterSpec) DeepCopy() *ClusterSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = new(ClusterCapacity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ClusterCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -865,14 +1051,14 @@ func (in *PodStatus) DeepCopyInto(out *PodStatus) {
 	*out = *in
 	if in.Containers != nil {
 		in, out := &in.Containers, &out.Containers
-		*out = make([]v1.ContainerStatus, len(*in))
+		*out = make([]corev1.ContainerStatus, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.InitContainers != nil {
 		in, out := &in.InitContainers, &out.InitContainers
-		*out = make([]v1.ContainerStatus, len(*in))
+		*out = make([]corev1.ContainerStatus, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -891,6 +1077,23 @@ func (in *PodStatus) DeepCopy() *PodStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreferredClusterSelector) DeepCopyInto(out *PreferredClusterSelector) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreferredClusterSelector.
+func (in *PreferredClusterSelector) DeepCopy() *PreferredClusterSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(PreferredClusterSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegionRequirement) DeepCopyInto(out *RegionRequirement) {
 	*out = *in
@@ -899,6 +1102,16 @@ func (in *RegionRequirement) DeepCopyInto(out *RegionRequirement) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReplicaDistribution != nil {
+		in, out := &in.ReplicaDistribution, &out.ReplicaDistribution
+		*out = new(ReplicaDistribution)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1041,7 +1254,7 @@ func (in *ReleaseStatus) DeepCopyInto(out *ReleaseStatus) {
 	if in.AchievedStep != nil {
 		in, out := &in.AchievedStep, &out.AchievedStep
 		*out = new(AchievedStep)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Strategy != nil {
 		in, out := &in.Strategy, &out.Strategy
@@ -1055,6 +1268,20 @@ func (in *ReleaseStatus) DeepCopyInto(out *ReleaseStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Analysis != nil {
+		in, out := &in.Analysis, &out.Analysis
+		*out = make([]AnalysisStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Approvals != nil {
+		in, out := &in.Approvals, &out.Approvals
+		*out = make([]StepApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1125,13 +1352,36 @@ func (in *ReleaseStrategyStatus) DeepCopy() *ReleaseStrategyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaDistribution) DeepCopyInto(out *ReplicaDistribution) {
+	*out = *in
+	if in.Ratios != nil {
+		in, out := &in.Ratios, &out.Ratios
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaDistribution.
+func (in *ReplicaDistribution) DeepCopy() *ReplicaDistribution {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaDistribution)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RolloutBlock) DeepCopyInto(out *RolloutBlock) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -1202,6 +1452,23 @@ func (in *RolloutBlockList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutBlockOverrideRecord) DeepCopyInto(out *RolloutBlockOverrideRecord) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlockOverrideRecord.
+func (in *RolloutBlockOverrideRecord) DeepCopy() *RolloutBlockOverrideRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutBlockOverrideRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RolloutBlockOverrides) DeepCopyInto(out *RolloutBlockOverrides) {
 	*out = *in
@@ -1218,10 +1485,80 @@ func (in *RolloutBlockOverrides) DeepCopy() *RolloutBlockOverrides {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutBlockSchedule) DeepCopyInto(out *RolloutBlockSchedule) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlockSchedule.
+func (in *RolloutBlockSchedule) DeepCopy() *RolloutBlockSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutBlockSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutBlockScope) DeepCopyInto(out *RolloutBlockScope) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Regions != nil {
+		in, out := &in.Regions, &out.Regions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Charts != nil {
+		in, out := &in.Charts, &out.Charts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlockScope.
+func (in *RolloutBlockScope) DeepCopy() *RolloutBlockScope {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutBlockScope)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RolloutBlockSpec) DeepCopyInto(out *RolloutBlockSpec) {
 	*out = *in
 	out.Author = in.Author
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EndTime != nil {
+		in, out := &in.EndTime, &out.EndTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(RolloutBlockSchedule)
+		**out = **in
+	}
+	if in.Scope != nil {
+		in, out := &in.Scope, &out.Scope
+		*out = new(RolloutBlockScope)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1239,6 +1576,17 @@ func (in *RolloutBlockSpec) DeepCopy() *RolloutBlockSpec {
 func (in *RolloutBlockStatus) DeepCopyInto(out *RolloutBlockStatus) {
 	*out = *in
 	out.Overrides = in.Overrides
+	if in.NextTransition != nil {
+		in, out := &in.NextTransition, &out.NextTransition
+		*out = (*in).DeepCopy()
+	}
+	if in.OverrideHistory != nil {
+		in, out := &in.OverrideHistory, &out.OverrideHistory
+		*out = make([]RolloutBlockOverrideRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1252,13 +1600,41 @@ func (in *RolloutBlockStatus) DeepCopy() *RolloutBlockStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStepAnalysis) DeepCopyInto(out *RolloutStepAnalysis) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStepAnalysis.
+func (in *RolloutStepAnalysis) DeepCopy() *RolloutStepAnalysis {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStepAnalysis)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
 	*out = *in
 	if in.Steps != nil {
 		in, out := &in.Steps, &out.Steps
 		*out = make([]RolloutStrategyStep, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BlueGreen != nil {
+		in, out := &in.BlueGreen, &out.BlueGreen
+		*out = new(BlueGreenStrategy)
+		**out = **in
+	}
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int32)
+		**out = **in
 	}
 	return
 }
@@ -1276,8 +1652,18 @@ func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RolloutStrategyStep) DeepCopyInto(out *RolloutStrategyStep) {
 	*out = *in
-	out.Capacity = in.Capacity
-	out.Traffic = in.Traffic
+	in.Capacity.DeepCopyInto(&out.Capacity)
+	in.Traffic.DeepCopyInto(&out.Traffic)
+	if in.Analysis != nil {
+		in, out := &in.Analysis, &out.Analysis
+		*out = make([]RolloutStepAnalysis, len(*in))
+		copy(*out, *in)
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = new(RolloutStrategyStepClusters)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1291,9 +1677,40 @@ func (in *RolloutStrategyStep) DeepCopy() *RolloutStrategyStep {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStrategyStepClusters) DeepCopyInto(out *RolloutStrategyStepClusters) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategyStepClusters.
+func (in *RolloutStrategyStepClusters) DeepCopy() *RolloutStrategyStepClusters {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStrategyStepClusters)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RolloutStrategyStepValue) DeepCopyInto(out *RolloutStrategyStepValue) {
 	*out = *in
+	if in.ContenderReplicas != nil {
+		in, out := &in.ContenderReplicas, &out.ContenderReplicas
+		*out = new(StepReplicaCount)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1307,6 +1724,49 @@ func (in *RolloutStrategyStepValue) DeepCopy() *RolloutStrategyStepValue {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepApproval) DeepCopyInto(out *StepApproval) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepApproval.
+func (in *StepApproval) DeepCopy() *StepApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(StepApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepReplicaCount) DeepCopyInto(out *StepReplicaCount) {
+	*out = *in
+	if in.PerCluster != nil {
+		in, out := &in.PerCluster, &out.PerCluster
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Total != nil {
+		in, out := &in.Total, &out.Total
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepReplicaCount.
+func (in *StepReplicaCount) DeepCopy() *StepReplicaCount {
+	if in == nil {
+		return nil
+	}
+	out := new(StepReplicaCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetCondition) DeepCopyInto(out *TargetCondition) {
 	*out = *in
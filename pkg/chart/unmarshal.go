@@ -33,3 +33,31 @@ func GetDeployments(rawRendered []string) []appsv1.Deployment {
 
 	return deployments
 }
+
+func GetStatefulSets(rawRendered []string) []appsv1.StatefulSet {
+	var statefulSets []appsv1.StatefulSet
+
+	decoder := scheme.Codecs.UniversalDeserializer()
+
+	for _, raw := range rawRendered {
+		klog.V(10).Infof("attempting to decode %q", raw)
+
+		var s appsv1.StatefulSet
+		obj, _, err := decoder.Decode([]byte(raw), nil, &s)
+		if err != nil {
+			klog.Warningf("failed to unmarshal a statefulset: %s", err)
+			continue
+		}
+
+		const expectedKind = "StatefulSet"
+		gotKind := obj.GetObjectKind().GroupVersionKind().Kind
+		if gotKind != expectedKind {
+			klog.V(10).Infof("got a %q, skipping", gotKind)
+			continue
+		}
+
+		statefulSets = append(statefulSets, s)
+	}
+
+	return statefulSets
+}
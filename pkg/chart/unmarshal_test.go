@@ -19,6 +19,22 @@ spec:
           image: "nginx:stable"
 `
 
+const statefulSetText = `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: my-stateful-app
+  namespace: default
+spec:
+  replicas: 3
+  serviceName: my-stateful-app
+  template:
+    spec:
+      containers:
+        - name: my-stateful-app
+          image: "redis:stable"
+`
+
 const somethingElseText = `
 apiVersion: v1
 kind: Service
@@ -42,7 +58,7 @@ kind: What
 `
 
 func TestGetDeploymentsValid(t *testing.T) {
-	deployments := GetDeployments([]string{deploymentText, somethingElseText, garbage})
+	deployments := GetDeployments([]string{deploymentText, statefulSetText, somethingElseText, garbage})
 	if len(deployments) != 1 {
 		t.Fatalf("expected exactly one Deployment but got %d", len(deployments))
 	}
@@ -65,3 +81,24 @@ func TestGetDeploymentsValid(t *testing.T) {
 		t.Errorf("expected %d replicas but got %d", expectedReplicas, *d.Spec.Replicas)
 	}
 }
+
+func TestGetStatefulSetsValid(t *testing.T) {
+	statefulSets := GetStatefulSets([]string{deploymentText, statefulSetText, somethingElseText, garbage})
+	if len(statefulSets) != 1 {
+		t.Fatalf("expected exactly one StatefulSet but got %d", len(statefulSets))
+	}
+
+	s := statefulSets[0]
+
+	const (
+		expectedName     = "my-stateful-app"
+		expectedReplicas = 3
+	)
+
+	if s.GetName() != expectedName {
+		t.Errorf("expected name %q but got %q", expectedName, s.GetName())
+	}
+	if *s.Spec.Replicas != expectedReplicas {
+		t.Errorf("expected %d replicas but got %d", expectedReplicas, *s.Spec.Replicas)
+	}
+}
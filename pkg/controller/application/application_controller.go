@@ -7,6 +7,7 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
@@ -54,6 +55,11 @@ type Controller struct {
 
 	versionResolver shipperrepo.ChartVersionResolver
 
+	// rebalance is whether applications that opted into it are rolled
+	// out again when their contender would be scheduled on other clusters
+	// if it was created now.
+	rebalance bool
+
 	recorder record.EventRecorder
 }
 
@@ -62,6 +68,7 @@ func NewController(
 	shipperClientset clientset.Interface,
 	shipperInformerFactory informers.SharedInformerFactory,
 	versionResolver shipperrepo.ChartVersionResolver,
+	rebalance bool,
 	recorder record.EventRecorder,
 ) *Controller {
 	appInformer := shipperInformerFactory.Shipper().V1alpha1().Applications()
@@ -82,6 +89,7 @@ func NewController(
 		rbSynced: rbInformer.Informer().HasSynced,
 
 		versionResolver: versionResolver,
+		rebalance:       rebalance,
 		recorder:        recorder,
 	}
 
@@ -102,6 +110,17 @@ func NewController(
 	})
 
 	rbInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) {
+			// Blocks come into and go out of effect on their
+			// schedule, as reflected in their status, and may
+			// be rescoped to apply to other objects.
+			oldRB, oldOk := old.(*shipper.RolloutBlock)
+			newRB, newOk := new.(*shipper.RolloutBlock)
+			if oldOk && newOk && (oldRB.Status.Active != newRB.Status.Active ||
+				!equality.Semantic.DeepEqual(oldRB.Spec.Scope, newRB.Spec.Scope)) {
+				c.enqueueAppFromRolloutBlock(new)
+			}
+		},
 		DeleteFunc: c.enqueueAppFromRolloutBlock,
 	})
 
@@ -440,6 +459,17 @@ func (c *Controller) processApplication(app *shipper.Application) error {
 	)
 	diff.Append(apputil.SetApplicationCondition(&app.Status, *condition))
 
+	// A contender aborted by the release controller is deleted, just like
+	// a user would abort a rollout by hand. Its predecessor then becomes the
+	// contender again, and the application is returned to its environment
+	// further down.
+	if len(appReleases) > 1 && releaseutil.ReleaseAborted(appReleases[0]) {
+		if err := c.deleteAbortedRelease(app, appReleases[0]); err != nil {
+			return err
+		}
+		appReleases = appReleases[1:]
+	}
+
 	if contender, err = apputil.GetContender(app.Name, appReleases); err != nil {
 		// Anything else rather than not found err is an abort case
 		if !shippererrors.IsContenderNotFoundError(err) {
@@ -531,10 +561,15 @@ func (c *Controller) processApplication(app *shipper.Application) error {
 		highestObserved = generation
 	}
 
-	if !identicalEnvironments(app.Spec.Template, contender.Spec.Environment) {
+	templateChanged := !identicalEnvironments(app.Spec.Template, contender.Spec.Environment)
+	rebalance := !templateChanged && c.rebalance &&
+		apputil.RebalancingEnabled(app) && releaseNeedsRebalancing(contender)
+	if templateChanged || rebalance {
 		// The application's template has been modified and is different than
 		// the contender's environment. This means that a new release should
-		// be created with the new template.
+		// be created with the new template. The same goes for contenders
+		// that should be moved to other clusters: the new release gets
+		// scheduled anew, and rolled out with the application's strategy.
 		highestObserved = highestObserved + 1
 		if releaseName, iteration, err := c.releaseNameForApplication(app); err != nil {
 			return err
@@ -555,6 +590,19 @@ func (c *Controller) processApplication(app *shipper.Application) error {
 			return err
 		} else {
 			appReleases = append(appReleases, rel)
+
+			if rebalance {
+				c.recorder.Eventf(
+					app,
+					corev1.EventTypeNormal,
+					"ReleaseRebalanced",
+					"Created release %q to move %q from clusters %q to %q",
+					rel.Name,
+					contender.Name,
+					contender.Annotations[shipper.ReleaseClustersAnnotation],
+					contender.Annotations[shipper.ReleaseRebalancedClustersAnnotation],
+				)
+			}
 		}
 	}
 
@@ -568,6 +616,32 @@ func (c *Controller) processApplication(app *shipper.Application) error {
 	return c.wrapUpApplicationConditions(app, appReleases)
 }
 
+func (c *Controller) deleteAbortedRelease(app *shipper.Application, rel *shipper.Release) error {
+	namespace := rel.GetNamespace()
+	err := c.shipperClientset.ShipperV1alpha1().Releases(namespace).Delete(rel.GetName(), &metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return shippererrors.
+			NewKubeclientDeleteError(namespace, rel.GetName(), err).
+			WithShipperKind("Release")
+	}
+
+	var msg string
+	if cond := releaseutil.GetReleaseCondition(rel.Status, shipper.ReleaseConditionTypeAborted); cond != nil {
+		msg = cond.Message
+	}
+
+	c.recorder.Eventf(
+		app,
+		corev1.EventTypeWarning,
+		"ReleaseAborted",
+		"Release %q was aborted and has been deleted: %s",
+		rel.GetName(),
+		msg,
+	)
+
+	return nil
+}
+
 func (c *Controller) cleanUpReleasesForApplication(app *shipper.Application, releases []*shipper.Release) error {
 	var completedReleases []*shipper.Release
 
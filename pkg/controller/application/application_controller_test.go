@@ -706,6 +706,160 @@ func TestCreateSecondRelease(t *testing.T) {
 	f.run()
 }
 
+// TestCreateReleaseToRebalance checks that a new release with the same
+// environment is created when the contender has been marked as needing to
+// move to other clusters.
+func TestCreateReleaseToRebalance(t *testing.T) {
+	f := newFixture(t)
+	f.rebalance = true
+
+	app := newApplication(testAppName)
+	app.Annotations[shipper.AppRebalanceAnnotation] = shipper.True
+	apputil.SetHighestObservedGeneration(app, 0)
+	apputil.UpdateChartNameAnnotation(app, "simple")
+	apputil.UpdateChartVersionRawAnnotation(app, "0.0.1")
+	apputil.UpdateChartVersionResolvedAnnotation(app, "0.0.1")
+	f.objects = append(f.objects, app)
+
+	envHash := hashReleaseEnvironment(app.Spec.Template)
+	incumbentRelName := fmt.Sprintf("%s-%s-0", testAppName, envHash)
+
+	incumbentRel := newRelease(incumbentRelName, app)
+	incumbentRel.Labels[shipper.ReleaseEnvironmentHashLabel] = envHash
+	incumbentRel.Annotations[shipper.ReleaseClustersAnnotation] = "cluster-a"
+	incumbentRel.Annotations[shipper.ReleaseRebalancedClustersAnnotation] = "cluster-b"
+	releaseutil.SetGeneration(incumbentRel, 0)
+	releaseutil.SetIteration(incumbentRel, 0)
+	releaseutil.SetReleaseCondition(&incumbentRel.Status, *releaseutil.NewReleaseCondition(shipper.ReleaseConditionTypeComplete, corev1.ConditionTrue, "", ""))
+	incumbentRel.Spec.TargetStep = 2
+	incumbentRel.Status.AchievedStep = &shipper.AchievedStep{
+		Step: 2,
+		Name: incumbentRel.Spec.Environment.Strategy.Steps[2].Name,
+	}
+
+	f.objects = append(f.objects, incumbentRel)
+
+	app.Status.History = []string{incumbentRelName}
+
+	contenderRelName := fmt.Sprintf("%s-%s-1", testAppName, envHash)
+
+	contenderRel := newRelease(contenderRelName, app)
+	contenderRel.Annotations[shipper.RolloutBlocksOverrideAnnotation] = ""
+	contenderRel.Labels[shipper.ReleaseEnvironmentHashLabel] = envHash
+	releaseutil.SetIteration(contenderRel, 1)
+	releaseutil.SetGeneration(contenderRel, 1)
+
+	expectedApp := app.DeepCopy()
+	apputil.SetHighestObservedGeneration(expectedApp, 1)
+	expectedApp.Status.History = []string{
+		incumbentRelName,
+		contenderRelName,
+	}
+
+	expectedApp.Status.Conditions = []shipper.ApplicationCondition{
+		{
+			Type:   shipper.ApplicationConditionTypeAborting,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeBlocked,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeReleaseSynced,
+			Status: corev1.ConditionTrue,
+		},
+		{
+			Type:    shipper.ApplicationConditionTypeRollingOut,
+			Status:  corev1.ConditionTrue,
+			Message: fmt.Sprintf(TransitioningMessageFormat, incumbentRelName, contenderRelName),
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeValidHistory,
+			Status: corev1.ConditionTrue,
+		},
+	}
+
+	f.expectReleaseCreate(contenderRel)
+	f.expectApplicationUpdate(expectedApp)
+
+	f.expectedEvents = []string{
+		fmt.Sprintf(`Normal ReleaseRebalanced Created release "%s" to move "%s" from clusters "cluster-a" to "cluster-b"`, contenderRelName, incumbentRelName),
+		fmt.Sprintf(`Normal ApplicationConditionChanged [] -> [Aborting False], [] -> [ValidHistory True], [] -> [ReleaseSynced True], [] -> [RollingOut True Transitioning from "%s" to "%s"]`, incumbentRelName, contenderRelName),
+		"Normal ApplicationConditionChanged [] -> [Blocked False]",
+	}
+
+	f.run()
+}
+
+// TestNoReleaseToRebalanceWithoutOptIn checks that contenders marked as
+// needing to move to other clusters are left alone unless their application
+// opted into rebalancing.
+func TestNoReleaseToRebalanceWithoutOptIn(t *testing.T) {
+	f := newFixture(t)
+	f.rebalance = true
+
+	app := newApplication(testAppName)
+	apputil.SetHighestObservedGeneration(app, 0)
+	apputil.UpdateChartNameAnnotation(app, "simple")
+	apputil.UpdateChartVersionRawAnnotation(app, "0.0.1")
+	apputil.UpdateChartVersionResolvedAnnotation(app, "0.0.1")
+	f.objects = append(f.objects, app)
+
+	envHash := hashReleaseEnvironment(app.Spec.Template)
+	relName := fmt.Sprintf("%s-%s-0", testAppName, envHash)
+
+	rel := newRelease(relName, app)
+	rel.Labels[shipper.ReleaseEnvironmentHashLabel] = envHash
+	rel.Annotations[shipper.ReleaseClustersAnnotation] = "cluster-a"
+	rel.Annotations[shipper.ReleaseRebalancedClustersAnnotation] = "cluster-b"
+	releaseutil.SetGeneration(rel, 0)
+	releaseutil.SetIteration(rel, 0)
+	releaseutil.SetReleaseCondition(&rel.Status, *releaseutil.NewReleaseCondition(shipper.ReleaseConditionTypeComplete, corev1.ConditionTrue, "", ""))
+	rel.Spec.TargetStep = 2
+	rel.Status.AchievedStep = &shipper.AchievedStep{
+		Step: 2,
+		Name: rel.Spec.Environment.Strategy.Steps[2].Name,
+	}
+
+	f.objects = append(f.objects, rel)
+
+	expectedApp := app.DeepCopy()
+	expectedApp.Status.History = []string{relName}
+	expectedApp.Status.Conditions = []shipper.ApplicationCondition{
+		{
+			Type:   shipper.ApplicationConditionTypeAborting,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeBlocked,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeReleaseSynced,
+			Status: corev1.ConditionTrue,
+		},
+		{
+			Type:    shipper.ApplicationConditionTypeRollingOut,
+			Status:  corev1.ConditionFalse,
+			Message: fmt.Sprintf(ReleaseActiveMessageFormat, relName),
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeValidHistory,
+			Status: corev1.ConditionTrue,
+		},
+	}
+
+	f.expectApplicationUpdate(expectedApp)
+
+	f.expectedEvents = []string{
+		fmt.Sprintf(`Normal ApplicationConditionChanged [] -> [Aborting False], [] -> [ValidHistory True], [] -> [ReleaseSynced True], [] -> [RollingOut False Release "%s" is active]`, relName),
+		"Normal ApplicationConditionChanged [] -> [Blocked False]",
+	}
+
+	f.run()
+}
+
 func TestCreateSecondReleaseWithUpdatedChartVersionResolve(t *testing.T) {
 	f := newFixture(t)
 	resolveCnt := 1
@@ -880,6 +1034,82 @@ func TestAbort(t *testing.T) {
 	f.run()
 }
 
+func TestAbortAfterFailedAnalysis(t *testing.T) {
+	f := newFixture(t)
+	app := newApplication(testAppName)
+	app.Annotations[shipper.AppHighestObservedGenerationAnnotation] = "1"
+	app.Spec.Template.ClusterRequirements = shipper.ClusterRequirements{
+		Regions: []shipper.RegionRequirement{{Name: "foo"}},
+	}
+
+	f.objects = append(f.objects, app)
+
+	incumbentName := fmt.Sprintf("%s-%s-0", testAppName, "incumbent")
+	incumbent := newRelease(incumbentName, app)
+	incumbent.Annotations[shipper.ReleaseGenerationAnnotation] = "0"
+	incumbent.Spec.TargetStep = 2
+	incumbent.Status.AchievedStep = &shipper.AchievedStep{
+		Step: 2,
+		Name: incumbent.Spec.Environment.Strategy.Steps[2].Name,
+	}
+	incumbent.Status.Conditions = []shipper.ReleaseCondition{
+		{Type: shipper.ReleaseConditionTypeComplete, Status: corev1.ConditionTrue},
+	}
+	incumbent.Spec.Environment.ClusterRequirements = shipper.ClusterRequirements{
+		Regions: []shipper.RegionRequirement{{Name: "bar"}},
+	}
+
+	contenderName := fmt.Sprintf("%s-%s-0", testAppName, hashReleaseEnvironment(app.Spec.Template))
+	contender := newRelease(contenderName, app)
+	contender.Annotations[shipper.ReleaseGenerationAnnotation] = "1"
+	msg := `analysis "errors" failed in step "staging": query "errors" returned 0.5, expected < 0.1`
+	contender.Status.Conditions = []shipper.ReleaseCondition{
+		{
+			Type:    shipper.ReleaseConditionTypeAborted,
+			Status:  corev1.ConditionTrue,
+			Reason:  conditions.AnalysisFailed,
+			Message: msg,
+		},
+	}
+
+	f.objects = append(f.objects, incumbent, contender)
+
+	app.Status.History = []string{incumbentName, contenderName}
+
+	expectedApp := app.DeepCopy()
+	expectedApp.Annotations[shipper.AppHighestObservedGenerationAnnotation] = "0"
+	apputil.UpdateChartNameAnnotation(expectedApp, "simple")
+	apputil.UpdateChartVersionRawAnnotation(expectedApp, "0.0.1")
+	apputil.UpdateChartVersionResolvedAnnotation(expectedApp, "0.0.1")
+	expectedApp.Spec.Template = incumbent.Spec.Environment
+	expectedApp.Status.History = []string{incumbentName}
+	expectedApp.Status.Conditions = []shipper.ApplicationCondition{
+		{
+			Type:    shipper.ApplicationConditionTypeAborting,
+			Status:  corev1.ConditionTrue,
+			Message: fmt.Sprintf("abort in progress, returning state to release %q", incumbentName),
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeBlocked,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeRollingOut,
+			Status: corev1.ConditionTrue,
+		},
+	}
+
+	f.expectReleaseDelete(contender)
+	f.expectApplicationUpdate(expectedApp)
+
+	f.expectedEvents = []string{
+		fmt.Sprintf(`Warning ReleaseAborted Release "%s" was aborted and has been deleted: %s`, contenderName, msg),
+		fmt.Sprintf(`Normal ApplicationConditionChanged [] -> [Blocked False], [] -> [Aborting True abort in progress, returning state to release "%s"], [] -> [RollingOut True]`, incumbentName),
+	}
+
+	f.run()
+}
+
 func TestAbortWithChartVerisonResolve(t *testing.T) {
 	f := newFixture(t)
 	app := newApplication(testAppName)
@@ -1222,6 +1452,7 @@ type fixture struct {
 	expectedEvents []string
 
 	resolveChartVersion shipperrepo.ChartVersionResolver
+	rebalance           bool
 }
 
 func newFixture(t *testing.T) *fixture {
@@ -1241,7 +1472,7 @@ func (f *fixture) newController() (*Controller, shipperinformers.SharedInformerF
 	const noResyncPeriod time.Duration = 0
 	shipperInformerFactory := shipperinformers.NewSharedInformerFactory(f.client, noResyncPeriod)
 
-	c := NewController(f.client, shipperInformerFactory, f.resolveChartVersion, f.recorder)
+	c := NewController(f.client, shipperInformerFactory, f.resolveChartVersion, f.rebalance, f.recorder)
 
 	return c, shipperInformerFactory
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/bookingcom/shipper/pkg/controller"
 	"github.com/bookingcom/shipper/pkg/errors"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
 )
 
 func (c *Controller) createReleaseForApplication(app *shipper.Application, releaseName string, iteration, generation int) (*shipper.Release, error) {
@@ -58,6 +59,18 @@ func (c *Controller) createReleaseForApplication(app *shipper.Application, relea
 	}
 	newRelease.Spec.Environment.Chart.Version = cv.Version
 
+	// Releases carry over the overrides of their application, along
+	// with their audit trail.
+	for _, annotation := range []string{
+		shipper.RolloutBlocksOverrideJustificationAnnotation,
+		shipper.RolloutBlocksOverrideAuthorAnnotation,
+		shipper.RolloutBlocksOverrideTimestampAnnotation,
+	} {
+		if value, ok := app.Annotations[annotation]; ok {
+			newRelease.Annotations[annotation] = value
+		}
+	}
+
 	klog.V(4).Infof("Release %q labels: %v", controller.MetaKey(newRelease), newRelease.Labels)
 	klog.V(4).Infof("Release %q annotations: %v", controller.MetaKey(newRelease), newRelease.Annotations)
 
@@ -128,6 +141,18 @@ func identicalEnvironments(envs ...shipper.ReleaseEnvironment) bool {
 	return true
 }
 
+// releaseNeedsRebalancing returns whether a complete release would be
+// scheduled on other clusters if it was created now.
+func releaseNeedsRebalancing(rel *shipper.Release) bool {
+	rebalanced, ok := rel.Annotations[shipper.ReleaseRebalancedClustersAnnotation]
+	if !ok || rebalanced == "" {
+		return false
+	}
+
+	return releaseutil.ReleaseComplete(rel) &&
+		rebalanced != rel.Annotations[shipper.ReleaseClustersAnnotation]
+}
+
 func hashReleaseEnvironment(env shipper.ReleaseEnvironment) string {
 	copy := env.DeepCopy()
 	b, err := json.Marshal(copy)
@@ -10,9 +10,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/types"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	kubeinformers "k8s.io/client-go/informers"
@@ -46,6 +44,7 @@ const (
 	InternalError    = "InternalError"
 	PodsNotReady     = "PodsNotReady"
 	DeploymentStuck  = "DeploymentStuck"
+	QuotaExceeded    = "QuotaExceeded"
 
 	CapacityTargetConditionChanged  = "CapacityTargetConditionChanged"
 	ClusterCapacityConditionChanged = "ClusterCapacityConditionChanged"
@@ -61,6 +60,11 @@ type Controller struct {
 	releasesListerSynced  cache.InformerSynced
 	workqueue             workqueue.RateLimitingInterface
 	recorder              record.EventRecorder
+
+	// reportPodDetails makes the controller attach events and log
+	// excerpts of sad pods to capacity reports.
+	reportPodDetails bool
+	containerLogs    *utilcache.LRUExpireCache
 }
 
 // NewController returns a new CapacityTarget controller.
@@ -69,6 +73,7 @@ func NewController(
 	shipperInformerFactory informers.SharedInformerFactory,
 	store clusterclientstore.Interface,
 	recorder record.EventRecorder,
+	reportPodDetails bool,
 ) *Controller {
 
 	capacityTargetInformer := shipperInformerFactory.Shipper().V1alpha1().CapacityTargets()
@@ -84,6 +89,8 @@ func NewController(
 		workqueue:             workqueue.NewNamedRateLimitingQueue(shipperworkqueue.NewDefaultControllerRateLimiter(), "capacity_controller_capacitytargets"),
 		recorder:              recorder,
 		clusterClientStore:    store,
+		reportPodDetails:      reportPodDetails,
+		containerLogs:         utilcache.NewLRUExpireCache(containerLogsSize),
 	}
 
 	klog.Info("Setting up event handlers")
@@ -95,6 +102,9 @@ func NewController(
 	})
 
 	store.AddSubscriptionCallback(controller.subscribeToDeployments)
+	if reportPodDetails {
+		store.AddSubscriptionCallback(controller.subscribeToPodEvents)
+	}
 	store.AddEventHandlerCallback(controller.registerDeploymentEventHandlers)
 
 	return controller
@@ -206,7 +216,7 @@ func (c *Controller) processCapacityTargetOnCluster(
 
 	appName := ct.Labels[shipper.AppLabel]
 	release := ct.Labels[shipper.ReleaseLabel]
-	deployment, pods, err := c.getClusterObjects(spec.Name, ct.Namespace, appName, release)
+	workload, pods, err := c.getClusterObjects(spec.Name, ct.Namespace, appName, release)
 	if err != nil {
 		operationalCond = capacityutil.NewClusterCapacityCondition(
 			shipper.ClusterConditionTypeOperational,
@@ -223,16 +233,91 @@ func (c *Controller) processCapacityTargetOnCluster(
 		"",
 		"")
 
-	report := buildReport(deployment.Name, pods)
+	report := buildReport(workload.GetName(), pods)
 
 	// availableReplicas and reports will be used by the defer at the top
 	// of this func
-	availableReplicas = deployment.Status.AvailableReplicas
+	availableReplicas = workload.availableReplicas
 	reports = []shipper.ClusterCapacityReport{*report}
 
-	desiredReplicas := int32(replicas.CalculateDesiredReplicaCount(uint(spec.TotalReplicaCount), float64(spec.Percent)))
-	if deployment.Spec.Replicas == nil || desiredReplicas != *deployment.Spec.Replicas {
-		_, err = c.patchDeploymentWithReplicaCount(deployment, spec.Name, desiredReplicas)
+	desiredReplicas := replicas.DesiredReplicaCount(*spec)
+
+	err = c.syncPodDisruptionBudget(ct, workload, spec.Name)
+	if err != nil {
+		readyCond = capacityutil.NewClusterCapacityCondition(
+			shipper.ClusterConditionTypeReady,
+			corev1.ConditionFalse,
+			InternalError,
+			err.Error(),
+		)
+		return err
+	}
+
+	// If the chart comes with a HorizontalPodAutoscaler for this
+	// workload, we scale its bounds instead of the workload, and let it
+	// decide how many replicas there should be.
+	hpa, err := c.getHorizontalPodAutoscaler(spec.Name, workload)
+	if err != nil {
+		operationalCond = capacityutil.NewClusterCapacityCondition(
+			shipper.ClusterConditionTypeOperational,
+			corev1.ConditionFalse,
+			InternalError,
+			err.Error())
+
+		return err
+	}
+
+	if hpa != nil && desiredReplicas > 0 {
+		var inProgress bool
+		desiredReplicas, inProgress, err = c.processHorizontalPodAutoscaler(
+			hpa, workload, spec.Name, spec.TotalReplicaCount, desiredReplicas)
+		if err != nil {
+			readyCond = capacityutil.NewClusterCapacityCondition(
+				shipper.ClusterConditionTypeReady,
+				corev1.ConditionFalse,
+				InternalError,
+				err.Error(),
+			)
+			return err
+		} else if inProgress {
+			readyCond = capacityutil.NewClusterCapacityCondition(
+				shipper.ClusterConditionTypeReady,
+				corev1.ConditionFalse,
+				InProgress,
+				"",
+			)
+			return shippererrors.NewCapacityInProgressError(ct.Name)
+		}
+	} else if workload.replicas == nil || desiredReplicas != *workload.replicas {
+		// Pods that would go over a quota never get created, so we
+		// don't ask for them, and say exactly what's missing instead.
+		var currentReplicas int32
+		if workload.replicas != nil {
+			currentReplicas = *workload.replicas
+		}
+
+		if additionalPods := desiredReplicas - currentReplicas; additionalPods > 0 {
+			msg, err := c.checkResourceQuotas(spec.Name, workload, additionalPods)
+			if err != nil {
+				readyCond = capacityutil.NewClusterCapacityCondition(
+					shipper.ClusterConditionTypeReady,
+					corev1.ConditionFalse,
+					InternalError,
+					err.Error(),
+				)
+				return err
+			} else if msg != "" {
+				readyCond = capacityutil.NewClusterCapacityCondition(
+					shipper.ClusterConditionTypeReady,
+					corev1.ConditionFalse,
+					QuotaExceeded,
+					msg,
+				)
+				return nil
+			}
+		}
+
+		err = c.patchWorkloadWithReplicaCount(workload, spec.Name, desiredReplicas)
 		if err != nil {
 			readyCond = capacityutil.NewClusterCapacityCondition(
 				shipper.ClusterConditionTypeReady,
@@ -252,10 +337,10 @@ func (c *Controller) processCapacityTargetOnCluster(
 		}
 	}
 
-	// Deployment was successfully updated, but the update hasn't been
-	// observed by the deployment controller yet, so our change is still in
-	// flight, and we can't trust the status yet.
-	if deployment.Generation > deployment.Status.ObservedGeneration {
+	// The workload was successfully updated, but the update hasn't been
+	// observed by its controller yet, so our change is still in flight,
+	// and we can't trust the status yet.
+	if workload.GetGeneration() > workload.observedGeneration {
 		readyCond = capacityutil.NewClusterCapacityCondition(
 			shipper.ClusterConditionTypeReady,
 			corev1.ConditionFalse,
@@ -268,7 +353,7 @@ func (c *Controller) processCapacityTargetOnCluster(
 
 	// If the number of available replicas matches what we want, the
 	// CapacityTarget is Ready and there's nothing left to check.
-	if replicas.AchievedDesiredReplicaPercentage(spec.TotalReplicaCount, availableReplicas, spec.Percent) {
+	if availableReplicas == desiredReplicas {
 		readyCond = capacityutil.NewClusterCapacityCondition(
 			shipper.ClusterConditionTypeReady,
 			corev1.ConditionTrue,
@@ -289,8 +374,17 @@ func (c *Controller) processCapacityTargetOnCluster(
 		sadPods = sadPods[:SadPodLimit]
 	}
 
-	replicaFailureCond := getDeploymentCondition(deployment.Status, appsv1.DeploymentReplicaFailure)
-	progressingCond := getDeploymentCondition(deployment.Status, appsv1.DeploymentProgressing)
+	if c.reportPodDetails && len(sadPods) > 0 {
+		c.addPodDetailsToReport(spec.Name, ct.Namespace, sadPods, &reports[0])
+	}
+
+	// StatefulSets don't have conditions telling whether they're stuck,
+	// so there's only something to look at for Deployments.
+	var replicaFailureCond, progressingCond *appsv1.DeploymentCondition
+	if deployment, ok := workload.kubeobj.(*appsv1.Deployment); ok {
+		replicaFailureCond = getDeploymentCondition(deployment.Status, appsv1.DeploymentReplicaFailure)
+		progressingCond = getDeploymentCondition(deployment.Status, appsv1.DeploymentProgressing)
+	}
 
 	var msg, reason string
 
@@ -440,78 +534,66 @@ func (c *Controller) registerDeploymentEventHandlers(informerFactory kubeinforme
 	handler := cache.FilteringResourceEventHandler{
 		FilterFunc: filters.BelongsToRelease,
 		Handler: cache.ResourceEventHandlerFuncs{
-			AddFunc:    c.enqueueCapacityTargetFromDeployment,
-			DeleteFunc: c.enqueueCapacityTargetFromDeployment,
+			AddFunc:    c.enqueueCapacityTargetFromObject,
+			DeleteFunc: c.enqueueCapacityTargetFromObject,
 			UpdateFunc: func(oldObj, newObj interface{}) {
-				c.enqueueCapacityTargetFromDeployment(newObj)
+				c.enqueueCapacityTargetFromObject(newObj)
 			},
 		},
 	}
 	informerFactory.Apps().V1().Deployments().Informer().AddEventHandler(handler)
-}
-
-func (c *Controller) subscribeToDeployments(informerFactory kubeinformers.SharedInformerFactory) {
-	informerFactory.Apps().V1().Deployments().Informer()
-	informerFactory.Core().V1().Pods().Informer()
-}
-
-func (c Controller) getClusterObjects(cluster, ns, appName, release string) (*appsv1.Deployment, []*corev1.Pod, error) {
-	informerFactory, err := c.clusterClientStore.GetInformerFactory(cluster)
-	if err != nil {
-		return nil, nil, err
-	}
 
-	deploymentSelector := labels.Set{
-		shipper.AppLabel:     appName,
-		shipper.ReleaseLabel: release,
-	}.AsSelector()
-	deploymentGVK := corev1.SchemeGroupVersion.WithKind("Deployment")
-	deployments, err := informerFactory.Apps().V1().Deployments().
-		Lister().Deployments(ns).List(deploymentSelector)
-	if err != nil {
-		return nil, nil, shippererrors.NewKubeclientListError(
-			deploymentGVK, ns, deploymentSelector, err)
-	}
-
-	if l := len(deployments); l != 1 {
-		return nil, nil, shippererrors.NewUnexpectedObjectCountFromSelectorError(
-			deploymentSelector, deploymentGVK, 1, l)
+	statefulSetHandler := cache.FilteringResourceEventHandler{
+		FilterFunc: filters.BelongsToRelease,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.enqueueCapacityTargetFromObject,
+			DeleteFunc: c.enqueueCapacityTargetFromObject,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				c.enqueueCapacityTargetFromObject(newObj)
+			},
+		},
 	}
+	informerFactory.Apps().V1().StatefulSets().Informer().AddEventHandler(statefulSetHandler)
 
-	deployment := deployments[0]
-
-	podSelector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
-	if err != nil {
-		return nil, nil, shippererrors.NewUnrecoverableError(fmt.Errorf("failed to transform label selector %v into a selector: %s", deployment.Spec.Selector, err))
+	hpaHandler := cache.FilteringResourceEventHandler{
+		FilterFunc: filters.BelongsToRelease,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.enqueueCapacityTargetFromObject,
+			DeleteFunc: c.enqueueCapacityTargetFromObject,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				c.enqueueCapacityTargetFromObject(newObj)
+			},
+		},
 	}
+	informerFactory.Autoscaling().V1().HorizontalPodAutoscalers().Informer().AddEventHandler(hpaHandler)
 
-	pods, err := informerFactory.Core().V1().Pods().Lister().
-		Pods(deployment.Namespace).List(podSelector)
-	if err != nil {
-		return nil, nil, shippererrors.NewKubeclientListError(
-			corev1.SchemeGroupVersion.WithKind("Pod"),
-			deployment.Namespace, podSelector, err)
+	pdbHandler := cache.FilteringResourceEventHandler{
+		FilterFunc: filters.BelongsToRelease,
+		Handler: cache.ResourceEventHandlerFuncs{
+			DeleteFunc: c.enqueueCapacityTargetFromObject,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				c.enqueueCapacityTargetFromObject(newObj)
+			},
+		},
 	}
+	informerFactory.Policy().V1beta1().PodDisruptionBudgets().Informer().AddEventHandler(pdbHandler)
 
-	return deployment, pods, nil
+	informerFactory.Core().V1().ResourceQuotas().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.enqueueCapacityTargetsFromResourceQuota(newObj)
+		},
+		DeleteFunc: c.enqueueCapacityTargetsFromResourceQuota,
+	})
 }
 
-func (c *Controller) patchDeploymentWithReplicaCount(deployment *appsv1.Deployment, clusterName string, replicaCount int32) (*appsv1.Deployment, error) {
-	targetClusterClient, err := c.clusterClientStore.GetClient(clusterName, AgentName)
-	if err != nil {
-		return nil, err
-	}
-
-	patch := []byte(fmt.Sprintf(`{"spec": {"replicas": %d}}`, replicaCount))
-
-	updatedDeployment, err := targetClusterClient.AppsV1().
-		Deployments(deployment.Namespace).
-		Patch(deployment.Name, types.StrategicMergePatchType, patch)
-	if err != nil {
-		return nil, shippererrors.NewKubeclientUpdateError(deployment, err)
-	}
-
-	return updatedDeployment, nil
+func (c *Controller) subscribeToDeployments(informerFactory kubeinformers.SharedInformerFactory) {
+	informerFactory.Apps().V1().Deployments().Informer()
+	informerFactory.Apps().V1().StatefulSets().Informer()
+	informerFactory.Autoscaling().V1().HorizontalPodAutoscalers().Informer()
+	informerFactory.Policy().V1beta1().PodDisruptionBudgets().Informer()
+	informerFactory.Core().V1().ConfigMaps().Informer()
+	informerFactory.Core().V1().ResourceQuotas().Informer()
+	informerFactory.Core().V1().Pods().Informer()
 }
 
 func (c *Controller) reportConditionChange(ct *shipper.CapacityTarget, reason string, diff diffutil.Diff) {
@@ -7,7 +7,9 @@ import (
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
@@ -66,6 +68,45 @@ func TestSingleCluster(t *testing.T) {
 	)
 }
 
+// TestSingleClusterReplicaCount verifies that an absolute replica count takes
+// precedence over the percentage of the total replica count.
+func TestSingleClusterReplicaCount(t *testing.T) {
+	totalReplicaCount := int32(10)
+	expectedReplicaCount := int32(3)
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, []shipper.ClusterCapacityTarget{
+		{
+			Name:              clusterA,
+			Percent:           1,
+			TotalReplicaCount: totalReplicaCount,
+			Replicas:          &expectedReplicaCount,
+		},
+	})
+
+	// 3 replicas out of 10 is reported the same way 30% would be.
+	status := buildSuccessStatus(ctName, []shipper.ClusterCapacityTarget{
+		{
+			Name:              clusterA,
+			Percent:           30,
+			TotalReplicaCount: totalReplicaCount,
+		},
+	})
+
+	runCapacityControllerTest(t,
+		map[string][]runtime.Object{
+			clusterA: []runtime.Object{buildDeployment(shippertesting.TestApp, ctName, 0, expectedReplicaCount)},
+		},
+		[]capacityTargetTestExpectation{
+			{
+				capacityTarget: ct,
+				status:         status,
+				replicasByCluster: map[string]int32{
+					clusterA: expectedReplicaCount,
+				},
+			},
+		},
+	)
+}
+
 // TestMultipleClusters does the same thing as TestSingleCluster, but does so
 // for multiple clusters.
 func TestMultipleClusters(t *testing.T) {
@@ -267,6 +308,74 @@ func TestCapacityShiftingSadPods(t *testing.T) {
 	)
 }
 
+// TestHorizontalPodAutoscaler verifies that the capacity controller scales the
+// bounds of a HorizontalPodAutoscaler instead of the deployment it targets,
+// and judges readiness against the replicas the autoscaler wants.
+func TestHorizontalPodAutoscaler(t *testing.T) {
+	totalReplicaCount := int32(10)
+	autoscaledReplicaCount := int32(3)
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, []shipper.ClusterCapacityTarget{
+		{
+			Name:              clusterA,
+			Percent:           50,
+			TotalReplicaCount: totalReplicaCount,
+		},
+	})
+
+	minReplicas := int32(4)
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ctName,
+			Namespace: shippertesting.TestNamespace,
+			Labels: map[string]string{
+				shipper.AppLabel:     shippertesting.TestApp,
+				shipper.ReleaseLabel: ctName,
+			},
+		},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+				Kind: "Deployment",
+				Name: ctName,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: 20,
+		},
+		Status: autoscalingv1.HorizontalPodAutoscalerStatus{
+			DesiredReplicas: autoscaledReplicaCount,
+		},
+	}
+
+	// 3 replicas out of 10 is what the autoscaler wants, so that's what
+	// gets reported, regardless of the 50% the strategy asks for.
+	status := buildSuccessStatus(ctName, []shipper.ClusterCapacityTarget{
+		{
+			Name:              clusterA,
+			Percent:           30,
+			TotalReplicaCount: totalReplicaCount,
+		},
+	})
+
+	// The deployment only gets scaled up to the minimum of the
+	// autoscaler, which then takes over.
+	runCapacityControllerTest(t,
+		map[string][]runtime.Object{
+			clusterA: []runtime.Object{
+				buildDeployment(shippertesting.TestApp, ctName, 0, autoscaledReplicaCount),
+				hpa,
+			},
+		},
+		[]capacityTargetTestExpectation{
+			{
+				capacityTarget: ct,
+				status:         status,
+				replicasByCluster: map[string]int32{
+					clusterA: 2,
+				},
+			},
+		},
+	)
+}
+
 func runCapacityControllerTest(
 	t *testing.T,
 	objectsByCluster map[string][]runtime.Object,
@@ -342,11 +451,16 @@ func assertDeploymentReplicas(
 }
 
 func runController(f *shippertesting.ControllerTestFixture) {
+	runControllerWithPodDetails(f, true)
+}
+
+func runControllerWithPodDetails(f *shippertesting.ControllerTestFixture, reportPodDetails bool) {
 	controller := NewController(
 		f.ShipperClient,
 		f.ShipperInformerFactory,
 		f.ClusterClientStore,
 		f.Recorder,
+		reportPodDetails,
 	)
 
 	stopCh := make(chan struct{})
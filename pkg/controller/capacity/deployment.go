@@ -6,8 +6,8 @@ import (
 	"sort"
 	"strings"
 
-	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
 
@@ -15,23 +15,25 @@ import (
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
 )
 
-func (c *Controller) enqueueCapacityTargetFromDeployment(obj interface{}) {
-	deployment, ok := obj.(*appsv1.Deployment)
+// enqueueCapacityTargetFromObject enqueues the capacity target of the release
+// that the given object, such as a Deployment or a StatefulSet, belongs to.
+func (c *Controller) enqueueCapacityTargetFromObject(obj interface{}) {
+	object, ok := obj.(metav1.Object)
 	if !ok {
-		runtime.HandleError(fmt.Errorf("not a Deployment: %#v", obj))
+		runtime.HandleError(fmt.Errorf("not a metav1.Object: %#v", obj))
 		return
 	}
 
-	// Using ReleaseLabel here instead of the full set of Deployment labels because
+	// Using ReleaseLabel here instead of the full set of object labels because
 	// we can't guarantee that there isn't extra stuff there that was put directly
 	// in the chart.
 	// Also not using ObjectReference here because it would go over cluster
 	// boundaries. While technically it's probably ok, I feel like it'd be abusing
 	// the feature.
-	rel := deployment.GetLabels()[shipper.ReleaseLabel]
-	ct, err := c.getCapacityTargetForReleaseAndNamespace(rel, deployment.GetNamespace())
+	rel := object.GetLabels()[shipper.ReleaseLabel]
+	ct, err := c.getCapacityTargetForReleaseAndNamespace(rel, object.GetNamespace())
 	if err != nil {
-		runtime.HandleError(fmt.Errorf("cannot get capacity target for release '%s/%s': %#v", rel, deployment.GetNamespace(), err))
+		runtime.HandleError(fmt.Errorf("cannot get capacity target for release '%s/%s': %#v", rel, object.GetNamespace(), err))
 		return
 	}
 
@@ -0,0 +1,187 @@
+package capacity
+
+import (
+	"encoding/json"
+	"strconv"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/util/replicas"
+)
+
+// getHorizontalPodAutoscaler returns the HorizontalPodAutoscaler scaling the
+// given workload, or nil if there is none.
+func (c Controller) getHorizontalPodAutoscaler(cluster string, w *workload) (*autoscalingv1.HorizontalPodAutoscaler, error) {
+	informerFactory, err := c.clusterClientStore.GetInformerFactory(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := labels.Everything()
+	hpas, err := informerFactory.Autoscaling().V1().HorizontalPodAutoscalers().
+		Lister().HorizontalPodAutoscalers(w.GetNamespace()).List(selector)
+	if err != nil {
+		return nil, shippererrors.NewKubeclientListError(
+			autoscalingv1.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler"),
+			w.GetNamespace(), selector, err)
+	}
+
+	for _, hpa := range hpas {
+		ref := hpa.Spec.ScaleTargetRef
+		if ref.Kind == w.kind && ref.Name == w.GetName() {
+			return hpa, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// horizontalPodAutoscalerBounds returns the replica bounds of a
+// HorizontalPodAutoscaler as rendered from the chart.
+func horizontalPodAutoscalerBounds(hpa *autoscalingv1.HorizontalPodAutoscaler) (int32, int32) {
+	minReplicas := int32(1)
+	if hpa.Spec.MinReplicas != nil {
+		minReplicas = *hpa.Spec.MinReplicas
+	}
+	maxReplicas := hpa.Spec.MaxReplicas
+
+	if v, err := strconv.Atoi(hpa.Annotations[shipper.HPAChartMinReplicasAnnotation]); err == nil {
+		minReplicas = int32(v)
+	}
+	if v, err := strconv.Atoi(hpa.Annotations[shipper.HPAChartMaxReplicasAnnotation]); err == nil {
+		maxReplicas = int32(v)
+	}
+
+	return minReplicas, maxReplicas
+}
+
+// scaleHorizontalPodAutoscalerBounds returns the replica bounds a
+// HorizontalPodAutoscaler should have for a release to run desiredReplicas
+// out of totalReplicaCount: the bounds from the chart, scaled in the same
+// proportion. The minimum is never lower than 1, as the autoscaler would
+// not be able to scale a workload back up from 0 replicas.
+func scaleHorizontalPodAutoscalerBounds(hpa *autoscalingv1.HorizontalPodAutoscaler, totalReplicaCount, desiredReplicas int32) (int32, int32) {
+	chartMin, chartMax := horizontalPodAutoscalerBounds(hpa)
+
+	percent := float64(100)
+	if totalReplicaCount > 0 && desiredReplicas < totalReplicaCount {
+		percent = float64(desiredReplicas) * 100 / float64(totalReplicaCount)
+	}
+
+	minReplicas := int32(replicas.CalculateDesiredReplicaCount(uint(chartMin), percent))
+	if minReplicas < 1 {
+		minReplicas = 1
+	}
+
+	maxReplicas := int32(replicas.CalculateDesiredReplicaCount(uint(chartMax), percent))
+	if maxReplicas < minReplicas {
+		maxReplicas = minReplicas
+	}
+
+	return minReplicas, maxReplicas
+}
+
+func (c *Controller) patchHorizontalPodAutoscaler(
+	hpa *autoscalingv1.HorizontalPodAutoscaler,
+	clusterName string,
+	minReplicas, maxReplicas int32,
+) (*autoscalingv1.HorizontalPodAutoscaler, error) {
+	targetClusterClient, err := c.clusterClientStore.GetClient(clusterName, AgentName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The bounds from the chart are recorded before being changed for
+	// the first time, so that they can still be scaled from later on.
+	chartMin, chartMax := horizontalPodAutoscalerBounds(hpa)
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				shipper.HPAChartMinReplicasAnnotation: strconv.Itoa(int(chartMin)),
+				shipper.HPAChartMaxReplicasAnnotation: strconv.Itoa(int(chartMax)),
+			},
+		},
+		"spec": map[string]interface{}{
+			"minReplicas": minReplicas,
+			"maxReplicas": maxReplicas,
+		},
+	})
+	if err != nil {
+		return nil, shippererrors.NewUnrecoverableError(err)
+	}
+
+	updatedHPA, err := targetClusterClient.AutoscalingV1().
+		HorizontalPodAutoscalers(hpa.Namespace).
+		Patch(hpa.Name, types.MergePatchType, patch)
+	if err != nil {
+		return nil, shippererrors.NewKubeclientUpdateError(hpa, err)
+	}
+
+	return updatedHPA, nil
+}
+
+// horizontalPodAutoscalerNeedsPatch returns whether a HorizontalPodAutoscaler
+// doesn't have the given bounds yet, or doesn't record its chart bounds.
+func horizontalPodAutoscalerNeedsPatch(hpa *autoscalingv1.HorizontalPodAutoscaler, minReplicas, maxReplicas int32) bool {
+	if _, ok := hpa.Annotations[shipper.HPAChartMinReplicasAnnotation]; !ok {
+		return true
+	}
+
+	if _, ok := hpa.Annotations[shipper.HPAChartMaxReplicasAnnotation]; !ok {
+		return true
+	}
+
+	return hpa.Spec.MinReplicas == nil ||
+		*hpa.Spec.MinReplicas != minReplicas ||
+		hpa.Spec.MaxReplicas != maxReplicas
+}
+
+// processHorizontalPodAutoscaler scales the bounds of a HorizontalPodAutoscaler
+// so that it runs desiredReplicas out of totalReplicaCount, and returns the
+// number of replicas the autoscaler currently wants the workload to have.
+// It also returns whether a change is still in flight, in which case the
+// status of the autoscaler can't be trusted yet.
+func (c *Controller) processHorizontalPodAutoscaler(
+	hpa *autoscalingv1.HorizontalPodAutoscaler,
+	w *workload,
+	clusterName string,
+	totalReplicaCount, desiredReplicas int32,
+) (int32, bool, error) {
+	minReplicas, maxReplicas := scaleHorizontalPodAutoscalerBounds(hpa, totalReplicaCount, desiredReplicas)
+
+	if horizontalPodAutoscalerNeedsPatch(hpa, minReplicas, maxReplicas) {
+		if _, err := c.patchHorizontalPodAutoscaler(hpa, clusterName, minReplicas, maxReplicas); err != nil {
+			return 0, false, err
+		}
+
+		return 0, true, nil
+	}
+
+	// Autoscaling is disabled for workloads with no replicas, which is
+	// how the installer creates them, so the workload needs to be scaled
+	// up once for the autoscaler to take over.
+	if w.replicas == nil || *w.replicas == 0 {
+		if err := c.patchWorkloadWithReplicaCount(w, clusterName, minReplicas); err != nil {
+			return 0, false, err
+		}
+
+		return 0, true, nil
+	}
+
+	if hpa.Status.ObservedGeneration != nil && *hpa.Status.ObservedGeneration < hpa.Generation {
+		return 0, true, nil
+	}
+
+	replicaCount := hpa.Status.DesiredReplicas
+	if replicaCount < minReplicas {
+		replicaCount = minReplicas
+	} else if replicaCount > maxReplicas {
+		replicaCount = maxReplicas
+	}
+
+	return replicaCount, false, nil
+}
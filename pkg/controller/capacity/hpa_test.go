@@ -0,0 +1,73 @@
+package capacity
+
+import (
+	"testing"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestScaleHorizontalPodAutoscalerBounds(t *testing.T) {
+	var tests = []struct {
+		title           string
+		annotations     map[string]string
+		minReplicas     int32
+		maxReplicas     int32
+		desiredReplicas int32
+		expectedMin     int32
+		expectedMax     int32
+	}{
+		{
+			"full capacity",
+			nil,
+			4, 20,
+			10,
+			4, 20,
+		},
+		{
+			"half capacity",
+			nil,
+			4, 20,
+			5,
+			2, 10,
+		},
+		{
+			"minimum is never scaled below 1",
+			nil,
+			2, 20,
+			1,
+			1, 2,
+		},
+		{
+			"bounds are scaled from the chart annotations",
+			map[string]string{
+				shipper.HPAChartMinReplicasAnnotation: "4",
+				shipper.HPAChartMaxReplicasAnnotation: "20",
+			},
+			1, 2,
+			5,
+			2, 10,
+		},
+	}
+
+	for _, test := range tests {
+		minReplicas := test.minReplicas
+		hpa := &autoscalingv1.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: test.annotations,
+			},
+			Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+				MinReplicas: &minReplicas,
+				MaxReplicas: test.maxReplicas,
+			},
+		}
+
+		min, max := scaleHorizontalPodAutoscalerBounds(hpa, 10, test.desiredReplicas)
+		if min != test.expectedMin || max != test.expectedMax {
+			t.Errorf("testing %s: expected bounds [%d, %d], got [%d, %d]",
+				test.title, test.expectedMin, test.expectedMax, min, max)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package capacity
+
+import (
+	"reflect"
+
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/util/anchor"
+)
+
+const (
+	// PodDisruptionBudgetSuffix is appended to the name of a release to
+	// name the PodDisruptionBudget Shipper maintains for it.
+	PodDisruptionBudgetSuffix = "-shipper-pdb"
+
+	// PodDisruptionBudgetMaxUnavailable is the share of the pods of a
+	// release that voluntary disruptions, such as node drains, can take
+	// down at once. Kubernetes rounds it up, so at least one pod can
+	// always be disrupted.
+	PodDisruptionBudgetMaxUnavailable = "25%"
+)
+
+// syncPodDisruptionBudget makes sure the pods of a workload are covered by a
+// PodDisruptionBudget. The budget is relative to the pods that are actually
+// there, rather than to the ones the release should have at its current step,
+// as there can be fewer of them while a step is scaling up or when an
+// autoscaler decides so, and node drains would then be blocked. The budget
+// is owned by the release's anchor, so it goes away with the release.
+// Workloads are only around once their release is installed, so a missing
+// anchor means the release is on its way out, and doesn't need a budget
+// anymore.
+func (c *Controller) syncPodDisruptionBudget(
+	ct *shipper.CapacityTarget,
+	w *workload,
+	clusterName string,
+) error {
+	informerFactory, err := c.clusterClientStore.GetInformerFactory(clusterName)
+	if err != nil {
+		return err
+	}
+
+	release := ct.Labels[shipper.ReleaseLabel]
+	anchorName := anchor.ReleaseAnchorName(release)
+	configMap, err := informerFactory.Core().V1().ConfigMaps().Lister().
+		ConfigMaps(ct.Namespace).Get(anchorName)
+	if kerrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return shippererrors.NewKubeclientGetError(ct.Namespace, anchorName, err).
+			WithCoreV1Kind("ConfigMap")
+	}
+
+	maxUnavailable := intstr.FromString(PodDisruptionBudgetMaxUnavailable)
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      release + PodDisruptionBudgetSuffix,
+			Namespace: ct.Namespace,
+			Labels:    ct.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				anchor.ConfigMapAnchorToOwnerReference(configMap),
+			},
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector:       w.selector,
+		},
+	}
+
+	client, err := c.clusterClientStore.GetClient(clusterName, AgentName)
+	if err != nil {
+		return err
+	}
+
+	existing, err := informerFactory.Policy().V1beta1().PodDisruptionBudgets().Lister().
+		PodDisruptionBudgets(pdb.Namespace).Get(pdb.Name)
+	if kerrors.IsNotFound(err) {
+		_, err = client.PolicyV1beta1().PodDisruptionBudgets(pdb.Namespace).Create(pdb)
+		if err != nil {
+			return shippererrors.NewKubeclientCreateError(pdb, err).
+				WithKind(policyv1beta1.SchemeGroupVersion.WithKind("PodDisruptionBudget"))
+		}
+
+		return nil
+	} else if err != nil {
+		return shippererrors.NewKubeclientGetError(pdb.Namespace, pdb.Name, err).
+			WithKind(policyv1beta1.SchemeGroupVersion.WithKind("PodDisruptionBudget"))
+	}
+
+	if reflect.DeepEqual(existing.Spec, pdb.Spec) {
+		return nil
+	}
+
+	updated := existing.DeepCopy()
+	updated.Spec = pdb.Spec
+	_, err = client.PolicyV1beta1().PodDisruptionBudgets(pdb.Namespace).Update(updated)
+	if err != nil {
+		return shippererrors.NewKubeclientUpdateError(updated, err).
+			WithKind(policyv1beta1.SchemeGroupVersion.WithKind("PodDisruptionBudget"))
+	}
+
+	return nil
+}
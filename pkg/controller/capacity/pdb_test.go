@@ -0,0 +1,101 @@
+package capacity
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	"github.com/bookingcom/shipper/pkg/util/anchor"
+)
+
+// TestPodDisruptionBudget verifies that the capacity controller creates a
+// PodDisruptionBudget for a release that doesn't have one yet, and updates
+// existing ones that budget for a fixed number of pods.
+func TestPodDisruptionBudget(t *testing.T) {
+	totalReplicaCount := int32(10)
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, []shipper.ClusterCapacityTarget{
+		{
+			Name:              clusterA,
+			Percent:           80,
+			TotalReplicaCount: totalReplicaCount,
+		},
+	})
+
+	deployment := buildDeployment(shippertesting.TestApp, ctName, 8, 8)
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      anchor.ReleaseAnchorName(ctName),
+			Namespace: shippertesting.TestNamespace,
+			UID:       "anchor-uid",
+		},
+		Data: map[string]string{
+			anchor.InstallationTargetUID: ctName,
+		},
+	}
+
+	staleMinAvailable := intstr.FromInt(1)
+	stalePDB := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ctName + PodDisruptionBudgetSuffix,
+			Namespace: shippertesting.TestNamespace,
+			Labels:    ct.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				anchor.ConfigMapAnchorToOwnerReference(configMap),
+			},
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MinAvailable: &staleMinAvailable,
+			Selector:     deployment.Spec.Selector,
+		},
+	}
+
+	var tests = []struct {
+		title   string
+		objects []runtime.Object
+	}{
+		{"budget is created", []runtime.Object{deployment, configMap}},
+		{"budget is updated", []runtime.Object{deployment, configMap, stalePDB}},
+	}
+
+	for _, test := range tests {
+		f := shippertesting.NewControllerTestFixture()
+		f.AddNamedCluster(clusterA).AddMany(test.objects)
+		f.ShipperClient.Tracker().Add(ct)
+
+		runController(f)
+
+		pdbGVR := policyv1beta1.SchemeGroupVersion.WithResource("poddisruptionbudgets")
+		object, err := f.Clusters[clusterA].Client.Tracker().Get(
+			pdbGVR, shippertesting.TestNamespace, ctName+PodDisruptionBudgetSuffix)
+		if err != nil {
+			t.Errorf("testing %s: could not Get PodDisruptionBudget: %s", test.title, err)
+			continue
+		}
+
+		pdb := object.(*policyv1beta1.PodDisruptionBudget)
+
+		expectedMaxUnavailable := intstr.FromString("25%")
+		if pdb.Spec.MinAvailable != nil || pdb.Spec.MaxUnavailable == nil ||
+			*pdb.Spec.MaxUnavailable != expectedMaxUnavailable {
+			t.Errorf("testing %s: expected max unavailable %s, got min available %v and max unavailable %v",
+				test.title, expectedMaxUnavailable.String(), pdb.Spec.MinAvailable, pdb.Spec.MaxUnavailable)
+		}
+
+		eq, diff := shippertesting.DeepEqualDiff(deployment.Spec.Selector, pdb.Spec.Selector)
+		if !eq {
+			t.Errorf("testing %s: expected budget to select the pods of the deployment:\n%s",
+				test.title, diff)
+		}
+
+		if len(pdb.OwnerReferences) != 1 || pdb.OwnerReferences[0].UID != configMap.UID {
+			t.Errorf("testing %s: expected budget to be owned by the release anchor, got %v",
+				test.title, pdb.OwnerReferences)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package capacity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+const (
+	// ReportEventLimit is the most events of sad pods a capacity report
+	// holds for a cluster.
+	ReportEventLimit = 10
+
+	// ReportLogLimit is the most crash-looping containers a capacity
+	// report holds log excerpts of for a cluster. Only one of the pods
+	// is looked at for each container name, as they usually all crash
+	// the same way.
+	ReportLogLimit = 3
+
+	// ReportLogTailLines and ReportLogTailBytes bound each log excerpt.
+	ReportLogTailLines = 20
+	ReportLogTailBytes = 2048
+
+	// ReportMessageLimit bounds the message of each event.
+	ReportMessageLimit = 512
+
+	// containerLogsSize and containerLogsTTL bound how many log excerpts
+	// we keep around, and for how long, so we don't fetch them again on
+	// every sync.
+	containerLogsSize = 1000
+	containerLogsTTL  = time.Hour
+
+	crashLoopBackOff = "CrashLoopBackOff"
+)
+
+// podEventsIndex indexes the events of an application cluster by the pod
+// they are about, so reports don't have to list events for every sad pod.
+const podEventsIndex = "involvedObject.pod"
+
+// getPodLogs fetches the logs of a container. It is only a variable so tests
+// can replace it, as fake clientsets can't return logs.
+var getPodLogs = func(client kubernetes.Interface, namespace, pod string, opts *corev1.PodLogOptions) ([]byte, error) {
+	return client.CoreV1().Pods(namespace).GetLogs(pod, opts).Do().Raw()
+}
+
+// containerLogKey identifies a container whose logs we fetched.
+type containerLogKey struct {
+	cluster   string
+	namespace string
+	pod       string
+	container string
+}
+
+// containerLogTail is the end of the logs of the previous run of a
+// container, as of restartCount restarts. There's no need to fetch them
+// again until the container restarts.
+type containerLogTail struct {
+	restartCount int32
+	tail         string
+}
+
+func indexEventsByPod(obj interface{}) ([]string, error) {
+	event, ok := obj.(*corev1.Event)
+	if !ok || event.InvolvedObject.Kind != "Pod" {
+		return nil, nil
+	}
+
+	return []string{podKey(event.InvolvedObject.Namespace, event.InvolvedObject.Name)}, nil
+}
+
+func podKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+func (c *Controller) subscribeToPodEvents(informerFactory kubeinformers.SharedInformerFactory) {
+	err := informerFactory.Core().V1().Events().Informer().AddIndexers(cache.Indexers{
+		podEventsIndex: indexEventsByPod,
+	})
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("cannot index events by pod: %s", err))
+	}
+}
+
+// addPodDetailsToReport attaches the Warning events of sadPods, and the end
+// of the logs of the previous run of their crash-looping containers, to
+// report. Those are what users would go look for in the application cluster
+// to find out why pods aren't ready. Failing to get them doesn't stop us from
+// reporting everything else, so errors are only logged.
+func (c *Controller) addPodDetailsToReport(
+	clusterName string,
+	namespace string,
+	sadPods []shipper.PodStatus,
+	report *shipper.ClusterCapacityReport,
+) {
+	informerFactory, err := c.clusterClientStore.GetInformerFactory(clusterName)
+	if err != nil {
+		klog.V(2).Infof("Cannot get informers for cluster %q to report on sad pods: %s", clusterName, err)
+		return
+	}
+
+	report.Events = getSadPodEvents(informerFactory.Core().V1().Events().Informer().GetIndexer(), namespace, sadPods)
+	report.Logs = c.getCrashLoopingContainerLogs(clusterName, namespace, sadPods)
+}
+
+// getSadPodEvents returns the Warning events of sadPods, the ones that
+// started the latest first. Events are ordered by when they first happened
+// so that reports stay the same when an event happens again.
+func getSadPodEvents(
+	indexer cache.Indexer,
+	namespace string,
+	sadPods []shipper.PodStatus,
+) []shipper.ClusterCapacityReportPodEvent {
+	var events []shipper.ClusterCapacityReportPodEvent
+	for _, sadPod := range sadPods {
+		objects, err := indexer.ByIndex(podEventsIndex, podKey(namespace, sadPod.Name))
+		if err != nil {
+			klog.V(2).Infof("Cannot get events of pod \"%s/%s\": %s", namespace, sadPod.Name, err)
+			continue
+		}
+
+		for _, obj := range objects {
+			event, ok := obj.(*corev1.Event)
+			if !ok || event.Type != corev1.EventTypeWarning {
+				continue
+			}
+
+			firstTimestamp := event.FirstTimestamp
+			if firstTimestamp.IsZero() {
+				firstTimestamp = metav1.NewTime(event.EventTime.Time)
+			}
+
+			events = append(events, shipper.ClusterCapacityReportPodEvent{
+				Pod:            sadPod.Name,
+				Reason:         event.Reason,
+				Message:        truncate(event.Message, ReportMessageLimit),
+				FirstTimestamp: firstTimestamp,
+			})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if !a.FirstTimestamp.Equal(&b.FirstTimestamp) {
+			return b.FirstTimestamp.Before(&a.FirstTimestamp)
+		}
+
+		if a.Pod != b.Pod {
+			return a.Pod < b.Pod
+		}
+
+		if a.Reason != b.Reason {
+			return a.Reason < b.Reason
+		}
+
+		return a.Message < b.Message
+	})
+
+	if len(events) > ReportEventLimit {
+		events = events[:ReportEventLimit]
+	}
+
+	return events
+}
+
+// getCrashLoopingContainerLogs returns the end of the logs of the previous
+// run of crash-looping containers of sadPods. Logs are only fetched again
+// once a container restarts, as they can't have changed before that.
+func (c *Controller) getCrashLoopingContainerLogs(
+	clusterName string,
+	namespace string,
+	sadPods []shipper.PodStatus,
+) []shipper.ClusterCapacityReportContainerLog {
+	var logs []shipper.ClusterCapacityReportContainerLog
+	seen := make(map[string]struct{})
+
+	// There's no asking for the last bytes of logs: the kubelet applies
+	// LimitBytes from the start of the lines it tails, which would cut
+	// off the end, so only lines are limited here.
+	tailLines := int64(ReportLogTailLines)
+
+	var client kubernetes.Interface
+
+	for _, sadPod := range sadPods {
+		containers := make([]corev1.ContainerStatus, 0, len(sadPod.InitContainers)+len(sadPod.Containers))
+		containers = append(containers, sadPod.InitContainers...)
+		containers = append(containers, sadPod.Containers...)
+
+		for _, container := range containers {
+			if len(logs) >= ReportLogLimit {
+				return logs
+			}
+
+			if _, ok := seen[container.Name]; ok || !isCrashLooping(container) {
+				continue
+			}
+
+			seen[container.Name] = struct{}{}
+
+			key := containerLogKey{
+				cluster:   clusterName,
+				namespace: namespace,
+				pod:       sadPod.Name,
+				container: container.Name,
+			}
+
+			if cached, ok := c.containerLogs.Get(key); ok {
+				if logTail := cached.(containerLogTail); logTail.restartCount == container.RestartCount {
+					logs = append(logs, shipper.ClusterCapacityReportContainerLog{
+						Pod:       sadPod.Name,
+						Container: container.Name,
+						Tail:      logTail.tail,
+					})
+					continue
+				}
+			}
+
+			if client == nil {
+				var err error
+				client, err = c.clusterClientStore.GetClient(clusterName, AgentName)
+				if err != nil {
+					klog.V(2).Infof("Cannot get client for cluster %q to get logs of sad pods: %s", clusterName, err)
+					return logs
+				}
+			}
+
+			raw, err := getPodLogs(client, namespace, sadPod.Name, &corev1.PodLogOptions{
+				Container: container.Name,
+				Previous:  true,
+				TailLines: &tailLines,
+			})
+			if err != nil {
+				klog.V(2).Infof("Cannot get logs of container %q of pod \"%s/%s\": %s",
+					container.Name, namespace, sadPod.Name, err)
+				continue
+			}
+
+			logTail := containerLogTail{
+				restartCount: container.RestartCount,
+				tail:         tail(string(raw), ReportLogTailBytes),
+			}
+			c.containerLogs.Add(key, logTail, containerLogsTTL)
+
+			logs = append(logs, shipper.ClusterCapacityReportContainerLog{
+				Pod:       sadPod.Name,
+				Container: container.Name,
+				Tail:      logTail.tail,
+			})
+		}
+	}
+
+	return logs
+}
+
+func isCrashLooping(container corev1.ContainerStatus) bool {
+	if container.LastTerminationState.Terminated == nil {
+		return false
+	}
+
+	waiting := container.State.Waiting
+	return waiting != nil && waiting.Reason == crashLoopBackOff
+}
+
+// truncate cuts s down to its first limit bytes.
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+
+	return strings.ToValidUTF8(s[:limit], "")
+}
+
+// tail cuts s down to its last limit bytes, as the end of logs is where the
+// reason a container crashed usually is.
+func tail(s string, limit int) string {
+	if len(s) > limit {
+		s = s[len(s)-limit:]
+	}
+
+	return strings.ToValidUTF8(s, "")
+}
@@ -0,0 +1,206 @@
+package capacity
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+// TestReportPodDetails verifies that the capacity controller attaches the
+// latest Warning events of sad pods and the end of the logs of their
+// crash-looping containers to capacity reports, within limits, and only when
+// asked to.
+func TestReportPodDetails(t *testing.T) {
+	totalReplicaCount := int32(10)
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, []shipper.ClusterCapacityTarget{
+		{
+			Name:              clusterA,
+			Percent:           100,
+			TotalReplicaCount: totalReplicaCount,
+		},
+	})
+
+	deployment := buildDeployment(shippertesting.TestApp, ctName, totalReplicaCount, 5)
+	sadPod := buildSadPodForDeployment(deployment)
+	sadPod.Status.ContainerStatuses[0].State.Waiting.Reason = crashLoopBackOff
+	sadPod.Status.ContainerStatuses[0].LastTerminationState = corev1.ContainerState{
+		Terminated: &corev1.ContainerStateTerminated{
+			ExitCode: 2,
+			Reason:   "Error",
+		},
+	}
+
+	objects := []runtime.Object{deployment, sadPod}
+
+	now := time.Now()
+	for i := 0; i < ReportEventLimit+2; i++ {
+		objects = append(objects, buildPodEvent(sadPod.Name, fmt.Sprintf("warning-%d", i),
+			corev1.EventTypeWarning, now.Add(time.Duration(i)*time.Minute)))
+	}
+
+	objects = append(objects,
+		buildPodEvent(sadPod.Name, "normal", corev1.EventTypeNormal, now.Add(time.Hour)),
+		buildPodEvent("another-pod", "another-warning", corev1.EventTypeWarning, now.Add(time.Hour)),
+	)
+
+	logs := strings.Repeat("x", ReportLogTailBytes) + "panic: the end"
+	defer func(orig func(kubernetes.Interface, string, string, *corev1.PodLogOptions) ([]byte, error)) {
+		getPodLogs = orig
+	}(getPodLogs)
+	getPodLogs = func(client kubernetes.Interface, namespace, pod string, opts *corev1.PodLogOptions) ([]byte, error) {
+		if pod != sadPod.Name || opts.Container != "app" || !opts.Previous || opts.LimitBytes != nil {
+			return nil, fmt.Errorf("unexpected logs request for container %q of pod %q", opts.Container, pod)
+		}
+
+		return []byte(logs), nil
+	}
+
+	for _, reportPodDetails := range []bool{true, false} {
+		f := shippertesting.NewControllerTestFixture()
+		f.AddNamedCluster(clusterA).AddMany(objects)
+		f.ShipperClient.Tracker().Add(ct)
+
+		runControllerWithPodDetails(f, reportPodDetails)
+
+		ctGVR := shipper.SchemeGroupVersion.WithResource("capacitytargets")
+		object, err := f.ShipperClient.Tracker().Get(ctGVR, ct.Namespace, ct.Name)
+		if err != nil {
+			t.Fatalf("could not Get CapacityTarget: %s", err)
+		}
+
+		status := object.(*shipper.CapacityTarget).Status
+		if len(status.Clusters) != 1 || len(status.Clusters[0].Reports) != 1 {
+			t.Fatalf("expected a report for 1 cluster, got %v", status.Clusters)
+		}
+
+		report := status.Clusters[0].Reports[0]
+
+		if !reportPodDetails {
+			if len(report.Events) != 0 || len(report.Logs) != 0 {
+				t.Errorf("expected no pod details in report, got events %v and logs %v",
+					report.Events, report.Logs)
+			}
+			continue
+		}
+
+		if len(report.Events) != ReportEventLimit {
+			t.Fatalf("expected %d events in report, got %d: %v",
+				ReportEventLimit, len(report.Events), report.Events)
+		}
+
+		for i, event := range report.Events {
+			expectedReason := fmt.Sprintf("warning-%d", ReportEventLimit+1-i)
+			if event.Pod != sadPod.Name || event.Reason != expectedReason {
+				t.Errorf("expected event %d to be %q for pod %q, got %q for pod %q",
+					i, expectedReason, sadPod.Name, event.Reason, event.Pod)
+			}
+		}
+
+		expectedLogs := []shipper.ClusterCapacityReportContainerLog{
+			{
+				Pod:       sadPod.Name,
+				Container: "app",
+				Tail:      logs[len(logs)-ReportLogTailBytes:],
+			},
+		}
+
+		eq, diff := shippertesting.DeepEqualDiff(expectedLogs, report.Logs)
+		if !eq {
+			t.Errorf("logs in report different from expected:\n%s", diff)
+		}
+	}
+}
+
+// TestCrashLoopingContainerLogsAreCached verifies that the logs of a
+// crash-looping container are only fetched again once it restarts.
+func TestCrashLoopingContainerLogsAreCached(t *testing.T) {
+	f := shippertesting.NewControllerTestFixture()
+	f.AddNamedCluster(clusterA)
+
+	controller := NewController(
+		f.ShipperClient,
+		f.ShipperInformerFactory,
+		f.ClusterClientStore,
+		f.Recorder,
+		true,
+	)
+
+	fetches := 0
+	defer func(orig func(kubernetes.Interface, string, string, *corev1.PodLogOptions) ([]byte, error)) {
+		getPodLogs = orig
+	}(getPodLogs)
+	getPodLogs = func(client kubernetes.Interface, namespace, pod string, opts *corev1.PodLogOptions) ([]byte, error) {
+		fetches++
+		return []byte(fmt.Sprintf("panic: run %d", fetches)), nil
+	}
+
+	sadPod := shipper.PodStatus{
+		Name: "foobar-deadbeef",
+		Containers: []corev1.ContainerStatus{
+			{
+				Name:         "app",
+				RestartCount: 1,
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: crashLoopBackOff},
+				},
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 2},
+				},
+			},
+		},
+	}
+
+	var tests = []struct {
+		title           string
+		restartCount    int32
+		expectedFetches int
+		expectedTail    string
+	}{
+		{"first sync", 1, 1, "panic: run 1"},
+		{"same run", 1, 1, "panic: run 1"},
+		{"container restarted", 2, 2, "panic: run 2"},
+	}
+
+	for _, test := range tests {
+		sadPod.Containers[0].RestartCount = test.restartCount
+		logs := controller.getCrashLoopingContainerLogs(clusterA, shippertesting.TestNamespace, []shipper.PodStatus{sadPod})
+
+		if fetches != test.expectedFetches {
+			t.Errorf("testing %s: expected logs to be fetched %d times, got %d",
+				test.title, test.expectedFetches, fetches)
+		}
+
+		if len(logs) != 1 || logs[0].Tail != test.expectedTail {
+			t.Errorf("testing %s: expected logs %q, got %v", test.title, test.expectedTail, logs)
+		}
+	}
+}
+
+func buildPodEvent(pod, reason, eventType string, firstTimestamp time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%s", pod, reason),
+			Namespace: shippertesting.TestNamespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      "Pod",
+			Name:      pod,
+			Namespace: shippertesting.TestNamespace,
+		},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        fmt.Sprintf("%s happened", reason),
+		Count:          1,
+		FirstTimestamp: metav1.NewTime(firstTimestamp),
+		LastTimestamp:  metav1.NewTime(firstTimestamp),
+	}
+}
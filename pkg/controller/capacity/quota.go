@@ -0,0 +1,139 @@
+package capacity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/util/resources"
+)
+
+// enqueueCapacityTargetsFromResourceQuota enqueues every capacity target in
+// the namespace of a ResourceQuota, as any of them might have been waiting
+// for quota to free up.
+func (c *Controller) enqueueCapacityTargetsFromResourceQuota(obj interface{}) {
+	quota, ok := obj.(*corev1.ResourceQuota)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a ResourceQuota: %#v", obj))
+		return
+	}
+
+	capacityTargets, err := c.capacityTargetsLister.CapacityTargets(quota.Namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("cannot list capacity targets in namespace %q: %#v", quota.Namespace, err))
+		return
+	}
+
+	for _, ct := range capacityTargets {
+		c.enqueueCapacityTarget(ct)
+	}
+}
+
+// checkResourceQuotas makes sure that additionalPods more pods like the ones
+// of a workload fit in the ResourceQuotas of its namespace. If they don't, it
+// returns a message saying how much of what is missing in which quota. Only
+// quotas without scopes are considered, as they apply to every pod.
+func (c *Controller) checkResourceQuotas(cluster string, w *workload, additionalPods int32) (string, error) {
+	informerFactory, err := c.clusterClientStore.GetInformerFactory(cluster)
+	if err != nil {
+		return "", err
+	}
+
+	namespace := w.GetNamespace()
+	quotas, err := informerFactory.Core().V1().ResourceQuotas().Lister().
+		ResourceQuotas(namespace).List(labels.Everything())
+	if err != nil {
+		return "", shippererrors.NewKubeclientListError(
+			corev1.SchemeGroupVersion.WithKind("ResourceQuota"),
+			namespace, labels.Everything(), err)
+	}
+
+	sort.Slice(quotas, func(i, j int) bool {
+		return quotas[i].Name < quotas[j].Name
+	})
+
+	usage := podResourceUsage(&w.template.Spec, additionalPods)
+	shortfalls := []string{}
+	for _, quota := range quotas {
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+
+		shortfall := quotaShortfall(quota, usage)
+		if len(shortfall) == 0 {
+			continue
+		}
+
+		shortfalls = append(shortfalls, fmt.Sprintf("%s is short of %s",
+			quota.Name, resources.String(shortfall)))
+	}
+
+	if len(shortfalls) == 0 {
+		return "", nil
+	}
+
+	return fmt.Sprintf("cannot add %d pods to %s %q: resource quota %s",
+		additionalPods, w.kind, w.GetName(), strings.Join(shortfalls, "; resource quota ")), nil
+}
+
+// podResourceUsage returns how much of each resource a ResourceQuota would
+// charge for count pods with the given spec.
+func podResourceUsage(spec *corev1.PodSpec, count int32) corev1.ResourceList {
+	requests := resources.Multiply(resources.PodRequests(*spec), count)
+	limits := resources.Multiply(resources.PodLimits(*spec), count)
+
+	usage := corev1.ResourceList{
+		corev1.ResourcePods: *resource.NewQuantity(int64(count), resource.DecimalSI),
+	}
+
+	for name, request := range requests {
+		usage[corev1.ResourceName("requests."+string(name))] = request
+
+		// Quotas on plain cpu and memory are quotas on requests.
+		if name == corev1.ResourceCPU || name == corev1.ResourceMemory {
+			usage[name] = request
+		}
+	}
+
+	for name, limit := range limits {
+		usage[corev1.ResourceName("limits."+string(name))] = limit
+	}
+
+	return usage
+}
+
+// quotaShortfall returns how much of each resource in usage is missing from
+// what's left in a ResourceQuota.
+func quotaShortfall(quota *corev1.ResourceQuota, usage corev1.ResourceList) corev1.ResourceList {
+	hard := quota.Status.Hard
+	if len(hard) == 0 {
+		hard = quota.Spec.Hard
+	}
+
+	shortfall := corev1.ResourceList{}
+	for name, limit := range hard {
+		needed, ok := usage[name]
+		if !ok {
+			continue
+		}
+
+		available := limit.DeepCopy()
+		if used, ok := quota.Status.Used[name]; ok {
+			available.Sub(used)
+		}
+
+		if needed.Cmp(available) > 0 {
+			missing := needed.DeepCopy()
+			missing.Sub(available)
+			shortfall[name] = missing
+		}
+	}
+
+	return shortfall
+}
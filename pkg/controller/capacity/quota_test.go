@@ -0,0 +1,212 @@
+package capacity
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	"github.com/bookingcom/shipper/pkg/util/resources"
+)
+
+func TestPodResourceUsage(t *testing.T) {
+	spec := &corev1.PodSpec{
+		InitContainers: []corev1.Container{
+			buildContainer("init", "2", "", "128Mi", ""),
+		},
+		Containers: []corev1.Container{
+			buildContainer("app", "500m", "1", "256Mi", "512Mi"),
+			buildContainer("sidecar", "", "100m", "", ""),
+		},
+	}
+
+	expected := corev1.ResourceList{
+		corev1.ResourcePods:           resource.MustParse("3"),
+		corev1.ResourceCPU:            resource.MustParse("6"),
+		corev1.ResourceMemory:         resource.MustParse("768Mi"),
+		corev1.ResourceRequestsCPU:    resource.MustParse("6"),
+		corev1.ResourceRequestsMemory: resource.MustParse("768Mi"),
+		corev1.ResourceLimitsCPU:      resource.MustParse("3300m"),
+		corev1.ResourceLimitsMemory:   resource.MustParse("1536Mi"),
+	}
+
+	usage := podResourceUsage(spec, 3)
+	if len(usage) != len(expected) {
+		t.Fatalf("expected usage of %s, got %s",
+			resources.String(expected), resources.String(usage))
+	}
+
+	for name, quantity := range expected {
+		if actual, ok := usage[name]; !ok || actual.Cmp(quantity) != 0 {
+			t.Errorf("expected usage of %s to be %s, got %s",
+				name, quantity.String(), actual.String())
+		}
+	}
+}
+
+func TestQuotaShortfall(t *testing.T) {
+	quota := buildResourceQuota("compute", corev1.ResourceList{
+		corev1.ResourceRequestsCPU:    resource.MustParse("4"),
+		corev1.ResourceRequestsMemory: resource.MustParse("2Gi"),
+		corev1.ResourcePods:           resource.MustParse("10"),
+	}, corev1.ResourceList{
+		corev1.ResourceRequestsCPU:    resource.MustParse("1"),
+		corev1.ResourceRequestsMemory: resource.MustParse("512Mi"),
+		corev1.ResourcePods:           resource.MustParse("2"),
+	})
+
+	var tests = []struct {
+		title             string
+		usage             corev1.ResourceList
+		expectedShortfall string
+	}{
+		{
+			"usage fits",
+			corev1.ResourceList{
+				corev1.ResourceRequestsCPU:    resource.MustParse("3"),
+				corev1.ResourceRequestsMemory: resource.MustParse("1536Mi"),
+				corev1.ResourcePods:           resource.MustParse("8"),
+			},
+			"",
+		},
+		{
+			"usage goes over",
+			corev1.ResourceList{
+				corev1.ResourceRequestsCPU:    resource.MustParse("4"),
+				corev1.ResourceRequestsMemory: resource.MustParse("2Gi"),
+				corev1.ResourcePods:           resource.MustParse("8"),
+			},
+			"requests.cpu=1,requests.memory=512Mi",
+		},
+		{
+			"resources not in the quota are ignored",
+			corev1.ResourceList{
+				corev1.ResourceLimitsCPU: resource.MustParse("100"),
+			},
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		shortfall := resources.String(quotaShortfall(quota, test.usage))
+		if shortfall != test.expectedShortfall {
+			t.Errorf("testing %s: expected shortfall %q, got %q",
+				test.title, test.expectedShortfall, shortfall)
+		}
+	}
+}
+
+// TestResourceQuotaExceeded verifies that the capacity controller doesn't
+// scale up a deployment whose new pods wouldn't fit in the resource quota of
+// its namespace, and reports what's missing instead.
+func TestResourceQuotaExceeded(t *testing.T) {
+	totalReplicaCount := int32(10)
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, []shipper.ClusterCapacityTarget{
+		{
+			Name:              clusterA,
+			Percent:           100,
+			TotalReplicaCount: totalReplicaCount,
+		},
+	})
+
+	deployment := buildDeployment(shippertesting.TestApp, ctName, 2, 2)
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{
+		buildContainer("app", "500m", "", "256Mi", ""),
+	}
+
+	quota := buildResourceQuota("compute", corev1.ResourceList{
+		corev1.ResourceCPU:            resource.MustParse("4"),
+		corev1.ResourceRequestsMemory: resource.MustParse("2Gi"),
+	}, corev1.ResourceList{
+		corev1.ResourceCPU:            resource.MustParse("1"),
+		corev1.ResourceRequestsMemory: resource.MustParse("512Mi"),
+	})
+
+	f := shippertesting.NewControllerTestFixture()
+	f.AddNamedCluster(clusterA).AddMany([]runtime.Object{deployment, quota})
+	f.ShipperClient.Tracker().Add(ct)
+
+	runController(f)
+
+	ctGVR := shipper.SchemeGroupVersion.WithResource("capacitytargets")
+	object, err := f.ShipperClient.Tracker().Get(ctGVR, ct.Namespace, ct.Name)
+	if err != nil {
+		t.Fatalf("could not Get CapacityTarget: %s", err)
+	}
+
+	status := object.(*shipper.CapacityTarget).Status
+	if len(status.Clusters) != 1 {
+		t.Fatalf("expected status for 1 cluster, got %d", len(status.Clusters))
+	}
+
+	expectedCond := shipper.ClusterCapacityCondition{
+		Type:    shipper.ClusterConditionTypeReady,
+		Status:  corev1.ConditionFalse,
+		Reason:  QuotaExceeded,
+		Message: `cannot add 8 pods to Deployment "foobar": resource quota compute is short of cpu=1,requests.memory=512Mi`,
+	}
+
+	var readyCond *shipper.ClusterCapacityCondition
+	for i, cond := range status.Clusters[0].Conditions {
+		if cond.Type == shipper.ClusterConditionTypeReady {
+			readyCond = &status.Clusters[0].Conditions[i]
+		}
+	}
+
+	if readyCond == nil {
+		t.Fatalf("expected cluster to have a Ready condition")
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(expectedCond, *readyCond)
+	if !eq {
+		t.Errorf("Ready condition different from expected:\n%s", diff)
+	}
+
+	assertDeploymentReplicas(t, ct, f.Clusters[clusterA], 2)
+}
+
+func buildContainer(name, cpuRequest, cpuLimit, memoryRequest, memoryLimit string) corev1.Container {
+	requests := corev1.ResourceList{}
+	limits := corev1.ResourceList{}
+
+	if cpuRequest != "" {
+		requests[corev1.ResourceCPU] = resource.MustParse(cpuRequest)
+	}
+	if cpuLimit != "" {
+		limits[corev1.ResourceCPU] = resource.MustParse(cpuLimit)
+	}
+	if memoryRequest != "" {
+		requests[corev1.ResourceMemory] = resource.MustParse(memoryRequest)
+	}
+	if memoryLimit != "" {
+		limits[corev1.ResourceMemory] = resource.MustParse(memoryLimit)
+	}
+
+	return corev1.Container{
+		Name: name,
+		Resources: corev1.ResourceRequirements{
+			Requests: requests,
+			Limits:   limits,
+		},
+	}
+}
+
+func buildResourceQuota(name string, hard, used corev1.ResourceList) *corev1.ResourceQuota {
+	return &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: shippertesting.TestNamespace,
+		},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: hard,
+		},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: hard,
+			Used: used,
+		},
+	}
+}
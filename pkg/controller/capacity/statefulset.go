@@ -0,0 +1,75 @@
+package capacity
+
+import (
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// orderedReadyReplicas returns how many replicas of a StatefulSet can be
+// considered available. Pods are only counted if their ordinal is within
+// the replicas of the StatefulSet, as pods beyond that are about to be
+// removed. When pods are managed in order, a pod only counts if all the
+// pods before it are ready too, since the StatefulSet controller won't make
+// progress past the first pod that isn't.
+func orderedReadyReplicas(statefulSet *appsv1.StatefulSet, pods []*corev1.Pod) int32 {
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+
+	ready := make([]bool, replicas)
+	for _, pod := range pods {
+		ordinal, ok := statefulSetPodOrdinal(statefulSet, pod)
+		if !ok || ordinal >= replicas {
+			continue
+		}
+
+		ready[ordinal] = isPodReady(pod)
+	}
+
+	ordered := statefulSet.Spec.PodManagementPolicy != appsv1.ParallelPodManagement
+
+	var readyReplicas int32
+	for _, r := range ready {
+		if r {
+			readyReplicas++
+		} else if ordered {
+			break
+		}
+	}
+
+	return readyReplicas
+}
+
+// statefulSetPodOrdinal returns the ordinal of a pod of a StatefulSet, as
+// found in the suffix of its name.
+func statefulSetPodOrdinal(statefulSet *appsv1.StatefulSet, pod *corev1.Pod) (int32, bool) {
+	prefix := statefulSet.Name + "-"
+	if !strings.HasPrefix(pod.Name, prefix) {
+		return 0, false
+	}
+
+	ordinal, err := strconv.ParseInt(strings.TrimPrefix(pod.Name, prefix), 10, 32)
+	if err != nil || ordinal < 0 {
+		return 0, false
+	}
+
+	return int32(ordinal), true
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,168 @@
+package capacity
+
+import (
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func buildStatefulSet(app, release string, replicas int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      release,
+			Namespace: shippertesting.TestNamespace,
+			Labels: map[string]string{
+				shipper.AppLabel:     app,
+				shipper.ReleaseLabel: release,
+			},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					shipper.AppLabel:     app,
+					shipper.ReleaseLabel: release,
+				},
+			},
+		},
+	}
+}
+
+func buildStatefulSetPod(statefulSet *appsv1.StatefulSet, ordinal int, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: statefulSet.Namespace,
+			Name:      fmt.Sprintf("%s-%d", statefulSet.Name, ordinal),
+			Labels:    statefulSet.Spec.Selector.MatchLabels,
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: status},
+			},
+		},
+	}
+}
+
+func TestOrderedReadyReplicas(t *testing.T) {
+	var tests = []struct {
+		title    string
+		policy   appsv1.PodManagementPolicyType
+		replicas int32
+		ready    []bool
+		expected int32
+	}{
+		{
+			"all pods ready",
+			appsv1.OrderedReadyPodManagement,
+			3,
+			[]bool{true, true, true},
+			3,
+		},
+		{
+			"pods after a pod that isn't ready don't count",
+			appsv1.OrderedReadyPodManagement,
+			3,
+			[]bool{true, false, true},
+			1,
+		},
+		{
+			"pods in parallel count regardless of order",
+			appsv1.ParallelPodManagement,
+			3,
+			[]bool{true, false, true},
+			2,
+		},
+		{
+			"pods beyond the replicas don't count",
+			appsv1.OrderedReadyPodManagement,
+			2,
+			[]bool{true, true, true},
+			2,
+		},
+	}
+
+	for _, test := range tests {
+		statefulSet := buildStatefulSet(shippertesting.TestApp, ctName, test.replicas)
+		statefulSet.Spec.PodManagementPolicy = test.policy
+
+		pods := make([]*corev1.Pod, 0, len(test.ready))
+		for i, ready := range test.ready {
+			pods = append(pods, buildStatefulSetPod(statefulSet, i, ready))
+		}
+
+		if got := orderedReadyReplicas(statefulSet, pods); got != test.expected {
+			t.Errorf("testing %s: expected %d ready replicas, got %d", test.title, test.expected, got)
+		}
+	}
+}
+
+// TestStatefulSet verifies that the capacity controller scales StatefulSets
+// the same way it does Deployments.
+func TestStatefulSet(t *testing.T) {
+	totalReplicaCount := int32(4)
+	expectedReplicaCount := int32(2)
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, []shipper.ClusterCapacityTarget{
+		{
+			Name:              clusterA,
+			Percent:           50,
+			TotalReplicaCount: totalReplicaCount,
+		},
+	})
+
+	statefulSet := buildStatefulSet(shippertesting.TestApp, ctName, 0)
+	objects := []runtime.Object{
+		statefulSet,
+		buildStatefulSetPod(statefulSet, 0, true),
+		buildStatefulSetPod(statefulSet, 1, true),
+	}
+
+	f := shippertesting.NewControllerTestFixture()
+	f.AddNamedCluster(clusterA).AddMany(objects)
+	f.ShipperClient.Tracker().Add(ct)
+
+	runController(f)
+
+	ctGVR := shipper.SchemeGroupVersion.WithResource("capacitytargets")
+	object, err := f.ShipperClient.Tracker().Get(ctGVR, ct.Namespace, ct.Name)
+	if err != nil {
+		t.Fatalf("could not Get CapacityTarget: %s", err)
+	}
+
+	status := object.(*shipper.CapacityTarget).Status
+	if len(status.Clusters) != 1 {
+		t.Fatalf("expected status for 1 cluster, got %d", len(status.Clusters))
+	}
+
+	clusterStatus := status.Clusters[0]
+	if clusterStatus.AvailableReplicas != expectedReplicaCount {
+		t.Errorf("expected %d available replicas, got %d", expectedReplicaCount, clusterStatus.AvailableReplicas)
+	}
+
+	for _, cond := range clusterStatus.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			t.Errorf("expected cluster condition %s to be true, got %#v", cond.Type, cond)
+		}
+	}
+
+	statefulSetGVR := appsv1.SchemeGroupVersion.WithResource("statefulsets")
+	object, err = f.Clusters[clusterA].Client.Tracker().Get(statefulSetGVR, statefulSet.Namespace, statefulSet.Name)
+	if err != nil {
+		t.Fatalf("could not Get StatefulSet: %s", err)
+	}
+
+	if replicas := *object.(*appsv1.StatefulSet).Spec.Replicas; replicas != expectedReplicaCount {
+		t.Errorf("expected StatefulSet to have %d replicas, got %d", expectedReplicaCount, replicas)
+	}
+}
@@ -0,0 +1,150 @@
+package capacity
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+type kubeobj interface {
+	metav1.Object
+	runtime.Object
+	GroupVersionKind() schema.GroupVersionKind
+}
+
+// workload is the object running the pods of a release in an application
+// cluster, either a Deployment or a StatefulSet.
+type workload struct {
+	kubeobj
+
+	kind               string
+	replicas           *int32
+	availableReplicas  int32
+	observedGeneration int64
+	selector           *metav1.LabelSelector
+	template           *corev1.PodTemplateSpec
+}
+
+func newDeploymentWorkload(deployment *appsv1.Deployment) *workload {
+	return &workload{
+		kubeobj:            deployment,
+		kind:               "Deployment",
+		replicas:           deployment.Spec.Replicas,
+		availableReplicas:  deployment.Status.AvailableReplicas,
+		observedGeneration: deployment.Status.ObservedGeneration,
+		selector:           deployment.Spec.Selector,
+		template:           &deployment.Spec.Template,
+	}
+}
+
+// newStatefulSetWorkload returns the workload of a StatefulSet. Its available
+// replicas are only known once its pods are, see orderedReadyReplicas.
+func newStatefulSetWorkload(statefulSet *appsv1.StatefulSet) *workload {
+	return &workload{
+		kubeobj:            statefulSet,
+		kind:               "StatefulSet",
+		replicas:           statefulSet.Spec.Replicas,
+		availableReplicas:  statefulSet.Status.ReadyReplicas,
+		observedGeneration: statefulSet.Status.ObservedGeneration,
+		selector:           statefulSet.Spec.Selector,
+		template:           &statefulSet.Spec.Template,
+	}
+}
+
+// getClusterObjects returns the workload of a release in a cluster, and the
+// pods it runs. A release is expected to have exactly one Deployment or
+// StatefulSet.
+func (c Controller) getClusterObjects(cluster, ns, appName, release string) (*workload, []*corev1.Pod, error) {
+	informerFactory, err := c.clusterClientStore.GetInformerFactory(cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	workloadSelector := labels.Set{
+		shipper.AppLabel:     appName,
+		shipper.ReleaseLabel: release,
+	}.AsSelector()
+
+	deploymentGVK := appsv1.SchemeGroupVersion.WithKind("Deployment")
+	deployments, err := informerFactory.Apps().V1().Deployments().
+		Lister().Deployments(ns).List(workloadSelector)
+	if err != nil {
+		return nil, nil, shippererrors.NewKubeclientListError(
+			deploymentGVK, ns, workloadSelector, err)
+	}
+
+	statefulSetGVK := appsv1.SchemeGroupVersion.WithKind("StatefulSet")
+	statefulSets, err := informerFactory.Apps().V1().StatefulSets().
+		Lister().StatefulSets(ns).List(workloadSelector)
+	if err != nil {
+		return nil, nil, shippererrors.NewKubeclientListError(
+			statefulSetGVK, ns, workloadSelector, err)
+	}
+
+	var w *workload
+	if len(deployments) == 1 && len(statefulSets) == 0 {
+		w = newDeploymentWorkload(deployments[0])
+	} else if len(statefulSets) == 1 && len(deployments) == 0 {
+		w = newStatefulSetWorkload(statefulSets[0])
+	} else if len(statefulSets) > 0 {
+		return nil, nil, shippererrors.NewUnexpectedObjectCountFromSelectorError(
+			workloadSelector, statefulSetGVK, 1, len(deployments)+len(statefulSets))
+	} else {
+		return nil, nil, shippererrors.NewUnexpectedObjectCountFromSelectorError(
+			workloadSelector, deploymentGVK, 1, len(deployments))
+	}
+
+	podSelector, err := metav1.LabelSelectorAsSelector(w.selector)
+	if err != nil {
+		return nil, nil, shippererrors.NewUnrecoverableError(fmt.Errorf("failed to transform label selector %v into a selector: %s", w.selector, err))
+	}
+
+	pods, err := informerFactory.Core().V1().Pods().Lister().
+		Pods(w.GetNamespace()).List(podSelector)
+	if err != nil {
+		return nil, nil, shippererrors.NewKubeclientListError(
+			corev1.SchemeGroupVersion.WithKind("Pod"),
+			w.GetNamespace(), podSelector, err)
+	}
+
+	if statefulSet, ok := w.kubeobj.(*appsv1.StatefulSet); ok {
+		w.availableReplicas = orderedReadyReplicas(statefulSet, pods)
+	}
+
+	return w, pods, nil
+}
+
+func (c *Controller) patchWorkloadWithReplicaCount(w *workload, clusterName string, replicaCount int32) error {
+	targetClusterClient, err := c.clusterClientStore.GetClient(clusterName, AgentName)
+	if err != nil {
+		return err
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec": {"replicas": %d}}`, replicaCount))
+
+	switch w.kind {
+	case "StatefulSet":
+		_, err = targetClusterClient.AppsV1().
+			StatefulSets(w.GetNamespace()).
+			Patch(w.GetName(), types.StrategicMergePatchType, patch)
+	default:
+		_, err = targetClusterClient.AppsV1().
+			Deployments(w.GetNamespace()).
+			Patch(w.GetName(), types.StrategicMergePatchType, patch)
+	}
+
+	if err != nil {
+		return shippererrors.NewKubeclientUpdateError(w.kubeobj, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,369 @@
+package cluster
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperclient "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
+	informers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	listers "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/clusterclientstore"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/util/resources"
+	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
+)
+
+const (
+	AgentName = "cluster-controller"
+
+	ClusterConditionChanged = "ClusterConditionChanged"
+
+	Unreachable        = "Unreachable"
+	NotInStore         = "NotInStore"
+	InformersNotSynced = "InformersNotSynced"
+)
+
+// Controller is the controller implementation for Cluster resources. It
+// periodically probes every application cluster through the cluster client
+// store, and reflects their health in their status, so that the release
+// scheduler can steer clear of clusters that are not in service.
+type Controller struct {
+	shipperclientset   shipperclient.Interface
+	clusterClientStore clusterclientstore.Interface
+	clustersLister     listers.ClusterLister
+	clustersSynced     cache.InformerSynced
+	workqueue          workqueue.RateLimitingInterface
+	recorder           record.EventRecorder
+
+	probeInterval time.Duration
+}
+
+// NewController returns a new Cluster controller.
+func NewController(
+	shipperclientset shipperclient.Interface,
+	shipperInformerFactory informers.SharedInformerFactory,
+	store clusterclientstore.Interface,
+	recorder record.EventRecorder,
+	probeInterval time.Duration,
+) *Controller {
+	clusterInformer := shipperInformerFactory.Shipper().V1alpha1().Clusters()
+
+	controller := &Controller{
+		shipperclientset:   shipperclientset,
+		clusterClientStore: store,
+
+		clustersLister: clusterInformer.Lister(),
+		clustersSynced: clusterInformer.Informer().HasSynced,
+		workqueue:      workqueue.NewNamedRateLimitingQueue(shipperworkqueue.NewDefaultControllerRateLimiter(), "cluster_controller_clusters"),
+		recorder:       recorder,
+
+		probeInterval: probeInterval,
+	}
+
+	store.AddSubscriptionCallback(controller.subscribeToNodesAndPods)
+
+	klog.Info("Setting up event handlers")
+	clusterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueCluster,
+		UpdateFunc: func(old, new interface{}) {
+			oldCluster, oldOk := old.(*shipper.Cluster)
+			newCluster, newOk := new.(*shipper.Cluster)
+
+			// Status updates are our own doing, and clusters
+			// get probed periodically anyway.
+			if oldOk && newOk && reflect.DeepEqual(oldCluster.Spec, newCluster.Spec) {
+				return
+			}
+
+			controller.enqueueCluster(new)
+		},
+	})
+
+	return controller
+}
+
+// Run will set up the event handlers for types we are interested in, as well as
+// syncing informer caches and starting workers. It will block until stopCh is
+// closed, at which point it will shutdown the workqueue and wait for workers to
+// finish processing their current work items.
+func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	klog.V(2).Info("Starting Cluster controller")
+	defer klog.V(2).Info("Shutting down Cluster controller")
+
+	if ok := cache.WaitForCacheSync(stopCh, c.clustersSynced); !ok {
+		runtime.HandleError(fmt.Errorf("failed to wait for caches to sync"))
+		return
+	}
+
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+
+	klog.V(4).Info("Started Cluster controller")
+
+	<-stopCh
+}
+
+func (c *Controller) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+
+	defer c.workqueue.Done(obj)
+
+	var (
+		key string
+		ok  bool
+	)
+
+	if key, ok = obj.(string); !ok {
+		c.workqueue.Forget(obj)
+		runtime.HandleError(fmt.Errorf("invalid object key (will retry: false): %#v", obj))
+		return true
+	}
+
+	shouldRetry := false
+	err := c.syncHandler(key)
+
+	if err != nil {
+		shouldRetry = shippererrors.ShouldRetry(err)
+		runtime.HandleError(fmt.Errorf("error syncing Cluster %q (will retry: %t): %s", key, shouldRetry, err.Error()))
+	}
+
+	if shouldRetry {
+		c.workqueue.AddRateLimited(key)
+
+		return true
+	}
+
+	c.workqueue.Forget(obj)
+	klog.V(4).Infof("Successfully synced Cluster %q", key)
+
+	return true
+}
+
+func (c *Controller) syncHandler(key string) error {
+	initialCluster, err := c.clustersLister.Get(key)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			klog.V(3).Infof("Cluster %q has been deleted", key)
+			forgetClusterMetrics(key)
+			return nil
+		}
+
+		return shippererrors.NewKubeclientGetError("", key, err).
+			WithShipperKind("Cluster")
+	}
+
+	// Clusters are probed again after a while no matter what happens
+	// here. Failing to update the status is retried sooner than that.
+	c.workqueue.AddAfter(key, c.probeInterval)
+
+	cluster := initialCluster.DeepCopy()
+	c.probeCluster(cluster)
+
+	if equality.Semantic.DeepEqual(initialCluster.Status, cluster.Status) {
+		return nil
+	}
+
+	_, err = c.shipperclientset.ShipperV1alpha1().Clusters().UpdateStatus(cluster)
+	if err != nil {
+		return shippererrors.NewKubeclientUpdateError(cluster, err).
+			WithShipperKind("Cluster")
+	}
+
+	return nil
+}
+
+// probeCluster checks whether a cluster's API is reachable and whether the
+// informers for it have synced, and reflects that in its status.
+func (c *Controller) probeCluster(cluster *shipper.Cluster) {
+	name := cluster.Name
+
+	informersSynced := newClusterCondition(shipper.ClusterConditionTypeInformersSynced, corev1.ConditionTrue, "", "")
+	if informerFactory, err := c.clusterClientStore.GetInformerFactory(name); err != nil {
+		reason := InformersNotSynced
+		if shippererrors.IsClusterNotInStoreError(err) {
+			reason = NotInStore
+		}
+		informersSynced = newClusterCondition(shipper.ClusterConditionTypeInformersSynced, corev1.ConditionFalse, reason, err.Error())
+	} else if capacity, err := computeClusterCapacity(informerFactory); err != nil {
+		runtime.HandleError(fmt.Errorf("failed to compute capacity of Cluster %q: %s", name, err))
+	} else {
+		cluster.Status.Capacity = capacity
+	}
+
+	reachable := newClusterCondition(shipper.ClusterConditionTypeReachable, corev1.ConditionTrue, "", "")
+	client, err := c.clientForProbe(name)
+	if err == nil {
+		err = c.probeServerVersion(cluster, client)
+	}
+	if err != nil {
+		reachable = newClusterCondition(shipper.ClusterConditionTypeReachable, corev1.ConditionFalse, Unreachable, err.Error())
+	}
+
+	c.setClusterCondition(cluster, *informersSynced)
+	c.setClusterCondition(cluster, *reachable)
+
+	cluster.Status.InService = reachable.Status == corev1.ConditionTrue &&
+		informersSynced.Status == corev1.ConditionTrue
+
+	reportClusterMetrics(cluster)
+}
+
+// probeServerVersion asks a cluster for its version, keeping track of how long
+// it takes to answer.
+func (c *Controller) probeServerVersion(cluster *shipper.Cluster, client kubernetes.Interface) error {
+	start := time.Now()
+	version, err := client.Discovery().ServerVersion()
+	probeLatency.WithLabelValues(cluster.Name).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return err
+	}
+
+	cluster.Status.ServerVersion = version.String()
+
+	return nil
+}
+
+// clientForProbe returns a client to probe a cluster with. Clusters whose
+// informers haven't synced yet don't hand out clients, but it's still worth
+// knowing whether their API is reachable at all.
+func (c *Controller) clientForProbe(name string) (kubernetes.Interface, error) {
+	client, err := c.clusterClientStore.GetClient(name, AgentName)
+	if err == nil {
+		return client, nil
+	}
+
+	config, configErr := c.clusterClientStore.GetConfig(name)
+	if config == nil {
+		if configErr == nil {
+			configErr = err
+		}
+		return nil, configErr
+	}
+
+	return kubernetes.NewForConfig(config)
+}
+
+func newClusterCondition(condType shipper.ClusterConditionType, status corev1.ConditionStatus, reason, message string) *shipper.ClusterCondition {
+	return &shipper.ClusterCondition{
+		Type:               condType,
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// setClusterCondition sets a condition in the status of a cluster, only
+// touching its transition time if its status changes, and emits an event if
+// anything about it changes.
+func (c *Controller) setClusterCondition(cluster *shipper.Cluster, condition shipper.ClusterCondition) {
+	conditions := cluster.Status.Conditions
+	for i, existing := range conditions {
+		if existing.Type != condition.Type {
+			continue
+		}
+
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+
+		if existing.Status != condition.Status || existing.Reason != condition.Reason {
+			c.recorder.Eventf(cluster, corev1.EventTypeNormal, ClusterConditionChanged,
+				"%s: %s -> %s %s", condition.Type, existing.Status, condition.Status, condition.Reason)
+		}
+
+		conditions[i] = condition
+		return
+	}
+
+	c.recorder.Eventf(cluster, corev1.EventTypeNormal, ClusterConditionChanged,
+		"%s: %s %s", condition.Type, condition.Status, condition.Reason)
+	cluster.Status.Conditions = append(conditions, condition)
+}
+
+func (c *Controller) subscribeToNodesAndPods(informerFactory kubeinformers.SharedInformerFactory) {
+	informerFactory.Core().V1().Nodes().Informer()
+	informerFactory.Core().V1().Pods().Informer()
+}
+
+// computeClusterCapacity sums up the allocatable resources of the nodes in a
+// cluster that accept new pods, and the resources requested by the pods
+// running on them.
+func computeClusterCapacity(informerFactory kubeinformers.SharedInformerFactory) (*shipper.ClusterCapacity, error) {
+	nodes, err := informerFactory.Core().V1().Nodes().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := informerFactory.Core().V1().Pods().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	capacity := &shipper.ClusterCapacity{
+		Allocatable: corev1.ResourceList{},
+		Requested:   corev1.ResourceList{},
+	}
+
+	schedulable := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			continue
+		}
+
+		schedulable[node.Name] = true
+		resources.Add(capacity.Allocatable, resources.Scheduling(node.Status.Allocatable))
+	}
+
+	for _, pod := range pods {
+		if !schedulable[pod.Spec.NodeName] {
+			continue
+		}
+
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		resources.Add(capacity.Requested, resources.Scheduling(resources.PodRequests(pod.Spec)))
+	}
+
+	return capacity, nil
+}
+
+func (c *Controller) enqueueCluster(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	c.workqueue.Add(key)
+}
@@ -0,0 +1,218 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	"github.com/bookingcom/shipper/pkg/util/resources"
+)
+
+const testProbePeriod = time.Minute
+
+func buildCluster(name string) *shipper.Cluster {
+	return &shipper.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: shipper.ClusterSpec{
+			Region: shippertesting.TestRegion,
+		},
+	}
+}
+
+func runController(f *shippertesting.ControllerTestFixture) *Controller {
+	c := NewController(
+		f.ShipperClient,
+		f.ShipperInformerFactory,
+		f.ClusterClientStore,
+		f.Recorder,
+		testProbePeriod,
+	)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	f.Run(stopCh)
+
+	return c
+}
+
+func getCondition(cluster *shipper.Cluster, condType shipper.ClusterConditionType) *shipper.ClusterCondition {
+	for _, c := range cluster.Status.Conditions {
+		if c.Type == condType {
+			return &c
+		}
+	}
+	return nil
+}
+
+func TestProbeHealthyCluster(t *testing.T) {
+	cluster := buildCluster("cluster-a")
+	f := shippertesting.NewControllerTestFixture(cluster)
+	f.AddNamedCluster(cluster.Name)
+	c := runController(f)
+
+	if err := c.syncHandler(cluster.Name); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := f.ShipperClient.ShipperV1alpha1().Clusters().Get(cluster.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Status.InService {
+		t.Errorf("expected cluster to be in service, got status %#v", got.Status)
+	}
+
+	if got.Status.ServerVersion == "" {
+		t.Errorf("expected cluster to report its server version")
+	}
+
+	for _, condType := range []shipper.ClusterConditionType{
+		shipper.ClusterConditionTypeReachable,
+		shipper.ClusterConditionTypeInformersSynced,
+	} {
+		cond := getCondition(got, condType)
+		if cond == nil || cond.Status != corev1.ConditionTrue {
+			t.Errorf("expected condition %s to be true, got %#v", condType, cond)
+		}
+	}
+}
+
+func TestProbeClusterNotInStore(t *testing.T) {
+	cluster := buildCluster("cluster-a")
+	f := shippertesting.NewControllerTestFixture(cluster)
+	c := runController(f)
+
+	if err := c.syncHandler(cluster.Name); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := f.ShipperClient.ShipperV1alpha1().Clusters().Get(cluster.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Status.InService {
+		t.Errorf("expected cluster not to be in service")
+	}
+
+	cond := getCondition(got, shipper.ClusterConditionTypeInformersSynced)
+	if cond == nil || cond.Status != corev1.ConditionFalse || cond.Reason != NotInStore {
+		t.Errorf("expected condition %s to be false with reason %s, got %#v",
+			shipper.ClusterConditionTypeInformersSynced, NotInStore, cond)
+	}
+
+	cond = getCondition(got, shipper.ClusterConditionTypeReachable)
+	if cond == nil || cond.Status != corev1.ConditionFalse {
+		t.Errorf("expected condition %s to be false, got %#v",
+			shipper.ClusterConditionTypeReachable, cond)
+	}
+}
+
+// TestProbeKeepsTransitionTime checks that probing a cluster whose health did
+// not change keeps the transition time of its conditions.
+func TestProbeKeepsTransitionTime(t *testing.T) {
+	transitionTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+
+	cluster := buildCluster("cluster-a")
+	cluster.Status = shipper.ClusterStatus{
+		InService:     true,
+		ServerVersion: "v1.15.0",
+	}
+	for _, condType := range []shipper.ClusterConditionType{
+		shipper.ClusterConditionTypeInformersSynced,
+		shipper.ClusterConditionTypeReachable,
+	} {
+		cluster.Status.Conditions = append(cluster.Status.Conditions, shipper.ClusterCondition{
+			Type:               condType,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: transitionTime,
+		})
+	}
+
+	f := shippertesting.NewControllerTestFixture(cluster)
+	f.AddNamedCluster(cluster.Name)
+	c := runController(f)
+
+	probed := cluster.DeepCopy()
+	c.probeCluster(probed)
+
+	for _, cond := range probed.Status.Conditions {
+		if !cond.LastTransitionTime.Equal(&transitionTime) {
+			t.Errorf("expected condition %s to keep its transition time %s, got %s",
+				cond.Type, transitionTime, cond.LastTransitionTime)
+		}
+	}
+}
+
+func TestProbeComputesCapacity(t *testing.T) {
+	node := func(name, cpu, memory string, unschedulable bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				},
+			},
+		}
+	}
+	pod := func(name, nodeName, cpu string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: shippertesting.TestNamespace},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU: resource.MustParse(cpu),
+							},
+						},
+					},
+				},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	cluster := buildCluster("cluster-a")
+	f := shippertesting.NewControllerTestFixture(cluster)
+	fakeCluster := f.AddNamedCluster(cluster.Name)
+	fakeCluster.AddMany([]runtime.Object{
+		node("node-a", "4", "8Gi", false),
+		node("node-b", "4", "8Gi", false),
+		node("node-cordoned", "4", "8Gi", true),
+		pod("running", "node-a", "1", corev1.PodRunning),
+		pod("succeeded", "node-a", "1", corev1.PodSucceeded),
+		pod("pending", "", "1", corev1.PodPending),
+		pod("on-cordoned-node", "node-cordoned", "1", corev1.PodRunning),
+	})
+	c := runController(f)
+
+	probed := cluster.DeepCopy()
+	c.probeCluster(probed)
+
+	capacity := probed.Status.Capacity
+	if capacity == nil {
+		t.Fatalf("expected cluster capacity to be computed")
+	}
+
+	if got := resources.String(capacity.Allocatable); got != "cpu=8,memory=16Gi" {
+		t.Errorf("expected allocatable cpu=8,memory=16Gi, got %s", got)
+	}
+
+	if got := resources.String(capacity.Requested); got != "cpu=1" {
+		t.Errorf("expected requested cpu=1, got %s", got)
+	}
+}
@@ -0,0 +1,76 @@
+package cluster
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+var (
+	conditions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "shipper",
+			Subsystem: "cluster_controller",
+			Name:      "condition",
+			Help:      "Whether a condition of a cluster is true (1) or not (0), as last probed",
+		},
+		[]string{"cluster", "condition"},
+	)
+	inService = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "shipper",
+			Subsystem: "cluster_controller",
+			Name:      "in_service",
+			Help:      "Whether a cluster is in service (1) or not (0), as last probed",
+		},
+		[]string{"cluster"},
+	)
+	probeLatency = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Namespace: "shipper",
+			Subsystem: "cluster_controller",
+			Name:      "probe_latency_seconds",
+			Help:      "How long it takes for the API of a cluster to answer a probe",
+		},
+		[]string{"cluster"},
+	)
+)
+
+// GetMetrics returns all the Prometheus variables the cluster controller
+// reports on. Used for registering with an HTTP handler.
+func GetMetrics() []prometheus.Collector {
+	return []prometheus.Collector{
+		conditions,
+		inService,
+		probeLatency,
+	}
+}
+
+func boolToGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func reportClusterMetrics(cluster *shipper.Cluster) {
+	for _, c := range cluster.Status.Conditions {
+		conditions.WithLabelValues(cluster.Name, string(c.Type)).
+			Set(boolToGauge(c.Status == corev1.ConditionTrue))
+	}
+
+	inService.WithLabelValues(cluster.Name).Set(boolToGauge(cluster.Status.InService))
+}
+
+func forgetClusterMetrics(name string) {
+	for _, condType := range []shipper.ClusterConditionType{
+		shipper.ClusterConditionTypeReachable,
+		shipper.ClusterConditionTypeInformersSynced,
+	} {
+		conditions.DeleteLabelValues(name, string(condType))
+	}
+
+	inService.DeleteLabelValues(name)
+	probeLatency.DeleteLabelValues(name)
+}
@@ -5,6 +5,9 @@ import (
 	"reflect"
 	"sort"
 
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -126,6 +129,7 @@ func (i *Installer) install(
 
 	ownerReference := anchor.ConfigMapAnchorToOwnerReference(createdConfigMap)
 	resourceClients := make(map[string]dynamic.ResourceInterface)
+	autoscaledWorkloads := horizontalPodAutoscalerTargets(i.objects)
 
 	for _, preparedObj := range i.objects {
 		obj := &unstructured.Unstructured{}
@@ -229,6 +233,18 @@ func (i *Installer) install(
 			}
 		}
 
+		// Replica counts of autoscaled workloads and the bounds of
+		// their HorizontalPodAutoscalers are managed by the capacity
+		// controller and the autoscaler, so we keep them as they are.
+		if gvk.Kind == "Deployment" || gvk.Kind == "StatefulSet" {
+			if _, ok := autoscaledWorkloads[gvk.Kind+"/"+name]; ok {
+				copyNestedField(existingUnstructuredObj, newUnstructuredObj, "spec", "replicas")
+			}
+		} else if gvk.Kind == "HorizontalPodAutoscaler" {
+			copyNestedField(existingUnstructuredObj, newUnstructuredObj, "spec", "minReplicas")
+			copyNestedField(existingUnstructuredObj, newUnstructuredObj, "spec", "maxReplicas")
+		}
+
 		unstructured.SetNestedField(existingUnstructuredObj, newUnstructuredObj["spec"], "spec")
 		existingObj.SetUnstructuredContent(existingUnstructuredObj)
 
@@ -241,6 +257,37 @@ func (i *Installer) install(
 	return nil
 }
 
+// horizontalPodAutoscalerTargets returns the Deployments and StatefulSets
+// scaled by the HorizontalPodAutoscalers in objects, as "kind/name" keys.
+func horizontalPodAutoscalerTargets(objects []runtime.Object) map[string]struct{} {
+	targets := make(map[string]struct{})
+	for _, obj := range objects {
+		var kind, name string
+		switch hpa := obj.(type) {
+		case *autoscalingv1.HorizontalPodAutoscaler:
+			kind, name = hpa.Spec.ScaleTargetRef.Kind, hpa.Spec.ScaleTargetRef.Name
+		case *autoscalingv2beta1.HorizontalPodAutoscaler:
+			kind, name = hpa.Spec.ScaleTargetRef.Kind, hpa.Spec.ScaleTargetRef.Name
+		case *autoscalingv2beta2.HorizontalPodAutoscaler:
+			kind, name = hpa.Spec.ScaleTargetRef.Kind, hpa.Spec.ScaleTargetRef.Name
+		default:
+			continue
+		}
+
+		targets[kind+"/"+name] = struct{}{}
+	}
+
+	return targets
+}
+
+// copyNestedField copies the field at fields from the existing object to the
+// rendered one, if the existing object has it set.
+func copyNestedField(existing, rendered map[string]interface{}, fields ...string) {
+	if value, ok, err := unstructured.NestedFieldCopy(existing, fields...); ok && err == nil {
+		unstructured.SetNestedField(rendered, value, fields...)
+	}
+}
+
 // shouldUpdateObject detects whether the current iteration of the installer
 // should update an object in the application cluster.
 func shouldUpdateObject(it *shipper.InstallationTarget, obj *unstructured.Unstructured) (bool, error) {
@@ -5,7 +5,9 @@ import (
 	"testing"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -601,3 +603,81 @@ func TestInstallerNoOverride(t *testing.T) {
 	shippertesting.ShallowCheckActions(expectedActions, fakeCluster.Client.Actions(), t)
 	shippertesting.ShallowCheckActions(expectedDynamicActions, fakeCluster.DynamicClient.Actions(), t)
 }
+
+// TestInstallerKeepsAutoscaledReplicas tests that overriding objects from a
+// different installation target doesn't overwrite the replica count of an
+// autoscaled Deployment, nor the bounds of its HorizontalPodAutoscaler.
+func TestInstallerKeepsAutoscaledReplicas(t *testing.T) {
+	cluster := buildCluster("minikube-a")
+	appName := "reviews-api"
+	testNs := "reviews-api"
+
+	chart := buildChart(appName, "0.0.1", repoUrl)
+	it := buildInstallationTarget(testNs, appName, []string{cluster.Name}, &chart)
+	it.Spec.CanOverride = true
+
+	labels := map[string]string{
+		shipper.AppLabel:                     appName,
+		shipper.InstallationTargetOwnerLabel: "some-other-installation-target",
+	}
+
+	buildObjects := func(replicas, minReplicas, maxReplicas int32) []runtime.Object {
+		deployment := buildDeployment()
+		deployment.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}
+		deployment.Namespace = testNs
+		deployment.Spec.Replicas = &replicas
+		deployment.SetLabels(labels)
+
+		hpa := &autoscalingv1.HorizontalPodAutoscaler{
+			TypeMeta: metav1.TypeMeta{APIVersion: "autoscaling/v1", Kind: "HorizontalPodAutoscaler"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      deployment.Name,
+				Namespace: testNs,
+				Labels:    labels,
+			},
+			Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+					Kind: "Deployment",
+					Name: deployment.Name,
+				},
+				MinReplicas: &minReplicas,
+				MaxReplicas: maxReplicas,
+			},
+		}
+
+		return []runtime.Object{deployment, hpa}
+	}
+
+	f := newFixture(objectsPerClusterMap{cluster.Name: buildObjects(7, 2, 5)})
+	fakeCluster := f.Clusters[cluster.Name]
+
+	installer := NewInstaller(it, buildObjects(0, 4, 20))
+	if err := installer.install(cluster, fakeCluster.Client, restConfig, f.DynamicClientBuilder); err != nil {
+		t.Fatal(err)
+	}
+
+	updateActions := filterActions(fakeCluster.DynamicClient.Actions(), "update")
+	if len(updateActions) != 2 {
+		t.Fatalf("expected 2 updates, got %d", len(updateActions))
+	}
+
+	for _, action := range updateActions {
+		obj := action.(kubetesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+
+		var fields []string
+		var expected []int64
+		switch obj.GetKind() {
+		case "Deployment":
+			fields, expected = []string{"replicas"}, []int64{7}
+		case "HorizontalPodAutoscaler":
+			fields, expected = []string{"minReplicas", "maxReplicas"}, []int64{2, 5}
+		}
+
+		for i, field := range fields {
+			got, _, _ := unstructured.NestedInt64(obj.Object, "spec", field)
+			if got != expected[i] {
+				t.Errorf("expected %s to keep spec.%s %d, got %d", obj.GetKind(), field, expected[i], got)
+			}
+		}
+	}
+}
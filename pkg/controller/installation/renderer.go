@@ -2,6 +2,7 @@ package installation
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
@@ -9,6 +10,9 @@ import (
 	shipperrepo "github.com/bookingcom/shipper/pkg/chart/repo"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -68,24 +72,23 @@ func prepareObjects(it *shipper.InstallationTarget, manifests []string) ([]runti
 
 		switch obj := decodedObj.(type) {
 		case *appsv1.Deployment:
-			// We need the Deployment in the chart to have a unique
-			// name, meaning that different installations need to
-			// generate Deployments with different names,
-			// otherwise, we try to overwrite a previous
-			// Deployment, and that fails with a "field is
-			// immutable" error.
-			deploymentName := obj.Name
-			expectedName := it.Name
-			if !strings.Contains(deploymentName, expectedName) {
-				return nil, shippererrors.NewInvalidChartError(
-					fmt.Sprintf("Deployment %q has invalid name."+
-						" The name of the Deployment should be"+
-						" templated with {{.Release.Name}}.",
-						deploymentName),
-				)
+			if err := validateWorkloadName(it, "Deployment", obj.Name); err != nil {
+				return nil, err
 			}
 
 			decodedObj = patchDeployment(obj, shipperLabels)
+		case *appsv1.StatefulSet:
+			if err := validateWorkloadName(it, "StatefulSet", obj.Name); err != nil {
+				return nil, err
+			}
+
+			decodedObj = patchStatefulSet(obj, shipperLabels)
+		case *autoscalingv1.HorizontalPodAutoscaler:
+			annotateHorizontalPodAutoscaler(obj, obj.Spec.MinReplicas, obj.Spec.MaxReplicas)
+		case *autoscalingv2beta1.HorizontalPodAutoscaler:
+			annotateHorizontalPodAutoscaler(obj, obj.Spec.MinReplicas, obj.Spec.MaxReplicas)
+		case *autoscalingv2beta2.HorizontalPodAutoscaler:
+			annotateHorizontalPodAutoscaler(obj, obj.Spec.MinReplicas, obj.Spec.MaxReplicas)
 		case *corev1.Service:
 			allServices = append(allServices, obj)
 
@@ -128,31 +131,91 @@ func prepareObjects(it *shipper.InstallationTarget, manifests []string) ([]runti
 	return preparedObjects, nil
 }
 
+// validateWorkloadName checks that the name of the Deployment or StatefulSet
+// in the chart is unique to the installation target.
+func validateWorkloadName(it *shipper.InstallationTarget, kind, name string) error {
+	// We need the workload in the chart to have a unique name, meaning
+	// that different installations need to generate workloads with
+	// different names, otherwise, we try to overwrite a previous one, and
+	// that fails with a "field is immutable" error.
+	if !strings.Contains(name, it.Name) {
+		return shippererrors.NewInvalidChartError(
+			fmt.Sprintf("%s %q has invalid name."+
+				" The name of the %s should be"+
+				" templated with {{.Release.Name}}.",
+				kind, name, kind),
+		)
+	}
+
+	return nil
+}
+
 func patchDeployment(d *appsv1.Deployment, labelsToInject map[string]string) runtime.Object {
 	replicas := int32(0)
 	d.Spec.Replicas = &replicas
+	d.Spec.Selector = injectSelectorLabels(d.Spec.Selector, labelsToInject)
+	injectPodTemplateLabels(&d.Spec.Template, labelsToInject)
+
+	return d
+}
+
+func patchStatefulSet(s *appsv1.StatefulSet, labelsToInject map[string]string) runtime.Object {
+	replicas := int32(0)
+	s.Spec.Replicas = &replicas
+	s.Spec.Selector = injectSelectorLabels(s.Spec.Selector, labelsToInject)
+	injectPodTemplateLabels(&s.Spec.Template, labelsToInject)
+
+	return s
+}
 
+func injectSelectorLabels(selector *metav1.LabelSelector, labelsToInject map[string]string) *metav1.LabelSelector {
 	var newSelector *metav1.LabelSelector
-	if d.Spec.Selector != nil {
-		newSelector = d.Spec.Selector.DeepCopy()
+	if selector != nil {
+		newSelector = selector.DeepCopy()
 	} else {
-		newSelector = &metav1.LabelSelector{
-			MatchLabels: map[string]string{},
-		}
+		newSelector = &metav1.LabelSelector{}
+	}
+
+	if newSelector.MatchLabels == nil {
+		newSelector.MatchLabels = map[string]string{}
 	}
 
 	for k, v := range labelsToInject {
 		newSelector.MatchLabels[k] = v
 	}
-	d.Spec.Selector = newSelector
 
-	podTemplateLabels := d.Spec.Template.Labels
+	return newSelector
+}
+
+func injectPodTemplateLabels(template *corev1.PodTemplateSpec, labelsToInject map[string]string) {
+	podTemplateLabels := template.Labels
+	if podTemplateLabels == nil {
+		podTemplateLabels = map[string]string{}
+	}
+
 	for k, v := range labelsToInject {
 		podTemplateLabels[k] = v
 	}
-	d.Spec.Template.SetLabels(podTemplateLabels)
+	template.SetLabels(podTemplateLabels)
+}
 
-	return d
+// annotateHorizontalPodAutoscaler records the replica bounds of a
+// HorizontalPodAutoscaler as they are in the chart, since the capacity
+// controller will change the actual bounds as the release gets more or less
+// capacity.
+func annotateHorizontalPodAutoscaler(hpa metav1.Object, minReplicas *int32, maxReplicas int32) {
+	chartMin := int32(1)
+	if minReplicas != nil {
+		chartMin = *minReplicas
+	}
+
+	annotations := hpa.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[shipper.HPAChartMinReplicasAnnotation] = strconv.Itoa(int(chartMin))
+	annotations[shipper.HPAChartMaxReplicasAnnotation] = strconv.Itoa(int(maxReplicas))
+	hpa.SetAnnotations(annotations)
 }
 
 func patchService(it *shipper.InstallationTarget, s *corev1.Service) error {
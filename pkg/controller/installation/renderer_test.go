@@ -0,0 +1,98 @@
+package installation
+
+import (
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+const statefulSetServiceManifest = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: reviews-api
+  labels:
+    app: reviews-api
+spec:
+  selector:
+    app: reviews-api
+`
+
+const statefulSetManifest = `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: %s
+  labels:
+    app: reviews-api
+spec:
+  replicas: 3
+  serviceName: reviews-api
+  selector:
+    matchLabels:
+      app: reviews-api
+  template:
+    metadata:
+      labels:
+        app: reviews-api
+    spec:
+      containers:
+        - name: reviews-api
+          image: "redis:stable"
+`
+
+// TestPrepareObjectsStatefulSet tests that a StatefulSet in a chart is
+// prepared the same way as a Deployment: created with no replicas, and with
+// the labels of the release in its selector and pod template.
+func TestPrepareObjectsStatefulSet(t *testing.T) {
+	chart := buildChart("reviews-api", "0.0.1", repoUrl)
+	it := buildInstallationTarget("reviews-api", "reviews-api", []string{"minikube-a"}, &chart)
+
+	manifests := []string{
+		statefulSetServiceManifest,
+		fmt.Sprintf(statefulSetManifest, "reviews-api-db"),
+	}
+
+	objects, err := prepareObjects(it, manifests)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var statefulSet *appsv1.StatefulSet
+	for _, obj := range objects {
+		if s, ok := obj.(*appsv1.StatefulSet); ok {
+			statefulSet = s
+		}
+	}
+
+	if statefulSet == nil {
+		t.Fatalf("expected a StatefulSet among the prepared objects")
+	}
+
+	if replicas := *statefulSet.Spec.Replicas; replicas != 0 {
+		t.Errorf("expected StatefulSet to have 0 replicas, got %d", replicas)
+	}
+
+	for _, labels := range []map[string]string{
+		statefulSet.Spec.Selector.MatchLabels,
+		statefulSet.Spec.Template.Labels,
+	} {
+		if owner := labels[shipper.InstallationTargetOwnerLabel]; owner != it.Name {
+			t.Errorf("expected label %s=%s, got labels %v", shipper.InstallationTargetOwnerLabel, it.Name, labels)
+		}
+	}
+
+	manifests = []string{
+		statefulSetServiceManifest,
+		fmt.Sprintf(statefulSetManifest, "database"),
+	}
+
+	_, err = prepareObjects(it, manifests)
+	if _, ok := err.(shippererrors.InvalidChartError); !ok {
+		t.Errorf("expected an invalid chart error for a StatefulSet with an invalid name, got %v", err)
+	}
+}
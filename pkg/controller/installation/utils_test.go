@@ -64,6 +64,16 @@ var (
 				},
 			},
 		},
+		{
+			GroupVersion: "autoscaling/v1",
+			APIResources: []metav1.APIResource{
+				{
+					Kind:       "HorizontalPodAutoscaler",
+					Namespaced: true,
+					Name:       "horizontalpodautoscalers",
+				},
+			},
+		},
 	}
 )
 
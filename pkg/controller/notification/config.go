@@ -0,0 +1,131 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config lists the endpoints rollout notifications are delivered to.
+type Config struct {
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// Endpoint is an HTTP endpoint notifications are POSTed to, along with the
+// subscriptions that decide which notifications it receives.
+type Endpoint struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+
+	// Headers are added to every request sent to the endpoint.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Events restricts the endpoint to some types of notifications. All
+	// of them are delivered if empty.
+	Events []EventType `json:"events,omitempty"`
+
+	// Namespaces and Applications restrict the endpoint to releases of
+	// the given namespaces and applications. Releases of all namespaces
+	// and applications are notified about if empty.
+	Namespaces   []string `json:"namespaces,omitempty"`
+	Applications []string `json:"applications,omitempty"`
+
+	// Template is a Go template rendering the JSON payload sent to the
+	// endpoint out of a Notification. The Notification is sent as is if
+	// empty.
+	Template string `json:"template,omitempty"`
+}
+
+// LoadConfig reads a notification configuration from a YAML or JSON file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse notification config %q: %s", path, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid notification config %q: %s", path, err)
+	}
+
+	return config, nil
+}
+
+// Validate checks that every endpoint in the configuration is well formed.
+func (c *Config) Validate() error {
+	names := make(map[string]struct{})
+	for _, e := range c.Endpoints {
+		if e.Name == "" {
+			return fmt.Errorf("endpoint name must not be empty")
+		}
+
+		if _, ok := names[e.Name]; ok {
+			return fmt.Errorf("duplicate endpoint %q", e.Name)
+		}
+		names[e.Name] = struct{}{}
+
+		if u, err := url.Parse(e.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("endpoint %q: invalid url %q", e.Name, e.URL)
+		}
+
+		for _, event := range e.Events {
+			if !isKnownEventType(event) {
+				return fmt.Errorf("endpoint %q: unknown event %q", e.Name, event)
+			}
+		}
+
+		if _, err := parseTemplate(e); err != nil {
+			return fmt.Errorf("endpoint %q: %s", e.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// subscribes returns whether an endpoint should receive a notification.
+func (e *Endpoint) subscribes(n Notification) bool {
+	return (len(e.Events) == 0 || containsEvent(e.Events, n.Event)) &&
+		(len(e.Namespaces) == 0 || containsString(e.Namespaces, n.Namespace)) &&
+		(len(e.Applications) == 0 || containsString(e.Applications, n.Application))
+}
+
+func parseTemplate(e Endpoint) (*template.Template, error) {
+	if e.Template == "" {
+		return nil, nil
+	}
+
+	return template.New(e.Name).Funcs(template.FuncMap{
+		// json renders a value as JSON, so templates don't have to
+		// worry about quoting.
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(e.Template)
+}
+
+func containsEvent(events []EventType, event EventType) bool {
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
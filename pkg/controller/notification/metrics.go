@@ -0,0 +1,35 @@
+package notification
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	deliveries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "shipper",
+			Subsystem: "notification_controller",
+			Name:      "deliveries_total",
+			Help:      "How many notification deliveries were attempted, by endpoint and result",
+		},
+		[]string{"endpoint", "result"},
+	)
+	deliveryDuration = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Namespace: "shipper",
+			Subsystem: "notification_controller",
+			Name:      "delivery_duration_seconds",
+			Help:      "How long it takes to deliver a notification to an endpoint",
+		},
+		[]string{"endpoint"},
+	)
+)
+
+// GetMetrics returns all the Prometheus variables the notification
+// controller reports on. Used for registering with an HTTP handler.
+func GetMetrics() []prometheus.Collector {
+	return []prometheus.Collector{
+		deliveries,
+		deliveryDuration,
+	}
+}
@@ -0,0 +1,118 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+type EventType string
+
+const (
+	EventReleaseStarted   EventType = "started"
+	EventStepAchieved     EventType = "stepAchieved"
+	EventReleaseBlocked   EventType = "blocked"
+	EventReleaseAborted   EventType = "aborted"
+	EventReleaseCompleted EventType = "completed"
+)
+
+var eventTypes = []EventType{
+	EventReleaseStarted,
+	EventStepAchieved,
+	EventReleaseBlocked,
+	EventReleaseAborted,
+	EventReleaseCompleted,
+}
+
+func isKnownEventType(event EventType) bool {
+	return containsEvent(eventTypes, event)
+}
+
+// Notification describes a transition in the lifecycle of a release. It is
+// the payload delivered to endpoints without a template, and the data
+// templates are rendered with.
+type Notification struct {
+	Event       EventType `json:"event"`
+	Namespace   string    `json:"namespace"`
+	Application string    `json:"application"`
+	Release     string    `json:"release"`
+	Step        int32     `json:"step"`
+	StepName    string    `json:"stepName,omitempty"`
+	Message     string    `json:"message,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// releaseTransitions compares two versions of a release and returns a
+// notification for every lifecycle transition that happened in between.
+func releaseTransitions(oldRel, newRel *shipper.Release, now time.Time) []Notification {
+	var notifications []Notification
+
+	notify := func(event EventType, message string) {
+		n := Notification{
+			Event:       event,
+			Namespace:   newRel.Namespace,
+			Application: newRel.Labels[shipper.AppLabel],
+			Release:     newRel.Name,
+			Step:        newRel.Spec.TargetStep,
+			Message:     message,
+			Time:        now,
+		}
+
+		if achieved := newRel.Status.AchievedStep; achieved != nil {
+			n.Step = achieved.Step
+			n.StepName = achieved.Name
+		}
+
+		notifications = append(notifications, n)
+	}
+
+	if became, _ := conditionBecameTrue(oldRel, newRel, shipper.ReleaseConditionTypeScheduled); became {
+		notify(EventReleaseStarted, "")
+	}
+
+	if stepAchieved(oldRel, newRel) {
+		notify(EventStepAchieved, fmt.Sprintf("step %q achieved", newRel.Status.AchievedStep.Name))
+	}
+
+	if became, msg := conditionBecameTrue(oldRel, newRel, shipper.ReleaseConditionTypeBlocked); became {
+		notify(EventReleaseBlocked, msg)
+	}
+
+	if became, msg := conditionBecameTrue(oldRel, newRel, shipper.ReleaseConditionTypeAborted); became {
+		notify(EventReleaseAborted, msg)
+	}
+
+	if became, _ := conditionBecameTrue(oldRel, newRel, shipper.ReleaseConditionTypeComplete); became {
+		notify(EventReleaseCompleted, "")
+	}
+
+	return notifications
+}
+
+func conditionBecameTrue(oldRel, newRel *shipper.Release, condType shipper.ReleaseConditionType) (bool, string) {
+	newCond := releaseutil.GetReleaseCondition(newRel.Status, condType)
+	if newCond == nil || newCond.Status != corev1.ConditionTrue {
+		return false, ""
+	}
+
+	oldCond := releaseutil.GetReleaseCondition(oldRel.Status, condType)
+	if oldCond != nil && oldCond.Status == corev1.ConditionTrue {
+		return false, ""
+	}
+
+	return true, newCond.Message
+}
+
+func stepAchieved(oldRel, newRel *shipper.Release) bool {
+	newStep := newRel.Status.AchievedStep
+	if newStep == nil {
+		return false
+	}
+
+	oldStep := oldRel.Status.AchievedStep
+	return oldStep == nil || oldStep.Step != newStep.Step
+}
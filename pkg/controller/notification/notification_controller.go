@@ -0,0 +1,280 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+const (
+	AgentName = "notification-controller"
+
+	// maxRetries is the number of times a delivery is retried before it
+	// is dropped.
+	maxRetries = 10
+)
+
+// endpoint is a configured Endpoint with its template already parsed.
+type endpoint struct {
+	Endpoint
+	template *template.Template
+}
+
+// delivery is a notification waiting to be sent to an endpoint.
+type delivery struct {
+	endpoint     string
+	notification Notification
+}
+
+// Controller is a Kubernetes controller that watches Release objects and
+// POSTs a notification to the configured endpoints every time a release goes
+// through a lifecycle transition: when it starts rolling out, achieves a
+// step, is blocked or aborted, and when it completes.
+//
+// Notifications are only sent for transitions observed while the controller
+// is running, which with leader election means while this replica is the
+// leader. Deliveries are retried with exponential backoff.
+type Controller struct {
+	endpoints  []*endpoint
+	httpClient *http.Client
+
+	releasesSynced cache.InformerSynced
+
+	workqueue workqueue.RateLimitingInterface
+
+	// running is set while Run is, as informers also run on standby
+	// replicas that have no workers to drain the workqueue. It is only
+	// accessed atomically.
+	running int32
+}
+
+// NewController returns a new notification controller.
+func NewController(
+	informerFactory shipperinformers.SharedInformerFactory,
+	config *Config,
+	httpClient *http.Client,
+) (*Controller, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]*endpoint, 0, len(config.Endpoints))
+	for _, e := range config.Endpoints {
+		// Validate has already made sure the template parses.
+		tpl, _ := parseTemplate(e)
+		endpoints = append(endpoints, &endpoint{Endpoint: e, template: tpl})
+	}
+
+	releaseInformer := informerFactory.Shipper().V1alpha1().Releases()
+
+	klog.Info("Building a notification controller")
+
+	controller := &Controller{
+		endpoints:  endpoints,
+		httpClient: httpClient,
+
+		releasesSynced: releaseInformer.Informer().HasSynced,
+
+		workqueue: workqueue.NewNamedRateLimitingQueue(
+			workqueue.NewItemExponentialFailureRateLimiter(time.Second, 5*time.Minute),
+			"notification_controller_deliveries",
+		),
+	}
+
+	klog.Info("Setting up event handlers")
+
+	// Additions are not notified about, as the informer reports every
+	// existing release as added when it starts up. A new release is
+	// notified about once it gets scheduled.
+	releaseInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			UpdateFunc: controller.onUpdateRelease,
+		})
+
+	return controller, nil
+}
+
+// Run starts notification controller workers and blocks until stopCh is
+// closed.
+func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	klog.V(2).Info("Starting Notification controller")
+	defer klog.V(2).Info("Shutting down Notification controller")
+
+	if ok := cache.WaitForCacheSync(stopCh, c.releasesSynced); !ok {
+		runtime.HandleError(fmt.Errorf("failed to wait for caches to sync"))
+		return
+	}
+
+	atomic.StoreInt32(&c.running, 1)
+	defer atomic.StoreInt32(&c.running, 0)
+
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+
+	klog.V(4).Info("Started Notification controller")
+
+	<-stopCh
+}
+
+func (c *Controller) onUpdateRelease(oldObj, newObj interface{}) {
+	// Transitions observed before Run are dropped: whoever was running
+	// at the time has already notified about them.
+	if atomic.LoadInt32(&c.running) == 0 {
+		return
+	}
+
+	oldRel, ok := oldObj.(*shipper.Release)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a shipper.Release: %#v", oldObj))
+		return
+	}
+
+	newRel, ok := newObj.(*shipper.Release)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a shipper.Release: %#v", newObj))
+		return
+	}
+
+	for _, n := range releaseTransitions(oldRel, newRel, time.Now()) {
+		c.enqueueNotification(n)
+	}
+}
+
+func (c *Controller) enqueueNotification(n Notification) {
+	for _, e := range c.endpoints {
+		if e.subscribes(n) {
+			c.workqueue.Add(delivery{endpoint: e.Name, notification: n})
+		}
+	}
+}
+
+func (c *Controller) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+
+	defer c.workqueue.Done(obj)
+
+	d, ok := obj.(delivery)
+	if !ok {
+		c.workqueue.Forget(obj)
+		runtime.HandleError(fmt.Errorf("invalid delivery (will retry: false): %#v", obj))
+		return true
+	}
+
+	start := time.Now()
+	err := c.deliver(d)
+	deliveryDuration.WithLabelValues(d.endpoint).Observe(time.Since(start).Seconds())
+
+	if err == nil {
+		klog.V(4).Infof("Delivered %q notification for Release %s/%s to %q",
+			d.notification.Event, d.notification.Namespace, d.notification.Release, d.endpoint)
+		deliveries.WithLabelValues(d.endpoint, "success").Inc()
+		c.workqueue.Forget(obj)
+		return true
+	}
+
+	shouldRetry := shippererrors.ShouldRetry(err) && c.workqueue.NumRequeues(obj) < maxRetries
+	runtime.HandleError(fmt.Errorf("error delivering %q notification for Release %s/%s (will retry: %t): %s",
+		d.notification.Event, d.notification.Namespace, d.notification.Release, shouldRetry, err))
+
+	if shouldRetry {
+		deliveries.WithLabelValues(d.endpoint, "retry").Inc()
+		c.workqueue.AddRateLimited(obj)
+		return true
+	}
+
+	deliveries.WithLabelValues(d.endpoint, "failure").Inc()
+	c.workqueue.Forget(obj)
+
+	return true
+}
+
+func (c *Controller) deliver(d delivery) error {
+	var e *endpoint
+	for _, candidate := range c.endpoints {
+		if candidate.Name == d.endpoint {
+			e = candidate
+			break
+		}
+	}
+	if e == nil {
+		return shippererrors.NewNotificationDeliveryError(d.endpoint, fmt.Errorf("unknown endpoint"), false)
+	}
+
+	payload, err := renderPayload(e, d.notification)
+	if err != nil {
+		return shippererrors.NewNotificationDeliveryError(e.Name, err, false)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.URL, bytes.NewReader(payload))
+	if err != nil {
+		return shippererrors.NewNotificationDeliveryError(e.Name, err, false)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return shippererrors.NewNotificationDeliveryError(e.Name, err, true)
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused.
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	// Client errors won't go away by sending the same payload again,
+	// except for rate limiting.
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return shippererrors.NewNotificationDeliveryError(e.Name, fmt.Errorf("unexpected response %s", resp.Status), retry)
+}
+
+func renderPayload(e *endpoint, n Notification) ([]byte, error) {
+	if e.template == nil {
+		return json.Marshal(n)
+	}
+
+	var buf bytes.Buffer
+	if err := e.template.Execute(&buf, n); err != nil {
+		return nil, err
+	}
+
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("template rendered invalid JSON: %s", buf.String())
+	}
+
+	return buf.Bytes(), nil
+}
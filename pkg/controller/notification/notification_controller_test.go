@@ -0,0 +1,246 @@
+package notification
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
+	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+var now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func buildRelease(conditions ...shipper.ReleaseCondition) *shipper.Release {
+	return &shipper.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-release",
+			Namespace: shippertesting.TestNamespace,
+			Labels: map[string]string{
+				shipper.AppLabel: "test-app",
+			},
+		},
+		Status: shipper.ReleaseStatus{
+			Conditions: conditions,
+		},
+	}
+}
+
+func condition(condType shipper.ReleaseConditionType, status corev1.ConditionStatus, message string) shipper.ReleaseCondition {
+	return shipper.ReleaseCondition{Type: condType, Status: status, Message: message}
+}
+
+func TestReleaseTransitions(t *testing.T) {
+	scheduled := condition(shipper.ReleaseConditionTypeScheduled, corev1.ConditionTrue, "")
+	blocked := condition(shipper.ReleaseConditionTypeBlocked, corev1.ConditionTrue, "blocked by outage")
+	complete := condition(shipper.ReleaseConditionTypeComplete, corev1.ConditionTrue, "")
+
+	achieved := buildRelease(scheduled)
+	achieved.Status.AchievedStep = &shipper.AchievedStep{Step: 1, Name: "50/50"}
+
+	tests := []struct {
+		name     string
+		old, new *shipper.Release
+		expected []EventType
+	}{
+		{
+			"no changes",
+			buildRelease(scheduled),
+			buildRelease(scheduled),
+			nil,
+		},
+		{
+			"release scheduled",
+			buildRelease(),
+			buildRelease(scheduled),
+			[]EventType{EventReleaseStarted},
+		},
+		{
+			"step achieved",
+			buildRelease(scheduled),
+			achieved,
+			[]EventType{EventStepAchieved},
+		},
+		{
+			"release blocked",
+			buildRelease(scheduled),
+			buildRelease(scheduled, blocked),
+			[]EventType{EventReleaseBlocked},
+		},
+		{
+			"release completed",
+			achieved,
+			func() *shipper.Release {
+				rel := achieved.DeepCopy()
+				rel.Status.Conditions = append(rel.Status.Conditions, complete)
+				return rel
+			}(),
+			[]EventType{EventReleaseCompleted},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual []EventType
+			for _, n := range releaseTransitions(tt.old, tt.new, now) {
+				actual = append(actual, n.Event)
+			}
+
+			eq, diff := shippertesting.DeepEqualDiff(tt.expected, actual)
+			if !eq {
+				t.Fatalf("transitions differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEndpointSubscriptions(t *testing.T) {
+	n := Notification{
+		Event:       EventReleaseAborted,
+		Namespace:   "team-a",
+		Application: "reviews-api",
+	}
+
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		expected bool
+	}{
+		{"everything", Endpoint{}, true},
+		{"matching event", Endpoint{Events: []EventType{EventReleaseAborted}}, true},
+		{"other event", Endpoint{Events: []EventType{EventReleaseCompleted}}, false},
+		{"matching namespace", Endpoint{Namespaces: []string{"team-a"}}, true},
+		{"other namespace", Endpoint{Namespaces: []string{"team-b"}}, false},
+		{"matching application", Endpoint{Namespaces: []string{"team-a"}, Applications: []string{"reviews-api"}}, true},
+		{"other application", Endpoint{Applications: []string{"ratings-api"}}, false},
+	}
+
+	for _, tt := range tests {
+		if actual := tt.endpoint.subscribes(n); actual != tt.expected {
+			t.Errorf("%s: expected %t, got %t", tt.name, tt.expected, actual)
+		}
+	}
+}
+
+func newController(t *testing.T, endpoints ...Endpoint) *Controller {
+	informerFactory := shipperinformers.NewSharedInformerFactory(shipperfake.NewSimpleClientset(), 0)
+	c, err := NewController(informerFactory, &Config{Endpoints: endpoints}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("failed to build controller: %s", err)
+	}
+	return c
+}
+
+func TestDeliverTemplatedPayload(t *testing.T) {
+	var received map[string]string
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("endpoint received invalid JSON %q: %s", body, err)
+		}
+	}))
+	defer srv.Close()
+
+	c := newController(t, Endpoint{
+		Name:     "chat",
+		URL:      srv.URL,
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		Template: `{"text": {{ printf "%s/%s was %s: %s" .Namespace .Release .Event .Message | json }}}`,
+	})
+
+	rel := buildRelease(condition(shipper.ReleaseConditionTypeScheduled, corev1.ConditionTrue, ""))
+	aborted := rel.DeepCopy()
+	aborted.Status.Conditions = append(aborted.Status.Conditions,
+		condition(shipper.ReleaseConditionTypeAborted, corev1.ConditionTrue, `analysis "errors" failed`))
+
+	// Standby replicas don't queue up notifications they would never
+	// deliver.
+	c.onUpdateRelease(rel, aborted)
+	if c.workqueue.Len() != 0 {
+		t.Fatalf("expected no delivery to be enqueued before Run, got %d", c.workqueue.Len())
+	}
+
+	atomic.StoreInt32(&c.running, 1)
+	c.onUpdateRelease(rel, aborted)
+	if c.workqueue.Len() != 1 {
+		t.Fatalf("expected 1 delivery to be enqueued, got %d", c.workqueue.Len())
+	}
+
+	c.processNextWorkItem()
+
+	expected := map[string]string{
+		"text": `test-namespace/test-release was aborted: analysis "errors" failed`,
+	}
+	eq, diff := shippertesting.DeepEqualDiff(expected, received)
+	if !eq {
+		t.Fatalf("payload differs from expected:\n%s", diff)
+	}
+
+	if auth != "Bearer token" {
+		t.Fatalf("expected configured headers to be sent, got Authorization %q", auth)
+	}
+}
+
+func TestDeliveryErrorsAreRetried(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	c := newController(t, Endpoint{Name: "dashboard", URL: srv.URL})
+	d := delivery{
+		endpoint:     "dashboard",
+		notification: Notification{Event: EventReleaseCompleted, Time: now},
+	}
+
+	err := c.deliver(d)
+	if err == nil || !shippererrors.ShouldRetry(err) {
+		t.Fatalf("expected a retriable error, got %v", err)
+	}
+
+	status = http.StatusBadRequest
+	err = c.deliver(d)
+	if err == nil || shippererrors.ShouldRetry(err) {
+		t.Fatalf("expected a non retriable error, got %v", err)
+	}
+
+	status = http.StatusNoContent
+	if err := c.deliver(d); err != nil {
+		t.Fatalf("expected delivery to succeed, got %s", err)
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoints []Endpoint
+		valid     bool
+	}{
+		{"valid", []Endpoint{{Name: "a", URL: "https://example.com/hook"}}, true},
+		{"missing name", []Endpoint{{URL: "https://example.com/hook"}}, false},
+		{"duplicate name", []Endpoint{{Name: "a", URL: "https://example.com"}, {Name: "a", URL: "https://example.org"}}, false},
+		{"invalid url", []Endpoint{{Name: "a", URL: "example.com"}}, false},
+		{"unknown event", []Endpoint{{Name: "a", URL: "https://example.com", Events: []EventType{"deleted"}}}, false},
+		{"malformed template", []Endpoint{{Name: "a", URL: "https://example.com", Template: "{{ .Release"}}, false},
+	}
+
+	for _, tt := range tests {
+		err := (&Config{Endpoints: tt.endpoints}).Validate()
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid %t, got error %v", tt.name, tt.valid, err)
+		}
+	}
+}
@@ -12,9 +12,29 @@ func checkInstallation(it *shipper.InstallationTarget) (bool, string) {
 	return targetutil.IsReady(it.Status.Conditions)
 }
 
+// clusterCapacity is the capacity a strategy step asks for in a cluster:
+// a percentage of the requested replicas, or an absolute replica count when
+// replicas is set.
+type clusterCapacity struct {
+	percent  int32
+	replicas *int32
+}
+
+func (c clusterCapacity) matches(spec shipper.ClusterCapacityTarget) bool {
+	if spec.Percent != c.percent {
+		return false
+	}
+
+	if spec.Replicas == nil || c.replicas == nil {
+		return spec.Replicas == nil && c.replicas == nil
+	}
+
+	return *spec.Replicas == *c.replicas
+}
+
 func checkCapacity(
 	ct *shipper.CapacityTarget,
-	stepCapacity int32,
+	stepCapacity map[string]clusterCapacity,
 ) (
 	bool,
 	*shipper.CapacityTargetSpec,
@@ -27,11 +47,12 @@ func checkCapacity(
 	clustersNotReadyMap := make(map[string]struct{})
 	for _, spec := range ct.Spec.Clusters {
 		t := spec
-		if spec.Percent != stepCapacity {
+		if capacity := stepCapacity[spec.Name]; !capacity.matches(spec) {
 			t = shipper.ClusterCapacityTarget{
 				Name:              spec.Name,
-				Percent:           stepCapacity,
+				Percent:           capacity.percent,
 				TotalReplicaCount: spec.TotalReplicaCount,
+				Replicas:          capacity.replicas,
 			}
 
 			clustersNotReadyMap[spec.Name] = struct{}{}
@@ -79,7 +100,7 @@ func checkCapacity(
 
 func checkTraffic(
 	tt *shipper.TrafficTarget,
-	stepTrafficWeight uint32,
+	stepTrafficWeight map[string]uint32,
 ) (
 	bool,
 	*shipper.TrafficTargetSpec,
@@ -92,10 +113,10 @@ func checkTraffic(
 	clustersNotReadyMap := make(map[string]struct{})
 	for _, spec := range tt.Spec.Clusters {
 		t := spec
-		if spec.Weight != stepTrafficWeight {
+		if spec.Weight != stepTrafficWeight[spec.Name] {
 			t = shipper.ClusterTrafficTarget{
 				Name:   spec.Name,
-				Weight: stepTrafficWeight,
+				Weight: stepTrafficWeight[spec.Name],
 			}
 
 			clustersNotReadyMap[spec.Name] = struct{}{}
@@ -0,0 +1,25 @@
+package release
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	progressDeadlineExceeded = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "shipper",
+			Subsystem: "release_controller",
+			Name:      "progress_deadline_exceeded_total",
+			Help:      "How many times a Release failed to achieve a strategy step within its progress deadline",
+		},
+		[]string{"namespace", "application"},
+	)
+)
+
+// GetMetrics returns all the Prometheus variables the release controller
+// reports on. Used for registering with an HTTP handler.
+func GetMetrics() []prometheus.Collector {
+	return []prometheus.Collector{
+		progressDeadlineExceeded,
+	}
+}
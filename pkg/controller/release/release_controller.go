@@ -20,6 +20,7 @@ import (
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 
+	"github.com/bookingcom/shipper/pkg/analysis"
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shipperrepo "github.com/bookingcom/shipper/pkg/chart/repo"
 	shipperclient "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
@@ -28,6 +29,7 @@ import (
 	"github.com/bookingcom/shipper/pkg/controller"
 	shippercontroller "github.com/bookingcom/shipper/pkg/controller"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	apputil "github.com/bookingcom/shipper/pkg/util/application"
 	conditions "github.com/bookingcom/shipper/pkg/util/conditions"
 	diffutil "github.com/bookingcom/shipper/pkg/util/diff"
 	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
@@ -40,7 +42,8 @@ const (
 )
 
 const (
-	ClustersNotReady = "ClustersNotReady"
+	ClustersNotReady    = "ClustersNotReady"
+	ReplicaCountTooHigh = "ReplicaCountTooHigh"
 )
 
 // Controller is a Kubernetes controller whose role is to pick up a newly created
@@ -75,6 +78,13 @@ type Controller struct {
 
 	chartFetcher shipperrepo.ChartFetcher
 
+	analysisClient analysis.QueryClient
+
+	// rebalance is whether complete releases of applications that opted
+	// into it get to know which clusters they would be scheduled on if
+	// they were created now.
+	rebalance bool
+
 	recorder record.EventRecorder
 }
 
@@ -95,6 +105,8 @@ func NewController(
 	clientset shipperclient.Interface,
 	informerFactory shipperinformers.SharedInformerFactory,
 	chartFetcher shipperrepo.ChartFetcher,
+	analysisClient analysis.QueryClient,
+	rebalance bool,
 	recorder record.EventRecorder,
 ) *Controller {
 
@@ -139,6 +151,10 @@ func NewController(
 
 		chartFetcher: chartFetcher,
 
+		analysisClient: analysisClient,
+
+		rebalance: rebalance,
+
 		recorder: recorder,
 	}
 
@@ -155,6 +171,17 @@ func NewController(
 
 	rolloutBlockInformer.Informer().AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				// Blocks come into and go out of effect on their
+				// schedule, as reflected in their status, and may
+				// be rescoped to apply to other objects.
+				oldRB, oldOk := oldObj.(*shipper.RolloutBlock)
+				newRB, newOk := newObj.(*shipper.RolloutBlock)
+				if oldOk && newOk && (oldRB.Status.Active != newRB.Status.Active ||
+					!equality.Semantic.DeepEqual(oldRB.Spec.Scope, newRB.Spec.Scope)) {
+					controller.enqueueReleaseFromRolloutBlock(newObj)
+				}
+			},
 			DeleteFunc: controller.enqueueReleaseFromRolloutBlock,
 		})
 
@@ -170,6 +197,26 @@ func NewController(
 	capacityTargetInformer.Informer().AddEventHandler(eventHandler)
 	trafficTargetInformer.Informer().AddEventHandler(eventHandler)
 
+	if rebalance {
+		clusterInformer.Informer().AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					// Draining or reweighting a cluster can
+					// change where complete releases would
+					// be scheduled now. Health is left out
+					// on purpose: a failed probe should not
+					// roll out every application on the
+					// cluster again.
+					oldCluster, oldOk := oldObj.(*shipper.Cluster)
+					newCluster, newOk := newObj.(*shipper.Cluster)
+					if oldOk && newOk &&
+						!equality.Semantic.DeepEqual(oldCluster.Spec.Scheduler, newCluster.Spec.Scheduler) {
+						controller.enqueueCompleteReleases()
+					}
+				},
+			})
+	}
+
 	return controller
 }
 
@@ -274,8 +321,14 @@ func (c *Controller) syncOneReleaseHandler(key string) error {
 		return nil
 	}
 
+	if releaseutil.ReleaseAborted(rel) {
+		klog.V(4).Infof("Release %q has been aborted, waiting for it to be cleaned up", key)
+		return nil
+	}
+
 	var condition *shipper.ReleaseCondition
 	var relinfo *releaseInfo
+	var wasScheduled bool
 	var patches []StrategyPatch
 	var execRel *shipper.Release
 
@@ -307,36 +360,12 @@ func (c *Controller) syncOneReleaseHandler(key string) error {
 		klog.Errorf("failed to update application overrides: %v", err)
 	}
 
-	rolloutBlocked, events, err := rolloutblock.BlocksRollout(c.rolloutBlockLister, rel)
-	for _, ev := range events {
-		c.recorder.Event(rel, ev.Type, ev.Reason, ev.Message)
-	}
-
-	if rolloutBlocked {
-		var msg string
-		if err != nil {
-			msg = err.Error()
-		}
-
-		condition = releaseutil.NewReleaseCondition(
-			shipper.ReleaseConditionTypeBlocked,
-			corev1.ConditionTrue,
-			shipper.RolloutBlockReason,
-			msg,
-		)
-		diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *condition))
+	wasScheduled = releaseHasClusters(rel)
 
+	if err = c.checkRolloutBlocks(rel, diff); err != nil {
 		goto ApplyChanges
 	}
 
-	condition = releaseutil.NewReleaseCondition(
-		shipper.ReleaseConditionTypeBlocked,
-		corev1.ConditionFalse,
-		"",
-		"",
-	)
-	diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *condition))
-
 	relinfo, err = scheduler.ScheduleRelease(rel.DeepCopy())
 	if err != nil {
 		reason := reasonForReleaseCondition(err)
@@ -361,6 +390,15 @@ func (c *Controller) syncOneReleaseHandler(key string) error {
 	)
 	diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *condition))
 
+	// Rollout blocks scoped to clusters can only apply to a release once
+	// it knows which clusters it is on, so they need another look right
+	// after it's been scheduled for the first time.
+	if !wasScheduled {
+		if err = c.checkRolloutBlocks(rel, diff); err != nil {
+			goto ApplyChanges
+		}
+	}
+
 	execRel, patches, err = c.executeReleaseStrategy(relinfo, diff)
 	if err != nil {
 		releaseStrategyExecutedCond := releaseutil.NewReleaseCondition(
@@ -375,6 +413,10 @@ func (c *Controller) syncOneReleaseHandler(key string) error {
 	}
 	rel = execRel
 
+	if c.rebalance {
+		c.checkRebalancing(scheduler, rel)
+	}
+
 ApplyChanges:
 
 	if !equality.Semantic.DeepEqual(rel, baseRel) {
@@ -394,6 +436,123 @@ ApplyChanges:
 	return err
 }
 
+// checkRebalancing records on a complete contender the clusters it would be
+// scheduled on if it was created now, if they are not the ones it is on and
+// its application opted into rebalancing. The application controller can then
+// roll it out again to those clusters.
+func (c *Controller) checkRebalancing(scheduler *Scheduler, rel *shipper.Release) {
+	var rebalanced string
+
+	if releaseutil.ReleaseComplete(rel) {
+		isContender, err := c.isContender(rel)
+		if err != nil {
+			runtime.HandleError(fmt.Errorf("failed to check whether Release %q needs rebalancing: %s", controller.MetaKey(rel), err))
+			return
+		}
+
+		optedIn, err := c.applicationRebalances(rel)
+		if err != nil {
+			runtime.HandleError(fmt.Errorf("failed to check whether Release %q needs rebalancing: %s", controller.MetaKey(rel), err))
+			return
+		}
+
+		if isContender && optedIn {
+			clusters, err := scheduler.RebalancedClusters(rel)
+			if err != nil {
+				// Releases that could not be scheduled anew
+				// are better off where they are.
+				klog.V(4).Infof("Release %q can not be rebalanced: %s", controller.MetaKey(rel), err)
+			} else if names := strings.Join(clusters, ","); names != strings.Join(getReleaseClusters(rel), ",") {
+				rebalanced = names
+			}
+		}
+	}
+
+	if rebalanced == rel.Annotations[shipper.ReleaseRebalancedClustersAnnotation] {
+		return
+	}
+
+	if rebalanced == "" {
+		delete(rel.Annotations, shipper.ReleaseRebalancedClustersAnnotation)
+		return
+	}
+
+	rel.Annotations[shipper.ReleaseRebalancedClustersAnnotation] = rebalanced
+	c.recorder.Eventf(
+		rel,
+		corev1.EventTypeNormal,
+		"RebalancingNeeded",
+		"Release %q would be scheduled on clusters %q instead of %q",
+		controller.MetaKey(rel),
+		rebalanced,
+		rel.Annotations[shipper.ReleaseClustersAnnotation],
+	)
+}
+
+func (c *Controller) applicationRebalances(rel *shipper.Release) (bool, error) {
+	appName, err := releaseutil.ApplicationNameForRelease(rel)
+	if err != nil {
+		return false, err
+	}
+
+	app, err := c.applicationLister.Applications(rel.Namespace).Get(appName)
+	if err != nil {
+		return false, err
+	}
+
+	return apputil.RebalancingEnabled(app), nil
+}
+
+func (c *Controller) isContender(rel *shipper.Release) (bool, error) {
+	rels, err := c.applicationReleases(rel)
+	if err != nil {
+		return false, err
+	}
+
+	rels = releaseutil.SortByGenerationDescending(rels)
+
+	return len(rels) > 0 && rels[0].Name == rel.Name, nil
+}
+
+// checkRolloutBlocks sets the blocked condition of a release, and returns an
+// error if its rollout is blocked.
+func (c *Controller) checkRolloutBlocks(rel *shipper.Release, diff *diffutil.MultiDiff) error {
+	rolloutBlocked, events, err := rolloutblock.BlocksRollout(c.rolloutBlockLister, rel)
+	for _, ev := range events {
+		c.recorder.Event(rel, ev.Type, ev.Reason, ev.Message)
+	}
+
+	if rolloutBlocked {
+		var msg string
+		if err != nil {
+			msg = err.Error()
+		}
+
+		condition := releaseutil.NewReleaseCondition(
+			shipper.ReleaseConditionTypeBlocked,
+			corev1.ConditionTrue,
+			shipper.RolloutBlockReason,
+			msg,
+		)
+		diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *condition))
+
+		return err
+	}
+
+	condition := releaseutil.NewReleaseCondition(
+		shipper.ReleaseConditionTypeBlocked,
+		corev1.ConditionFalse,
+		"",
+		"",
+	)
+	diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *condition))
+
+	return nil
+}
+
+// updateApplicationOverrides adds the rollout block overrides of a release to
+// its application. The justification, author and timestamp of the overrides
+// go along with them, so the application keeps the same audit trail.
 func (c *Controller) updateApplicationOverrides(rel *shipper.Release) error {
 	namespace := rel.GetNamespace()
 	// update application with same override annotations as release
@@ -410,8 +569,23 @@ func (c *Controller) updateApplicationOverrides(rel *shipper.Release) error {
 	if strings.EqualFold(applicationOverrides, newOverrides) {
 		return nil
 	}
-	patch := make(map[string]interface{})
-	patch["annotations"] = newOverrides
+	annotations := map[string]string{
+		shipper.RolloutBlocksOverrideAnnotation: newOverrides,
+	}
+	for _, annotation := range []string{
+		shipper.RolloutBlocksOverrideJustificationAnnotation,
+		shipper.RolloutBlocksOverrideAuthorAnnotation,
+		shipper.RolloutBlocksOverrideTimestampAnnotation,
+	} {
+		if value, ok := rel.GetAnnotations()[annotation]; ok {
+			annotations[annotation] = value
+		}
+	}
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	}
 	b, _ := json.Marshal(patch)
 	if _, err = c.clientset.ShipperV1alpha1().Applications(namespace).Patch(appName, types.MergePatchType, b); err != nil {
 		return err
@@ -444,10 +618,25 @@ func (c *Controller) executeReleaseStrategy(relinfo *releaseInfo, diff *diffutil
 	if err != nil {
 		return nil, nil, err
 	}
-	executor := NewStrategyExecutor(strategy, targetStep, isSteppingBackwards)
+	clusters, err := c.clusterLister.List(labels.Everything())
+	if err != nil {
+		return nil, nil, shippererrors.NewKubeclientListError(
+			shipper.SchemeGroupVersion.WithKind("Cluster"),
+			"", labels.Everything(), err)
+	}
+
+	executor := NewStrategyExecutor(strategy, targetStep, isSteppingBackwards, clusters)
 	complete, patches, trans := executor.
 		Execute(relinfoPrev, relinfo, relinfoSucc, NewExecutorPipeline())
 
+	isHead := relinfoSucc == nil || relinfoSucc.release == nil
+	if complete && isHead && !isSteppingBackwards {
+		complete, err = c.analyzeStep(rel, diff, strategy.Steps[targetStep], targetStep)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// update logs, conditions, events
 	if len(patches) == 0 {
 		klog.V(4).Infof("Strategy verified for release %q, nothing to patch", controller.MetaKey(rel))
@@ -455,14 +644,223 @@ func (c *Controller) executeReleaseStrategy(relinfo *releaseInfo, diff *diffutil
 		klog.V(4).Infof("Strategy has been executed for release %q, applying patches", controller.MetaKey(rel))
 	}
 
-	isHead := relinfoSucc == nil || relinfoSucc.release == nil
 	prevStep := rel.Status.AchievedStep
 	achievedStep := updateConditions(rel, diff, targetStep, strategy, complete, isHead)
+	if isHead {
+		c.checkProgressDeadline(rel, diff, strategy, complete)
+	}
 	c.recordEvents(rel, achievedStep, prevStep, complete, trans)
 
+	if complete && isHead {
+		if err := c.progressPausedStep(rel, strategy, targetStep); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	return rel, patches, nil
 }
 
+// analyzeStep runs the analysis checks of the step a head release is
+// targeting, and reports whether all of them have passed. Until they have,
+// the release is enqueued again for when the next measurement is due. A
+// failed check marks the release as aborted, so the application controller
+// can return the application to its previous release.
+func (c *Controller) analyzeStep(rel *shipper.Release, diff *diffutil.MultiDiff, step shipper.RolloutStrategyStep, targetStep int32) (bool, error) {
+	if len(step.Analysis) == 0 || releaseutil.ReleaseAchievedTargetStep(rel) {
+		return true, nil
+	}
+
+	if c.analysisClient == nil {
+		err := fmt.Errorf("step %q requires analysis, but no analysis provider is configured", step.Name)
+		return false, shippererrors.NewUnrecoverableError(err)
+	}
+
+	result, statuses, err := analysis.Run(c.analysisClient, targetStep, step.Analysis, rel.Status.Analysis, time.Now())
+	if err != nil {
+		return false, err
+	}
+	rel.Status.Analysis = statuses
+
+	if result.Failed != nil {
+		msg := fmt.Sprintf("analysis %q failed in step %q: %s", result.Failed.Name, step.Name, result.Failed.Message)
+		condition := releaseutil.NewReleaseCondition(
+			shipper.ReleaseConditionTypeAborted,
+			corev1.ConditionTrue,
+			conditions.AnalysisFailed,
+			msg,
+		)
+		diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *condition))
+		c.recorder.Event(rel, corev1.EventTypeWarning, "ReleaseAborted", msg)
+
+		return false, nil
+	}
+
+	if !result.Passed {
+		klog.V(4).Infof("Release %q is waiting for analysis of step %d", controller.MetaKey(rel), targetStep)
+		c.releaseWorkqueue.AddAfter(controller.MetaKey(rel), result.NextMeasurement)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// progressPausedStep moves a head release on to the next step of its
+// strategy once the step it has achieved has been held for as long as the
+// step's pause asks for. Until then, the release is enqueued again for when
+// the pause is over. Steps with a manual pause and the last step of a
+// strategy are left alone.
+func (c *Controller) progressPausedStep(rel *shipper.Release, strategy *shipper.RolloutStrategy, targetStep int32) error {
+	if int(targetStep) >= len(strategy.Steps)-1 || !releaseutil.ReleaseAchievedTargetStep(rel) {
+		return nil
+	}
+
+	pause, ok, err := releaseutil.StepPauseDuration(strategy.Steps[targetStep])
+	if err != nil {
+		return shippererrors.NewUnrecoverableError(err)
+	} else if !ok {
+		return nil
+	}
+
+	held := time.Since(stepHeldSince(rel))
+	if held < pause {
+		klog.V(4).Infof("Release %q will progress past step %d in %s", controller.MetaKey(rel), targetStep, pause-held)
+		c.releaseWorkqueue.AddAfter(controller.MetaKey(rel), pause-held)
+		return nil
+	}
+
+	if missing := releaseutil.MissingApprovals(rel, targetStep+1); missing > 0 {
+		klog.V(4).Infof("Release %q will not progress past step %d before step %d gets %d more approval(s)",
+			controller.MetaKey(rel), targetStep, targetStep+1, missing)
+		return nil
+	}
+
+	rel.Spec.TargetStep = targetStep + 1
+
+	c.recorder.Eventf(
+		rel,
+		corev1.EventTypeNormal,
+		"StrategyStepProgressed",
+		"step [%d] was held for %s, progressing to step [%d]",
+		targetStep,
+		pause,
+		rel.Spec.TargetStep,
+	)
+
+	return nil
+}
+
+// stepHeldSince returns the moment since which a release has been
+// continuously holding its achieved step: either when it achieved it, or
+// when the last of its strategy conditions turned true, whichever is later.
+func stepHeldSince(rel *shipper.Release) time.Time {
+	since := rel.Status.AchievedStep.AchievedTime.Time
+	if rel.Status.Strategy == nil {
+		return time.Now()
+	}
+
+	for _, cond := range rel.Status.Strategy.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			// The condition is only about to be patched to true.
+			return time.Now()
+		}
+
+		if cond.LastTransitionTime.After(since) {
+			since = cond.LastTransitionTime.Time
+		}
+	}
+
+	return since
+}
+
+// checkProgressDeadline flags a head release that has not achieved its
+// target step within the progress deadline of its strategy. The clock starts
+// at the earliest transition of the strategy conditions that are not true
+// yet. If the strategy asks for it, the release is aborted as well, so the
+// application controller can roll back to the incumbent.
+func (c *Controller) checkProgressDeadline(rel *shipper.Release, diff *diffutil.MultiDiff, strategy *shipper.RolloutStrategy, complete bool) {
+	if strategy.ProgressDeadlineSeconds == nil {
+		return
+	}
+
+	prevCond := releaseutil.GetReleaseCondition(rel.Status, shipper.ReleaseConditionTypeProgressDeadlineExceeded)
+	exceeded := prevCond != nil && prevCond.Status == corev1.ConditionTrue
+
+	stuckSince, reasons := stuckStrategyConditions(rel)
+	if complete || stuckSince.IsZero() {
+		if exceeded {
+			condition := releaseutil.NewReleaseCondition(
+				shipper.ReleaseConditionTypeProgressDeadlineExceeded,
+				corev1.ConditionFalse,
+				"",
+				"",
+			)
+			diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *condition))
+		}
+		return
+	}
+
+	deadline := time.Duration(*strategy.ProgressDeadlineSeconds) * time.Second
+	if elapsed := time.Since(stuckSince); elapsed < deadline {
+		c.releaseWorkqueue.AddAfter(controller.MetaKey(rel), deadline-elapsed)
+		return
+	}
+
+	msg := fmt.Sprintf(
+		"step %d was not achieved within %s: %s",
+		rel.Spec.TargetStep, deadline, strings.Join(reasons, "; "),
+	)
+	condition := releaseutil.NewReleaseCondition(
+		shipper.ReleaseConditionTypeProgressDeadlineExceeded,
+		corev1.ConditionTrue,
+		conditions.ProgressDeadlineExceeded,
+		msg,
+	)
+	diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *condition))
+
+	if !exceeded {
+		c.recorder.Event(rel, corev1.EventTypeWarning, "ProgressDeadlineExceeded", msg)
+		progressDeadlineExceeded.WithLabelValues(rel.Namespace, rel.Labels[shipper.AppLabel]).Inc()
+	}
+
+	if strategy.RollbackOnProgressDeadline {
+		condition := releaseutil.NewReleaseCondition(
+			shipper.ReleaseConditionTypeAborted,
+			corev1.ConditionTrue,
+			conditions.ProgressDeadlineExceeded,
+			msg,
+		)
+		diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *condition))
+	}
+}
+
+// stuckStrategyConditions returns the earliest transition time of the
+// strategy conditions of a release that are not true, along with their
+// messages. The time is zero if all conditions are true.
+func stuckStrategyConditions(rel *shipper.Release) (time.Time, []string) {
+	var since time.Time
+	var reasons []string
+
+	if rel.Status.Strategy == nil {
+		return since, reasons
+	}
+
+	for _, cond := range rel.Status.Strategy.Conditions {
+		if cond.Status == corev1.ConditionTrue || cond.LastTransitionTime.IsZero() {
+			continue
+		}
+
+		if since.IsZero() || cond.LastTransitionTime.Time.Before(since) {
+			since = cond.LastTransitionTime.Time
+		}
+
+		if cond.Message != "" {
+			reasons = append(reasons, cond.Message)
+		}
+	}
+
+	return since, reasons
+}
+
 func updateConditions(rel *shipper.Release, diff *diffutil.MultiDiff, targetStep int32, strategy *shipper.RolloutStrategy, complete, isHead bool) int32 {
 	condition := releaseutil.NewReleaseCondition(
 		shipper.ReleaseConditionTypeStrategyExecuted,
@@ -482,13 +880,15 @@ func updateConditions(rel *shipper.Release, diff *diffutil.MultiDiff, targetStep
 			achievedStep = targetStep
 			achievedStepName = strategy.Steps[achievedStep].Name
 		} else {
-			achievedStep = int32(len(rel.Spec.Environment.Strategy.Steps)) - 1
-			achievedStepName = rel.Spec.Environment.Strategy.Steps[achievedStep].Name
+			steps := releaseutil.Strategy(rel).Steps
+			achievedStep = int32(len(steps)) - 1
+			achievedStepName = steps[achievedStep].Name
 		}
 		if prevStep == nil || achievedStep != prevStep.Step {
 			rel.Status.AchievedStep = &shipper.AchievedStep{
-				Step: achievedStep,
-				Name: achievedStepName,
+				Step:         achievedStep,
+				Name:         achievedStepName,
+				AchievedTime: metav1.Now(),
 			}
 		}
 
@@ -542,22 +942,36 @@ func strategyAndStepToExecute(rel *shipper.Release, relinfoSucc *releaseInfo) (*
 	var isSteppingBackwards bool
 	// A head release uses it's local spec-defined strategy, any other release
 	// follows it's successor state, therefore looking into the forecoming spec.
-	if isHead {
-		strategy = rel.Spec.Environment.Strategy
-		targetStep = rel.Spec.TargetStep
-		isSteppingBackwards = releaseutil.IsReleaseSteppingBackwards(rel.Status.AchievedStep, rel.Spec.TargetStep)
-	} else {
-		strategy = succ.Spec.Environment.Strategy
-		targetStep = succ.Spec.TargetStep
-		isSteppingBackwards = releaseutil.IsReleaseSteppingBackwards(succ.Status.AchievedStep, succ.Spec.TargetStep)
+	headRel := rel
+	if !isHead {
+		headRel = succ
 	}
 
+	strategy = releaseutil.Strategy(headRel)
+
+	// Steps that are still waiting for approvals are not honoured, in case
+	// the target step was bumped without going through the webhook.
+	targetStep = releaseutil.ApprovedTargetStep(headRel)
+	if targetStep != headRel.Spec.TargetStep {
+		klog.V(4).Infof("Release %q is waiting for approvals to progress past step %d",
+			controller.MetaKey(headRel), targetStep)
+	}
+
+	isSteppingBackwards = releaseutil.IsReleaseSteppingBackwards(headRel.Status.AchievedStep, targetStep)
+
 	// Looks like a malformed input. Informing about a problem and bailing out.
 	if targetStep >= int32(len(strategy.Steps)) {
 		err := fmt.Errorf("no step %d in strategy for Release %q",
 			targetStep, controller.MetaKey(rel))
 		return nil, 0, true, shippererrors.NewUnrecoverableError(err)
 	}
+
+	if err := releaseutil.ValidateStepClusters(strategy); err != nil {
+		err = fmt.Errorf("invalid strategy for Release %q: %s",
+			controller.MetaKey(rel), err)
+		return nil, 0, true, shippererrors.NewUnrecoverableError(err)
+	}
+
 	return strategy, targetStep, isSteppingBackwards, nil
 }
 
@@ -589,7 +1003,7 @@ func (c *Controller) buildReleasesInfo(relinfo *releaseInfo) (*releaseInfo, *rel
 			return nil, nil, shippererrors.NewInconsistentReleaseTargetStep(
 				controller.MetaKey(relinfo.release),
 				relinfo.release.Spec.TargetStep,
-				int32(len(relinfo.release.Spec.Environment.Strategy.Steps)-1),
+				int32(len(releaseutil.Strategy(relinfo.release).Steps)-1),
 			)
 		}
 
@@ -763,6 +1177,20 @@ func (c *Controller) enqueueReleaseFromRolloutBlock(obj interface{}) {
 	}
 }
 
+func (c *Controller) enqueueCompleteReleases() {
+	releases, err := c.releaseLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error fetching releases: %s", err))
+		return
+	}
+
+	for _, rel := range releases {
+		if releaseutil.ReleaseComplete(rel) {
+			c.enqueueRelease(rel)
+		}
+	}
+}
+
 func (c *Controller) enqueueReleaseFromAssociatedObject(obj interface{}) {
 	kubeobj, ok := obj.(metav1.Object)
 	if !ok {
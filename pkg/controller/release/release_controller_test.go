@@ -19,6 +19,7 @@ import (
 	kubetesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
 
+	"github.com/bookingcom/shipper/pkg/analysis"
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
 	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
@@ -154,6 +155,9 @@ type fixture struct {
 	filter         actionfilter
 	receivedEvents []string
 	expectedEvents []string
+
+	analysisClient analysis.QueryClient
+	rebalance      bool
 }
 
 func newFixture(t *testing.T, objects ...runtime.Object) *fixture {
@@ -227,6 +231,8 @@ func (f *fixture) newController() *Controller {
 		f.clientset,
 		f.informerFactory,
 		localFetchChart,
+		f.analysisClient,
+		f.rebalance,
 		f.recorder,
 	)
 }
@@ -699,8 +705,12 @@ func addCluster(ri *releaseInfo, cluster *shipper.Cluster) {
 	ri.installationTarget.Spec.Clusters = append(ri.installationTarget.Spec.Clusters,
 		cluster.Name,
 	)
+	var totalReplicaCount int32
+	if len(ri.capacityTarget.Spec.Clusters) > 0 {
+		totalReplicaCount = ri.capacityTarget.Spec.Clusters[0].TotalReplicaCount
+	}
 	ri.capacityTarget.Spec.Clusters = append(ri.capacityTarget.Spec.Clusters,
-		shipper.ClusterCapacityTarget{Name: cluster.Name, Percent: 0},
+		shipper.ClusterCapacityTarget{Name: cluster.Name, Percent: 0, TotalReplicaCount: totalReplicaCount},
 	)
 	ri.trafficTarget.Spec.Clusters = append(ri.trafficTarget.Spec.Clusters,
 		shipper.ClusterTrafficTarget{Name: cluster.Name, Weight: 0},
@@ -1499,7 +1509,7 @@ func TestContenderReleasePhaseIsWaitingForCommandForInitialStepState(t *testing.
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 
@@ -1532,7 +1542,7 @@ func TestContenderDoNothingClusterInstallationNotReady(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -1576,7 +1586,7 @@ func TestContenderDoNothingClusterCapacityNotReady(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -1635,7 +1645,7 @@ func TestContenderDoNothingClusterTrafficNotReady(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -1698,7 +1708,7 @@ func TestContenderCapacityShouldIncrease(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -1722,6 +1732,257 @@ func TestContenderCapacityShouldIncrease(t *testing.T) {
 	f.run()
 }
 
+func TestContenderCapacityShouldIncreaseInWave(t *testing.T) {
+	namespace := "test-namespace"
+	incumbentName, contenderName := "test-incumbent", "test-contender"
+	app := buildApplication(namespace, "test-app")
+	firstCluster := buildCluster("minikube")
+	secondCluster := buildCluster("second")
+
+	f := newFixture(t, app.DeepCopy(), firstCluster.DeepCopy(), secondCluster.DeepCopy())
+	f.cycles = 1
+
+	totalReplicaCount := int32(12)
+	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
+	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
+
+	// The second step only applies to the first cluster, so the second
+	// one should stay on the values of the first step.
+	strategy := vanguard.DeepCopy()
+	strategy.Steps[1].Clusters = &shipper.RolloutStrategyStepClusters{
+		Names: []string{"minikube"},
+	}
+	contender.release.Spec.Environment.Strategy = strategy
+	contender.release.Spec.TargetStep = 1
+	for i := range contender.capacityTarget.Spec.Clusters {
+		contender.capacityTarget.Spec.Clusters[i].Percent = 1
+	}
+
+	f.addObjects(
+		contender.release.DeepCopy(),
+		contender.installationTarget.DeepCopy(),
+		contender.capacityTarget.DeepCopy(),
+		contender.trafficTarget.DeepCopy(),
+
+		incumbent.release.DeepCopy(),
+		incumbent.installationTarget.DeepCopy(),
+		incumbent.capacityTarget.DeepCopy(),
+		incumbent.trafficTarget.DeepCopy(),
+	)
+
+	f.filter = f.filter.Extend(actionfilter{
+		[]string{"patch"},
+		[]string{"capacitytargets"},
+	})
+
+	ct := contender.capacityTarget
+	newSpec := map[string]interface{}{
+		"spec": shipper.CapacityTargetSpec{
+			Clusters: []shipper.ClusterCapacityTarget{
+				{Name: "minikube", Percent: 50, TotalReplicaCount: totalReplicaCount},
+				{Name: "second", Percent: 1, TotalReplicaCount: totalReplicaCount},
+			},
+		},
+	}
+	patch, _ := json.Marshal(newSpec)
+	f.actions = append(f.actions, kubetesting.NewPatchAction(
+		shipper.SchemeGroupVersion.WithResource("capacitytargets"),
+		ct.GetNamespace(),
+		ct.GetName(),
+		types.MergePatchType,
+		patch,
+	))
+
+	f.expectedEvents = []string{
+		"Normal ReleaseConditionChanged [] -> [Scheduled True], [] -> [StrategyExecuted True]",
+	}
+
+	f.run()
+}
+
+func TestContenderCapacityShouldIncreaseToReplicaCount(t *testing.T) {
+	namespace := "test-namespace"
+	incumbentName, contenderName := "test-incumbent", "test-contender"
+	app := buildApplication(namespace, "test-app")
+	cluster := buildCluster("minikube")
+
+	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
+	f.cycles = 1
+
+	totalReplicaCount := int32(12)
+	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
+	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
+
+	replicas := int32(3)
+	strategy := vanguard.DeepCopy()
+	strategy.Steps[1].Capacity.ContenderReplicas = &shipper.StepReplicaCount{PerCluster: &replicas}
+	contender.release.Spec.Environment.Strategy = strategy
+	contender.release.Spec.TargetStep = 1
+	contender.capacityTarget.Spec.Clusters[0].Percent = 1
+
+	f.addObjects(
+		contender.release.DeepCopy(),
+		contender.installationTarget.DeepCopy(),
+		contender.capacityTarget.DeepCopy(),
+		contender.trafficTarget.DeepCopy(),
+
+		incumbent.release.DeepCopy(),
+		incumbent.installationTarget.DeepCopy(),
+		incumbent.capacityTarget.DeepCopy(),
+		incumbent.trafficTarget.DeepCopy(),
+	)
+
+	f.filter = f.filter.Extend(actionfilter{
+		[]string{"patch"},
+		[]string{"capacitytargets"},
+	})
+
+	ct := contender.capacityTarget
+	newSpec := map[string]interface{}{
+		"spec": shipper.CapacityTargetSpec{
+			Clusters: []shipper.ClusterCapacityTarget{
+				{
+					Name:              "minikube",
+					Percent:           strategy.Steps[1].Capacity.Contender,
+					TotalReplicaCount: totalReplicaCount,
+					Replicas:          &replicas,
+				},
+			},
+		},
+	}
+	patch, _ := json.Marshal(newSpec)
+	f.actions = append(f.actions, kubetesting.NewPatchAction(
+		shipper.SchemeGroupVersion.WithResource("capacitytargets"),
+		ct.GetNamespace(),
+		ct.GetName(),
+		types.MergePatchType,
+		patch,
+	))
+
+	f.expectedEvents = []string{
+		"Normal ReleaseConditionChanged [] -> [Scheduled True], [] -> [StrategyExecuted True]",
+	}
+
+	f.run()
+}
+
+func TestContenderCapacityShouldNotExceedRequestedReplicas(t *testing.T) {
+	namespace := "test-namespace"
+	app := buildApplication(namespace, "test-app")
+	cluster := buildCluster("minikube")
+
+	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
+	f.cycles = 1
+
+	totalReplicaCount := int32(12)
+	contender := f.buildContender(namespace, "test-contender", totalReplicaCount)
+	incumbent := f.buildIncumbent(namespace, "test-incumbent", totalReplicaCount)
+
+	// The release only requests 10 replicas, so the capacity target
+	// should be left alone.
+	replicas := int32(20)
+	strategy := vanguard.DeepCopy()
+	strategy.Steps[1].Capacity.ContenderReplicas = &shipper.StepReplicaCount{PerCluster: &replicas}
+	contender.release.Spec.Environment.Strategy = strategy
+	contender.release.Spec.TargetStep = 1
+	contender.capacityTarget.Spec.Clusters[0].Percent = 1
+
+	f.addObjects(
+		contender.release.DeepCopy(),
+		contender.installationTarget.DeepCopy(),
+		contender.capacityTarget.DeepCopy(),
+		contender.trafficTarget.DeepCopy(),
+
+		incumbent.release.DeepCopy(),
+		incumbent.installationTarget.DeepCopy(),
+		incumbent.capacityTarget.DeepCopy(),
+		incumbent.trafficTarget.DeepCopy(),
+	)
+
+	f.filter = f.filter.Extend(actionfilter{
+		[]string{"patch"},
+		[]string{"capacitytargets"},
+	})
+
+	f.expectedEvents = []string{
+		"Normal ReleaseConditionChanged [] -> [Scheduled True], [] -> [StrategyExecuted True]",
+	}
+
+	f.run()
+}
+
+func buildGatedContender(f *fixture, namespace string, approvers ...string) (*releaseInfo, *releaseInfo) {
+	totalReplicaCount := int32(12)
+	contender := f.buildContender(namespace, "test-contender", totalReplicaCount)
+	incumbent := f.buildIncumbent(namespace, "test-incumbent", totalReplicaCount)
+
+	strategy := vanguard.DeepCopy()
+	strategy.Steps[1].Approvals = 2
+	contender.release.Spec.Environment.Strategy = strategy
+	contender.release.Spec.TargetStep = 1
+	for _, approver := range approvers {
+		contender.release.Status.Approvals = append(contender.release.Status.Approvals,
+			shipper.StepApproval{Step: 1, User: approver})
+	}
+
+	contender.capacityTarget.Spec.Clusters[0].Percent = 1
+	incumbent.capacityTarget.Spec.Clusters[0].Percent = 100
+
+	f.addObjects(
+		contender.release.DeepCopy(),
+		contender.installationTarget.DeepCopy(),
+		contender.capacityTarget.DeepCopy(),
+		contender.trafficTarget.DeepCopy(),
+
+		incumbent.release.DeepCopy(),
+		incumbent.installationTarget.DeepCopy(),
+		incumbent.capacityTarget.DeepCopy(),
+		incumbent.trafficTarget.DeepCopy(),
+	)
+
+	return contender, incumbent
+}
+
+func TestContenderCapacityShouldNotIncreaseWithoutApprovals(t *testing.T) {
+	namespace := "test-namespace"
+	app := buildApplication(namespace, "test-app")
+	cluster := buildCluster("minikube")
+
+	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
+	f.cycles = 1
+
+	// A single approval is not enough, so the contender should stay on
+	// the first step.
+	contender, _ := buildGatedContender(f, namespace, "alice", "alice")
+
+	f.expectReleaseWaitingForCommand(contender.release, 0)
+	f.filter = f.filter.Extend(actionfilter{
+		[]string{"patch"},
+		[]string{"capacitytargets"},
+	})
+
+	f.run()
+}
+
+func TestContenderCapacityShouldIncreaseWithApprovals(t *testing.T) {
+	namespace := "test-namespace"
+	app := buildApplication(namespace, "test-app")
+	cluster := buildCluster("minikube")
+
+	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
+	f.cycles = 1
+
+	contender, _ := buildGatedContender(f, namespace, "alice", "bob")
+
+	f.expectCapacityStatusPatch(
+		contender.release.Spec.TargetStep,
+		contender.capacityTarget.DeepCopy(),
+		contender.release.DeepCopy(),
+		50, 12, Contender)
+
+	f.run()
+}
+
 func TestContenderCapacityShouldIncreaseWithRolloutBlockOverride(t *testing.T) {
 	namespace := "test-namespace"
 	incumbentName, contenderName := "test-incumbent", "test-contender"
@@ -1730,7 +1991,7 @@ func TestContenderCapacityShouldIncreaseWithRolloutBlockOverride(t *testing.T) {
 	rolloutBlockKey := fmt.Sprintf("%s/%s", namespace, testRolloutBlockName)
 	cluster := buildCluster("minikube")
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy(), rolloutBlock.DeepCopy())
 	f.cycles = 1
 
@@ -1765,6 +2026,95 @@ func TestContenderCapacityShouldIncreaseWithRolloutBlockOverride(t *testing.T) {
 	f.run()
 }
 
+// TestApplicationOverridesKeepAuditTrail checks that rollout block overrides
+// added to a release are copied to its application along with their
+// justification, author and timestamp, as the webhook rejects overrides
+// without a justification.
+func TestApplicationOverridesKeepAuditTrail(t *testing.T) {
+	namespace := "test-namespace"
+	app := buildApplication(namespace, "test-app")
+	rolloutBlockKey := fmt.Sprintf("%s/%s", namespace, testRolloutBlockName)
+
+	f := newFixture(t, app.DeepCopy(), buildCluster("minikube"))
+	contender := f.buildContender(namespace, "test-contender", 1)
+	rel := contender.release
+	rel.Annotations[shipper.RolloutBlocksOverrideAnnotation] = rolloutBlockKey
+	rel.Annotations[shipper.RolloutBlocksOverrideJustificationAnnotation] = "hotfix for an outage"
+	rel.Annotations[shipper.RolloutBlocksOverrideAuthorAnnotation] = "jdoe@example.com"
+	rel.Annotations[shipper.RolloutBlocksOverrideTimestampAnnotation] = "2026-10-18T12:00:00Z"
+
+	f.clientset = shipperfake.NewSimpleClientset(app.DeepCopy(), rel.DeepCopy())
+	f.informerFactory = shipperinformers.NewSharedInformerFactory(f.clientset, 0)
+	f.recorder = record.NewFakeRecorder(42)
+	c := f.newController()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	f.informerFactory.Start(stopCh)
+	f.informerFactory.WaitForCacheSync(stopCh)
+
+	if err := c.updateApplicationOverrides(rel); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := f.clientset.ShipperV1alpha1().Applications(namespace).Get(app.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, annotation := range []string{
+		shipper.RolloutBlocksOverrideAnnotation,
+		shipper.RolloutBlocksOverrideJustificationAnnotation,
+		shipper.RolloutBlocksOverrideAuthorAnnotation,
+		shipper.RolloutBlocksOverrideTimestampAnnotation,
+	} {
+		if expected, got := rel.Annotations[annotation], updated.Annotations[annotation]; got != expected {
+			t.Errorf("expected application annotation %q to be %q, got %q", annotation, expected, got)
+		}
+	}
+}
+
+func TestContenderCapacityShouldIncreaseWithRolloutBlockScopedToOtherCluster(t *testing.T) {
+	namespace := "test-namespace"
+	incumbentName, contenderName := "test-incumbent", "test-contender"
+	app := buildApplication(namespace, "test-app")
+	rolloutBlock := newRolloutBlock(testRolloutBlockName, namespace)
+	rolloutBlock.Spec.Scope = &shipper.RolloutBlockScope{
+		Clusters: []string{"some-other-cluster"},
+	}
+	cluster := buildCluster("minikube")
+
+	totalReplicaCount := int32(12)
+	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy(), rolloutBlock.DeepCopy())
+	f.cycles = 1
+
+	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
+	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
+
+	contender.release.Spec.TargetStep = 1
+
+	incumbent.capacityTarget.Spec.Clusters[0].Percent = 50
+	incumbent.capacityTarget.Spec.Clusters[0].TotalReplicaCount = totalReplicaCount
+	incumbent.trafficTarget.Spec.Clusters[0].Weight = 50
+
+	f.addObjects(
+		contender.release.DeepCopy(),
+		contender.installationTarget.DeepCopy(),
+		contender.capacityTarget.DeepCopy(),
+		contender.trafficTarget.DeepCopy(),
+
+		incumbent.release.DeepCopy(),
+		incumbent.installationTarget.DeepCopy(),
+		incumbent.capacityTarget.DeepCopy(),
+		incumbent.trafficTarget.DeepCopy(),
+	)
+
+	ct := contender.capacityTarget.DeepCopy()
+	r := contender.release.DeepCopy()
+	f.expectCapacityStatusPatch(contender.release.Spec.TargetStep, ct, r, 50, uint(totalReplicaCount), Contender)
+	f.run()
+}
+
 func TestContenderCapacityShouldNotIncreaseWithRolloutBlock(t *testing.T) {
 	namespace := "test-namespace"
 	contenderName := "test-contender-bimbambom"
@@ -1820,7 +2170,7 @@ func TestContenderTrafficShouldIncrease(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1 // It only runs a single cycle of processNextReleaseWorkItem
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -1850,6 +2200,76 @@ func TestContenderTrafficShouldIncrease(t *testing.T) {
 	f.run()
 }
 
+func TestBlueGreenContenderSwitchesTrafficAtOnce(t *testing.T) {
+	namespace := "test-namespace"
+	incumbentName, contenderName := "test-incumbent", "test-contender"
+	app := buildApplication(namespace, "test-app")
+	cluster := buildCluster("minikube")
+
+	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
+	f.cycles = 1
+
+	totalReplicaCount := int32(12)
+	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
+	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
+
+	contender.release.Spec.Environment.Strategy = &shipper.RolloutStrategy{
+		BlueGreen: &shipper.BlueGreenStrategy{IncumbentRetention: "30m"},
+	}
+	contender.release.Spec.TargetStep = releaseutil.BlueGreenStepSwitch
+	contender.capacityTarget.Spec.Clusters[0].Percent = 100
+	incumbent.capacityTarget.Spec.Clusters[0].Percent = 100
+
+	f.addObjects(
+		contender.release.DeepCopy(),
+		contender.installationTarget.DeepCopy(),
+		contender.capacityTarget.DeepCopy(),
+		contender.trafficTarget.DeepCopy(),
+
+		incumbent.release.DeepCopy(),
+		incumbent.installationTarget.DeepCopy(),
+		incumbent.capacityTarget.DeepCopy(),
+		incumbent.trafficTarget.DeepCopy(),
+	)
+
+	f.filter = f.filter.Extend(actionfilter{
+		[]string{"patch"},
+		[]string{"traffictargets"},
+	})
+
+	// Both traffic targets are expected to be patched in the same sync,
+	// without waiting for the contender to achieve traffic first.
+	for _, expected := range []struct {
+		tt     *shipper.TrafficTarget
+		weight uint32
+	}{
+		{contender.trafficTarget, 100},
+		{incumbent.trafficTarget, 0},
+	} {
+		newSpec := map[string]interface{}{
+			"spec": shipper.TrafficTargetSpec{
+				Clusters: []shipper.ClusterTrafficTarget{
+					{Name: "minikube", Weight: expected.weight},
+				},
+			},
+		}
+		patch, _ := json.Marshal(newSpec)
+		f.actions = append(f.actions, kubetesting.NewPatchAction(
+			shipper.SchemeGroupVersion.WithResource("traffictargets"),
+			expected.tt.GetNamespace(),
+			expected.tt.GetName(),
+			types.MergePatchType,
+			patch,
+		))
+	}
+
+	f.expectedEvents = []string{
+		"Normal ReleaseConditionChanged [] -> [Scheduled True], [] -> [StrategyExecuted True]",
+	}
+
+	f.run()
+}
+
 func TestContenderTrafficShouldIncreaseWithRolloutBlockOverride(t *testing.T) {
 	namespace := "test-namespace"
 	incumbentName, contenderName := "test-incumbent", "test-contender"
@@ -1861,7 +2281,7 @@ func TestContenderTrafficShouldIncreaseWithRolloutBlockOverride(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy(), rolloutBlock.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -1907,7 +2327,7 @@ func TestContenderTrafficShouldNotIncreaseWithRolloutBlock(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy(), rolloutBlock.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 
 	contender.release.Spec.TargetStep = 1
@@ -1951,7 +2371,7 @@ func TestIncumbentTrafficShouldDecrease(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -1990,7 +2410,7 @@ func TestIncumbentTrafficShouldDecreaseWithRolloutBlockOverride(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy(), rolloutBlock.DeepCopy())
 	f.cycles = 1 // we're looking at a single-step progression
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -2078,7 +2498,7 @@ func TestIncumbentCapacityShouldDecrease(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -2119,7 +2539,7 @@ func TestIncumbentCapacityShouldDecreaseWithRolloutBlockOverride(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy(), rolloutBlock.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(3)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -2210,7 +2630,7 @@ func TestContenderReleasePhaseIsWaitingForCommandForFinalStepState(t *testing.T)
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -2238,6 +2658,299 @@ func TestContenderReleasePhaseIsWaitingForCommandForFinalStepState(t *testing.T)
 	f.run()
 }
 
+func buildPausedContender(f *fixture, namespace string, pause string, heldFor time.Duration) *releaseInfo {
+	totalReplicaCount := int32(12)
+	incumbent := f.buildIncumbent(namespace, "test-incumbent", totalReplicaCount)
+	contender := f.buildContender(namespace, "test-contender", totalReplicaCount)
+
+	strategy := vanguard.DeepCopy()
+	strategy.Steps[0].Pause = pause
+	contender.release.Spec.Environment.Strategy = strategy
+	contender.release.Status.AchievedStep = &shipper.AchievedStep{
+		Step:         0,
+		Name:         strategy.Steps[0].Name,
+		AchievedTime: metav1.NewTime(time.Now().Add(-heldFor)),
+	}
+
+	contender.capacityTarget.Spec.Clusters[0].Percent = 1
+	incumbent.capacityTarget.Spec.Clusters[0].Percent = 100
+
+	f.addObjects(
+		contender.release.DeepCopy(),
+		contender.installationTarget.DeepCopy(),
+		contender.capacityTarget.DeepCopy(),
+		contender.trafficTarget.DeepCopy(),
+
+		incumbent.release.DeepCopy(),
+		incumbent.installationTarget.DeepCopy(),
+		incumbent.capacityTarget.DeepCopy(),
+		incumbent.trafficTarget.DeepCopy(),
+	)
+
+	return contender
+}
+
+func TestContenderProgressesAfterPause(t *testing.T) {
+	namespace := "test-namespace"
+	app := buildApplication(namespace, "test-app")
+	cluster := buildCluster("minikube")
+
+	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
+	f.cycles = 1
+
+	contender := buildPausedContender(f, namespace, "30m", time.Hour)
+
+	expected := contender.release.DeepCopy()
+	expected.Spec.TargetStep = 1
+	condScheduled := releaseutil.NewReleaseCondition(shipper.ReleaseConditionTypeScheduled, corev1.ConditionTrue, "", "")
+	releaseutil.SetReleaseCondition(&expected.Status, *condScheduled)
+	condStrategyExecuted := releaseutil.NewReleaseCondition(shipper.ReleaseConditionTypeStrategyExecuted, corev1.ConditionTrue, "", "")
+	releaseutil.SetReleaseCondition(&expected.Status, *condStrategyExecuted)
+
+	f.actions = []kubetesting.Action{
+		kubetesting.NewUpdateAction(
+			shipper.SchemeGroupVersion.WithResource("releases"),
+			namespace,
+			expected),
+	}
+	f.filter = f.filter.Extend(actionfilter{[]string{"update"}, []string{"releases"}})
+
+	relKey := fmt.Sprintf("%s/%s", namespace, contender.release.GetName())
+	f.expectedEvents = []string{
+		fmt.Sprintf(`Normal ReleaseStateTransitioned Release "%s" had its state "WaitingForCapacity" transitioned to "False"`, relKey),
+		fmt.Sprintf(`Normal ReleaseStateTransitioned Release "%s" had its state "WaitingForCommand" transitioned to "True"`, relKey),
+		fmt.Sprintf(`Normal ReleaseStateTransitioned Release "%s" had its state "WaitingForInstallation" transitioned to "False"`, relKey),
+		fmt.Sprintf(`Normal ReleaseStateTransitioned Release "%s" had its state "WaitingForTraffic" transitioned to "False"`, relKey),
+		"Normal StrategyStepProgressed step [0] was held for 30m0s, progressing to step [1]",
+		"Normal ReleaseConditionChanged [] -> [Scheduled True], [] -> [StrategyExecuted True]",
+	}
+
+	f.run()
+}
+
+func TestContenderDoesNotProgressDuringPause(t *testing.T) {
+	namespace := "test-namespace"
+	app := buildApplication(namespace, "test-app")
+	cluster := buildCluster("minikube")
+
+	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
+	f.cycles = 1
+
+	contender := buildPausedContender(f, namespace, "30m", time.Minute)
+
+	expected := contender.release.DeepCopy()
+	condScheduled := releaseutil.NewReleaseCondition(shipper.ReleaseConditionTypeScheduled, corev1.ConditionTrue, "", "")
+	releaseutil.SetReleaseCondition(&expected.Status, *condScheduled)
+	condStrategyExecuted := releaseutil.NewReleaseCondition(shipper.ReleaseConditionTypeStrategyExecuted, corev1.ConditionTrue, "", "")
+	releaseutil.SetReleaseCondition(&expected.Status, *condStrategyExecuted)
+
+	// The release keeps its target step: the only update is the one
+	// marking it as scheduled.
+	f.actions = []kubetesting.Action{
+		kubetesting.NewUpdateAction(
+			shipper.SchemeGroupVersion.WithResource("releases"),
+			namespace,
+			expected),
+	}
+	f.filter = f.filter.Extend(actionfilter{[]string{"update"}, []string{"releases"}})
+
+	relKey := fmt.Sprintf("%s/%s", namespace, contender.release.GetName())
+	f.expectedEvents = []string{
+		fmt.Sprintf(`Normal ReleaseStateTransitioned Release "%s" had its state "WaitingForCapacity" transitioned to "False"`, relKey),
+		fmt.Sprintf(`Normal ReleaseStateTransitioned Release "%s" had its state "WaitingForCommand" transitioned to "True"`, relKey),
+		fmt.Sprintf(`Normal ReleaseStateTransitioned Release "%s" had its state "WaitingForInstallation" transitioned to "False"`, relKey),
+		fmt.Sprintf(`Normal ReleaseStateTransitioned Release "%s" had its state "WaitingForTraffic" transitioned to "False"`, relKey),
+		"Normal ReleaseConditionChanged [] -> [Scheduled True], [] -> [StrategyExecuted True]",
+	}
+
+	f.run()
+}
+
+type fakeQueryClient map[string]float64
+
+func (c fakeQueryClient) Query(query string, ts time.Time) (float64, error) {
+	value, ok := c[query]
+	if !ok {
+		return 0, fmt.Errorf("unknown query %q", query)
+	}
+	return value, nil
+}
+
+func buildAnalyzedContender(f *fixture, namespace string) *releaseInfo {
+	totalReplicaCount := int32(12)
+	incumbent := f.buildIncumbent(namespace, "test-incumbent", totalReplicaCount)
+	contender := f.buildContender(namespace, "test-contender", totalReplicaCount)
+
+	strategy := vanguard.DeepCopy()
+	strategy.Steps[0].Analysis = []shipper.RolloutStepAnalysis{
+		{
+			Name:      "errors",
+			Query:     "errors",
+			Operator:  shipper.AnalysisOperatorLessThan,
+			Threshold: 0.1,
+		},
+	}
+	contender.release.Spec.Environment.Strategy = strategy
+
+	contender.capacityTarget.Spec.Clusters[0].Percent = 1
+	incumbent.capacityTarget.Spec.Clusters[0].Percent = 100
+
+	f.addObjects(
+		contender.release.DeepCopy(),
+		contender.installationTarget.DeepCopy(),
+		contender.capacityTarget.DeepCopy(),
+		contender.trafficTarget.DeepCopy(),
+
+		incumbent.release.DeepCopy(),
+		incumbent.installationTarget.DeepCopy(),
+		incumbent.capacityTarget.DeepCopy(),
+		incumbent.trafficTarget.DeepCopy(),
+	)
+
+	return contender
+}
+
+func TestContenderAchievesStepAfterAnalysis(t *testing.T) {
+	namespace := "test-namespace"
+	app := buildApplication(namespace, "test-app")
+	cluster := buildCluster("minikube")
+
+	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
+	f.cycles = 1
+	f.analysisClient = fakeQueryClient{"errors": 0.01}
+
+	contender := buildAnalyzedContender(f, namespace)
+
+	f.expectReleaseWaitingForCommand(contender.release, 0)
+	f.run()
+}
+
+func TestContenderIsAbortedAfterFailedAnalysis(t *testing.T) {
+	namespace := "test-namespace"
+	app := buildApplication(namespace, "test-app")
+	cluster := buildCluster("minikube")
+
+	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
+	f.cycles = 1
+	f.analysisClient = fakeQueryClient{"errors": 0.5}
+
+	contender := buildAnalyzedContender(f, namespace)
+
+	// The strategy conditions are still patched, but the step is not
+	// considered achieved.
+	f.expectReleaseWaitingForCommand(contender.release, 0)
+
+	relKey := fmt.Sprintf("%s/%s", namespace, contender.release.GetName())
+	msg := `analysis "errors" failed in step "staging": query "errors" returned 0.5, expected < 0.1`
+	f.expectedEvents = []string{
+		fmt.Sprintf("Warning ReleaseAborted %s", msg),
+		fmt.Sprintf(`Normal ReleaseStateTransitioned Release "%s" had its state "WaitingForCapacity" transitioned to "False"`, relKey),
+		fmt.Sprintf(`Normal ReleaseStateTransitioned Release "%s" had its state "WaitingForCommand" transitioned to "True"`, relKey),
+		fmt.Sprintf(`Normal ReleaseStateTransitioned Release "%s" had its state "WaitingForInstallation" transitioned to "False"`, relKey),
+		fmt.Sprintf(`Normal ReleaseStateTransitioned Release "%s" had its state "WaitingForTraffic" transitioned to "False"`, relKey),
+		fmt.Sprintf("Normal ReleaseConditionChanged [] -> [Scheduled True], [] -> [Aborted True %s %s], [] -> [StrategyExecuted True]", conditions.AnalysisFailed, msg),
+	}
+
+	f.run()
+}
+
+func buildStuckContender(f *fixture, namespace string, deadline int32, stuckFor time.Duration) *releaseInfo {
+	totalReplicaCount := int32(12)
+	incumbent := f.buildIncumbent(namespace, "test-incumbent", totalReplicaCount)
+	contender := f.buildContender(namespace, "test-contender", totalReplicaCount)
+
+	strategy := vanguard.DeepCopy()
+	strategy.ProgressDeadlineSeconds = &deadline
+	strategy.RollbackOnProgressDeadline = true
+	contender.release.Spec.Environment.Strategy = strategy
+
+	contender.capacityTarget.Spec.Clusters[0].Percent = 1
+	contender.capacityTarget.Status.Conditions, _ = targetutil.SetTargetCondition(
+		contender.capacityTarget.Status.Conditions,
+		targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeReady,
+			corev1.ConditionFalse,
+			ClustersNotReady, "[minikube]"))
+	incumbent.capacityTarget.Spec.Clusters[0].Percent = 100
+
+	contender.release.Status.Strategy = &shipper.ReleaseStrategyStatus{
+		Conditions: []shipper.ReleaseStrategyCondition{
+			{
+				Type:               shipper.StrategyConditionContenderAchievedCapacity,
+				Status:             corev1.ConditionFalse,
+				Reason:             ClustersNotReady,
+				Message:            "pods not ready in clusters: [minikube]",
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-stuckFor)),
+			},
+			{
+				Type:   shipper.StrategyConditionContenderAchievedInstallation,
+				Status: corev1.ConditionTrue,
+			},
+		},
+	}
+
+	f.addObjects(
+		contender.release.DeepCopy(),
+		contender.installationTarget.DeepCopy(),
+		contender.capacityTarget.DeepCopy(),
+		contender.trafficTarget.DeepCopy(),
+
+		incumbent.release.DeepCopy(),
+		incumbent.installationTarget.DeepCopy(),
+		incumbent.capacityTarget.DeepCopy(),
+		incumbent.trafficTarget.DeepCopy(),
+	)
+
+	// The capacity target spec is already in place, so no patches are
+	// expected: only the events are of interest here.
+	f.filter = f.filter.Extend(actionfilter{
+		[]string{"patch"},
+		[]string{"capacitytargets"},
+	})
+
+	return contender
+}
+
+func TestContenderExceedsProgressDeadline(t *testing.T) {
+	namespace := "test-namespace"
+	app := buildApplication(namespace, "test-app")
+	cluster := buildCluster("minikube")
+
+	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
+	f.cycles = 1
+
+	buildStuckContender(f, namespace, 600, time.Hour)
+
+	msg := "step 0 was not achieved within 10m0s: pods not ready in clusters: [minikube]"
+	f.expectedEvents = []string{
+		fmt.Sprintf("Warning ProgressDeadlineExceeded %s", msg),
+		fmt.Sprintf(
+			"Normal ReleaseConditionChanged [] -> [Scheduled True], [] -> [StrategyExecuted True], [] -> [ProgressDeadlineExceeded True %s %s], [] -> [Aborted True %s %s]",
+			conditions.ProgressDeadlineExceeded, msg,
+			conditions.ProgressDeadlineExceeded, msg,
+		),
+	}
+
+	f.run()
+}
+
+func TestContenderWithinProgressDeadline(t *testing.T) {
+	namespace := "test-namespace"
+	app := buildApplication(namespace, "test-app")
+	cluster := buildCluster("minikube")
+
+	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
+	f.cycles = 1
+
+	buildStuckContender(f, namespace, 600, time.Minute)
+
+	f.expectedEvents = []string{
+		"Normal ReleaseConditionChanged [] -> [Scheduled True], [] -> [StrategyExecuted True]",
+	}
+
+	f.run()
+}
+
 func TestContenderReleaseIsInstalled(t *testing.T) {
 	namespace := "test-namespace"
 	incumbentName, contenderName := "test-incumbent", "test-contender"
@@ -2247,7 +2960,7 @@ func TestContenderReleaseIsInstalled(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -2286,7 +2999,7 @@ func TestApplicationExposesStrategyFailureIndexOutOfBounds(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(1)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -2368,7 +3081,7 @@ func TestApplicationExposesStrategyFailureSuccessorIndexOutOfBounds(t *testing.T
 	// to be issued independently
 	f.cycles = 2
 
-	totalReplicaCount := int32(1)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -2487,7 +3200,7 @@ func TestWaitingOnContenderCapacityProducesNoPatches(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -2533,7 +3246,7 @@ func TestWaitingOnContenderTrafficProducesNoPatches(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -2589,7 +3302,7 @@ func TestWaitingOnIncumbentTrafficProducesNoPatches(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -2647,7 +3360,7 @@ func TestWaitingOnIncumbentCapacityProducesNoPatches(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -2786,9 +3499,10 @@ func TestControllerChooseClustersSkipsUnschedulable(t *testing.T) {
 	relKey := fmt.Sprintf("%s/%s", contender.GetNamespace(), contender.GetName())
 	f.expectedEvents = append(f.expectedEvents,
 		fmt.Sprintf(
-			"Normal ClustersSelected Set clusters for \"%s\" to %s",
+			"Normal ClustersSelected Set clusters for \"%s\" to %s; skipped: %s: unschedulable",
 			relKey,
 			clusterA.Name,
+			clusterB.Name,
 		),
 		fmt.Sprintf(
 			"Normal ReleaseScheduled Created InstallationTarget \"%s\"",
@@ -2817,7 +3531,7 @@ func TestIncumbentOutOfRangeTargetStep(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 2
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -2865,7 +3579,7 @@ func TestUnhealthyTrafficAndCapacityIncumbentConvergesConsistently(t *testing.T)
 	brokenCluster := buildCluster("broken-cluster")
 
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
-	replicaCount := int32(4)
+	replicaCount := int32(12)
 
 	contender := f.buildContender(namespace, contenderName, replicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, replicaCount)
@@ -2935,7 +3649,7 @@ func TestUnhealthyTrafficAndCapacityIncumbentConvergesConsistently(t *testing.T)
 	incumbent.capacityTarget.Spec.Clusters[0].TotalReplicaCount = replicaCount
 	incumbent.capacityTarget.Spec.Clusters[1].Name = "minikube"
 	incumbent.capacityTarget.Spec.Clusters[1].Percent = 0
-	incumbent.capacityTarget.Spec.Clusters[1].TotalReplicaCount = 0
+	incumbent.capacityTarget.Spec.Clusters[1].TotalReplicaCount = replicaCount
 	incumbent.capacityTarget.Status.Conditions, _ = targetutil.SetTargetCondition(
 		incumbent.capacityTarget.Status.Conditions,
 		targetutil.NewTargetCondition(
@@ -3041,7 +3755,7 @@ func TestControllerDetectInconsistentTargetStep(t *testing.T) {
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
 	f.cycles = 1
 
-	totalReplicaCount := int32(10)
+	totalReplicaCount := int32(12)
 	contender := f.buildContender(namespace, contenderName, totalReplicaCount)
 	incumbent := f.buildIncumbent(namespace, incumbentName, totalReplicaCount)
 
@@ -3087,7 +3801,7 @@ func TestControllerDetectInconsistentTargetStep(t *testing.T) {
 func TestUpdatesHistoricalReleaseStrategyStateConditions(t *testing.T) {
 	namespace := "test-namespace"
 	app := buildApplication(namespace, "test-app")
-	totalReplicaCount := int32(4)
+	totalReplicaCount := int32(12)
 
 	cluster := buildCluster("minikube")
 	f := newFixture(t, app.DeepCopy(), cluster.DeepCopy())
@@ -20,7 +20,10 @@ import (
 	listers "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
 	"github.com/bookingcom/shipper/pkg/controller"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/util/clusterstatus"
 	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+	"github.com/bookingcom/shipper/pkg/util/replicas"
+	"github.com/bookingcom/shipper/pkg/util/resources"
 )
 
 type Scheduler struct {
@@ -77,15 +80,85 @@ func (s *Scheduler) ChooseClusters(rel *shipper.Release) (*shipper.Release, erro
 			"", selector, err)
 	}
 
-	selectedClusters, err := computeTargetClusters(rel, allClusters)
+	required, err := s.fetchChartAndComputeRequests(rel)
 	if err != nil {
 		return nil, err
 	}
+
+	selectedClusters, skipped, err := computeTargetClusters(rel, allClusters, required)
+	if err != nil {
+		if len(skipped) > 0 {
+			s.recorder.Eventf(
+				rel,
+				corev1.EventTypeWarning,
+				"ClustersSkipped",
+				"Could not choose clusters for %q; skipped: %s",
+				metaKey,
+				strings.Join(skipped, "; "),
+			)
+		}
+		return nil, err
+	}
 	setReleaseClusters(rel, selectedClusters)
 
+	msg := fmt.Sprintf("Set clusters for %q to %v", metaKey, rel.Annotations[shipper.ReleaseClustersAnnotation])
+	if len(required) > 0 {
+		msg = fmt.Sprintf("%s, fitting requests %s", msg, resources.String(required))
+	}
+	if len(skipped) > 0 {
+		msg = fmt.Sprintf("%s; skipped: %s", msg, strings.Join(skipped, "; "))
+	}
+	s.recorder.Event(rel, corev1.EventTypeNormal, "ClustersSelected", msg)
+
 	return rel, nil
 }
 
+// RebalancedClusters returns the clusters a release would be scheduled on if
+// it was created now. Clusters the release is already on are assumed to have
+// room for it, as its own pods take up part of their capacity, and to be in
+// service, as a cluster failing health checks is no reason to roll out again.
+func (s *Scheduler) RebalancedClusters(rel *shipper.Release) ([]string, error) {
+	selector := labels.Everything()
+	allClusters, err := s.clusterLister.List(selector)
+	if err != nil {
+		return nil, shippererrors.NewKubeclientListError(
+			shipper.SchemeGroupVersion.WithKind("Cluster"),
+			"", selector, err)
+	}
+
+	required, err := s.fetchChartAndComputeRequests(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	current := map[string]bool{}
+	for _, name := range getReleaseClusters(rel) {
+		current[name] = true
+	}
+
+	clusters := make([]*shipper.Cluster, 0, len(allClusters))
+	for _, cluster := range allClusters {
+		if current[cluster.Name] {
+			cluster = cluster.DeepCopy()
+			cluster.Status.Capacity = nil
+			cluster.Status.InService = true
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	selectedClusters, _, err := computeTargetClusters(rel, clusters, required)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(selectedClusters))
+	for _, cluster := range selectedClusters {
+		names = append(names, cluster.Name)
+	}
+
+	return names, nil
+}
+
 func (s *Scheduler) ScheduleRelease(rel *shipper.Release) (*releaseInfo, error) {
 	metaKey := controller.MetaKey(rel)
 	klog.V(4).Infof("Processing release %q", metaKey)
@@ -97,14 +170,6 @@ func (s *Scheduler) ScheduleRelease(rel *shipper.Release) (*releaseInfo, error)
 		if err != nil {
 			return nil, err
 		}
-		s.recorder.Eventf(
-			rel,
-			corev1.EventTypeNormal,
-			"ClustersSelected",
-			"Set clusters for %q to %v",
-			controller.MetaKey(rel),
-			rel.Annotations[shipper.ReleaseClustersAnnotation],
-		)
 	}
 
 	replicaCount, err := s.fetchChartAndExtractReplicaCount(rel)
@@ -199,6 +264,16 @@ func capacityTargetClustersMatch(ct *shipper.CapacityTarget, clusters []string)
 	return stringSliceEqual(clusters, ctClusters)
 }
 
+func capacityTargetReplicaCountsMatch(ct *shipper.CapacityTarget, replicaCounts map[string]int32) bool {
+	for _, ctc := range ct.Spec.Clusters {
+		if ctc.TotalReplicaCount != replicaCounts[ctc.Name] {
+			return false
+		}
+	}
+
+	return true
+}
+
 func trafficTargetClustersMatch(tt *shipper.TrafficTarget, clusters []string) bool {
 	ttClusters := make([]string, 0, len(tt.Spec.Clusters))
 	for _, ttc := range tt.Spec.Clusters {
@@ -213,7 +288,79 @@ func setInstallationTargetClusters(it *shipper.InstallationTarget, clusters []st
 	it.Spec.Clusters = clusters
 }
 
-func setCapacityTargetClusters(ct *shipper.CapacityTarget, clusters []string, totalReplicaCount int32) {
+// clusterReplicaCounts returns the total number of replicas of a release in
+// each of its clusters. Every cluster gets the number of replicas in the
+// chart, unless the requirements of its region ask for the replicas of the
+// region to be split between its clusters.
+func (s *Scheduler) clusterReplicaCounts(rel *shipper.Release, clusters []string, chartReplicaCount int32) (map[string]int32, error) {
+	replicaCounts := make(map[string]int32, len(clusters))
+	for _, cluster := range clusters {
+		replicaCounts[cluster] = chartReplicaCount
+	}
+
+	distributions := map[string]*shipper.ReplicaDistribution{}
+	for _, region := range rel.Spec.Environment.ClusterRequirements.Regions {
+		distribution := region.ReplicaDistribution
+		if distribution != nil && distribution.Mode != shipper.ReplicaDistributionModeEven {
+			distributions[region.Name] = distribution
+		}
+	}
+
+	if len(distributions) == 0 {
+		return replicaCounts, nil
+	}
+
+	clustersByRegion := map[string][]*shipper.Cluster{}
+	for _, name := range clusters {
+		cluster, err := s.clusterLister.Get(name)
+		if errors.IsNotFound(err) {
+			// Clusters that are gone keep the replicas of
+			// the chart until the release is rescheduled.
+			continue
+		} else if err != nil {
+			return nil, shippererrors.NewKubeclientGetError("", name, err).
+				WithShipperKind("Cluster")
+		}
+
+		clustersByRegion[cluster.Spec.Region] = append(clustersByRegion[cluster.Spec.Region], cluster)
+	}
+
+	for region, distribution := range distributions {
+		regionClusters := clustersByRegion[region]
+		weights := make([]int32, 0, len(regionClusters))
+		for _, cluster := range regionClusters {
+			weights = append(weights, replicaDistributionWeight(distribution, cluster))
+		}
+
+		regionReplicaCount := chartReplicaCount * int32(len(regionClusters))
+		for i, count := range replicas.DistributeReplicas(regionReplicaCount, weights) {
+			replicaCounts[regionClusters[i].Name] = count
+		}
+	}
+
+	return replicaCounts, nil
+}
+
+// replicaDistributionWeight returns the share of the replicas of its region
+// a cluster should get.
+func replicaDistributionWeight(distribution *shipper.ReplicaDistribution, cluster *shipper.Cluster) int32 {
+	switch distribution.Mode {
+	case shipper.ReplicaDistributionModeWeight:
+		if cluster.Spec.Scheduler.Weight == nil {
+			return defaultClusterWeight
+		}
+		return *cluster.Spec.Scheduler.Weight
+	case shipper.ReplicaDistributionModeRatio:
+		if ratio, ok := distribution.Ratios[cluster.Name]; ok {
+			return ratio
+		}
+		return 1
+	default:
+		return 1
+	}
+}
+
+func setCapacityTargetClusters(ct *shipper.CapacityTarget, clusters []string, replicaCounts map[string]int32) {
 	capacityTargetClusters := make([]shipper.ClusterCapacityTarget, 0, len(clusters))
 	for _, cluster := range clusters {
 		capacityTargetClusters = append(
@@ -221,7 +368,7 @@ func setCapacityTargetClusters(ct *shipper.CapacityTarget, clusters []string, to
 			shipper.ClusterCapacityTarget{
 				Name:              cluster,
 				Percent:           0,
-				TotalReplicaCount: totalReplicaCount,
+				TotalReplicaCount: replicaCounts[cluster],
 			})
 	}
 	ct.Spec.Clusters = capacityTargetClusters
@@ -316,6 +463,11 @@ func (s *Scheduler) CreateOrUpdateInstallationTarget(rel *shipper.Release) (*shi
 func (s *Scheduler) CreateOrUpdateCapacityTarget(rel *shipper.Release, totalReplicaCount int32) (*shipper.CapacityTarget, error) {
 	clusters := getReleaseClusters(rel)
 
+	replicaCounts, err := s.clusterReplicaCounts(rel, clusters, totalReplicaCount)
+	if err != nil {
+		return nil, err
+	}
+
 	ct, err := s.capacityTargetLister.CapacityTargets(rel.GetNamespace()).Get(rel.GetName())
 	if err != nil {
 		if !errors.IsNotFound(err) {
@@ -334,7 +486,7 @@ func (s *Scheduler) CreateOrUpdateCapacityTarget(rel *shipper.Release, totalRepl
 				},
 			},
 		}
-		setCapacityTargetClusters(ct, clusters, totalReplicaCount)
+		setCapacityTargetClusters(ct, clusters, replicaCounts)
 
 		updCt, err := s.clientset.ShipperV1alpha1().CapacityTargets(rel.GetNamespace()).Create(ct)
 		if err != nil {
@@ -360,7 +512,7 @@ func (s *Scheduler) CreateOrUpdateCapacityTarget(rel *shipper.Release, totalRepl
 		klog.V(4).Infof("Updating CapacityTarget %q clusters to %s",
 			controller.MetaKey(ct),
 			strings.Join(clusters, ","))
-		setCapacityTargetClusters(ct, clusters, totalReplicaCount)
+		setCapacityTargetClusters(ct, clusters, replicaCounts)
 		updCt, err := s.clientset.ShipperV1alpha1().CapacityTargets(rel.GetNamespace()).Update(ct)
 		if err != nil {
 			klog.Errorf("Failed to update CapacityTarget %q clusters: %s",
@@ -378,6 +530,30 @@ func (s *Scheduler) CreateOrUpdateCapacityTarget(rel *shipper.Release, totalRepl
 		return updCt, nil
 	}
 
+	// Cluster weights and replica ratios can change without the set of
+	// clusters changing, which only changes how many replicas each of
+	// them should end up with.
+	if !capacityTargetReplicaCountsMatch(ct, replicaCounts) {
+		ct = ct.DeepCopy()
+		for i := range ct.Spec.Clusters {
+			ct.Spec.Clusters[i].TotalReplicaCount = replicaCounts[ct.Spec.Clusters[i].Name]
+		}
+
+		klog.V(4).Infof("Updating CapacityTarget %q replica counts", controller.MetaKey(ct))
+		updCt, err := s.clientset.ShipperV1alpha1().CapacityTargets(rel.GetNamespace()).Update(ct)
+		if err != nil {
+			return nil, shippererrors.NewKubeclientUpdateError(ct, err).
+				WithShipperKind("CapacityTarget")
+		}
+		s.recorder.Eventf(
+			rel,
+			corev1.EventTypeNormal,
+			"ReleaseScheduled",
+			"Updated CapacityTarget %q replica counts",
+			controller.MetaKey(updCt))
+		return updCt, nil
+	}
+
 	return ct, nil
 }
 
@@ -401,6 +577,9 @@ func (s *Scheduler) CreateOrUpdateTrafficTarget(rel *shipper.Release) (*shipper.
 					createOwnerRefFromRelease(rel),
 				},
 			},
+			Spec: shipper.TrafficTargetSpec{
+				Backend: rel.Spec.Environment.TrafficBackend,
+			},
 		}
 		setTrafficTargetClusters(tt, clusters)
 
@@ -450,28 +629,37 @@ func (s *Scheduler) CreateOrUpdateTrafficTarget(rel *shipper.Release) (*shipper.
 }
 
 // computeTargetClusters picks out the clusters from the given list which match
-// the release's clusterRequirements.
-func computeTargetClusters(rel *shipper.Release, clusterList []*shipper.Cluster) ([]*shipper.Cluster, error) {
+// the release's clusterRequirements and have enough room for the resources it
+// requires. It also returns why the clusters in the requested regions that
+// could not be picked were skipped.
+func computeTargetClusters(rel *shipper.Release, clusterList []*shipper.Cluster, required corev1.ResourceList) ([]*shipper.Cluster, []string, error) {
 	regionSpecs := rel.Spec.Environment.ClusterRequirements.Regions
 	requiredCapabilities := rel.Spec.Environment.ClusterRequirements.Capabilities
 	capableClustersByRegion := map[string][]*shipper.Cluster{}
 	regionReplicas := map[string]int{}
+	regionZones := map[string]int{}
+	skipped := []string{}
 
 	if len(regionSpecs) == 0 {
-		return nil, shippererrors.NewNoRegionsSpecifiedError()
+		return nil, nil, shippererrors.NewNoRegionsSpecifiedError()
 	}
 
 	app, err := releaseutil.ApplicationNameForRelease(rel)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	err = validateClusterRequirements(rel.Spec.Environment.ClusterRequirements)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	prefList := buildPrefList(app, clusterList)
+	prefList, err = sortByAffinity(rel.Spec.Environment.ClusterRequirements, prefList)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// This algo could probably build up hashes instead of doing linear searches,
 	// but these data sets are so tiny (1-20 items) that it'd only be useful for
 	// readability.
@@ -482,39 +670,55 @@ func computeTargetClusters(rel *shipper.Release, clusterList []*shipper.Cluster)
 		} else {
 			regionReplicas[region.Name] = int(*region.Replicas)
 		}
+		if region.Zones != nil {
+			regionZones[region.Name] = int(*region.Zones)
+		}
 
 		matchedRegion := 0
 		for _, cluster := range prefList {
-			if cluster.Spec.Scheduler.Unschedulable {
+			if cluster.Spec.Region != region.Name {
+				continue
+			}
+
+			if reason, ok := clusterCanTakeRelease(cluster, required); !ok {
+				skipped = append(skipped, fmt.Sprintf("%s: %s", cluster.Name, reason))
+				continue
+			}
+
+			reason, ok, err := releaseutil.ClusterMatchesAffinity(rel.Spec.Environment.ClusterRequirements, cluster)
+			if err != nil {
+				return nil, nil, shippererrors.NewUnrecoverableError(err)
+			} else if !ok {
+				skipped = append(skipped, fmt.Sprintf("%s: %s", cluster.Name, reason))
 				continue
 			}
 
-			if cluster.Spec.Region == region.Name {
-				matchedRegion++
-				capabilityMatch := 0
-				for _, requiredCapability := range requiredCapabilities {
-					for _, providedCapability := range cluster.Spec.Capabilities {
-						if requiredCapability == providedCapability {
-							capabilityMatch++
-							break
-						}
+			matchedRegion++
+			capabilityMatch := 0
+			for _, requiredCapability := range requiredCapabilities {
+				for _, providedCapability := range cluster.Spec.Capabilities {
+					if requiredCapability == providedCapability {
+						capabilityMatch++
+						break
 					}
 				}
+			}
 
-				if capabilityMatch == len(requiredCapabilities) {
-					capableClustersByRegion[region.Name] = append(capableClustersByRegion[region.Name], cluster)
-				}
+			if capabilityMatch == len(requiredCapabilities) {
+				capableClustersByRegion[region.Name] = append(capableClustersByRegion[region.Name], cluster)
+			} else {
+				skipped = append(skipped, fmt.Sprintf("%s: missing capabilities", cluster.Name))
 			}
 		}
 		if regionReplicas[region.Name] > matchedRegion {
-			return nil, shippererrors.NewNotEnoughClustersInRegionError(region.Name, regionReplicas[region.Name], matchedRegion)
+			return nil, skipped, shippererrors.NewNotEnoughClustersInRegionError(region.Name, regionReplicas[region.Name], matchedRegion)
 		}
 	}
 
 	resClusters := make([]*shipper.Cluster, 0)
 	for region, clusters := range capableClustersByRegion {
 		if regionReplicas[region] > len(clusters) {
-			return nil, shippererrors.NewNotEnoughCapableClustersInRegionError(
+			return nil, skipped, shippererrors.NewNotEnoughCapableClustersInRegionError(
 				region,
 				requiredCapabilities,
 				regionReplicas[region],
@@ -522,23 +726,52 @@ func computeTargetClusters(rel *shipper.Release, clusterList []*shipper.Cluster)
 			)
 		}
 
+		if available := countZones(clusters); regionZones[region] > available {
+			return nil, skipped, shippererrors.NewNotEnoughZonesInRegionError(
+				region,
+				regionZones[region],
+				available,
+			)
+		}
+
 		//NOTE(btyler): this assumes we do not have duplicate cluster names. For the
 		//moment cluster objects are cluster scoped; if they become namespace scoped
 		//and releases can somehow be scheduled to clusters from multiple namespaces,
 		//this assumption will be wrong.
-		for _, cluster := range clusters {
-			if regionReplicas[region] > 0 {
-				regionReplicas[region]--
-				resClusters = append(resClusters, cluster)
-			}
-		}
+		resClusters = append(resClusters, spreadOverZones(clusters, regionReplicas[region], regionZones[region])...)
 	}
 
 	sort.Slice(resClusters, func(i, j int) bool {
 		return resClusters[i].Name < resClusters[j].Name
 	})
 
-	return resClusters, nil
+	return resClusters, skipped, nil
+}
+
+// clusterCanTakeRelease returns whether new releases requiring the given
+// resources can be scheduled on a cluster, and why not if they can't.
+// Clusters whose capacity is unknown are assumed to have room for them.
+func clusterCanTakeRelease(cluster *shipper.Cluster, required corev1.ResourceList) (string, bool) {
+	if cluster.Spec.Scheduler.Unschedulable {
+		return "unschedulable", false
+	}
+
+	if !clusterstatus.IsClusterInService(cluster) {
+		return "not in service", false
+	}
+
+	capacity := cluster.Status.Capacity
+	if capacity == nil || len(required) == 0 {
+		return "", true
+	}
+
+	available := resources.Available(capacity.Allocatable, capacity.Requested)
+	if insufficient := resources.Insufficient(required, available); len(insufficient) > 0 {
+		return fmt.Sprintf("not enough %v available (requires %s, has %s)",
+			insufficient, resources.String(required), resources.String(available)), false
+	}
+
+	return "", true
 }
 
 func validateClusterRequirements(requirements shipper.ClusterRequirements) error {
@@ -554,9 +787,141 @@ func validateClusterRequirements(requirements shipper.ClusterRequirements) error
 		seenCapabilities[capability] = struct{}{}
 	}
 
+	if err := releaseutil.ValidateClusterAffinity(requirements); err != nil {
+		return shippererrors.NewUnrecoverableError(err)
+	}
+
+	for _, region := range requirements.Regions {
+		if err := validateReplicaDistribution(region); err != nil {
+			return shippererrors.NewUnrecoverableError(err)
+		}
+	}
+
+	for _, region := range requirements.Regions {
+		if region.Zones == nil {
+			continue
+		}
+
+		replicas := 1
+		if region.Replicas != nil {
+			replicas = int(*region.Replicas)
+		}
+
+		if int(*region.Zones) > replicas {
+			return shippererrors.NewInvalidZoneSpreadError(region.Name, int(*region.Zones), replicas)
+		}
+	}
+
 	return nil
 }
 
+// sortByAffinity moves the clusters a release prefers towards the front of
+// prefList, and the ones it prefers to avoid towards its end. Clusters the
+// release has no preference for keep their relative order, so releases
+// without preferences are scheduled exactly like before.
+func sortByAffinity(requirements shipper.ClusterRequirements, prefList []*shipper.Cluster) ([]*shipper.Cluster, error) {
+	scores := make(map[string]int, len(prefList))
+	for _, cluster := range prefList {
+		score, err := releaseutil.ClusterAffinityScore(requirements, cluster)
+		if err != nil {
+			return nil, shippererrors.NewUnrecoverableError(err)
+		}
+		scores[cluster.Name] = score
+	}
+
+	sort.SliceStable(prefList, func(i, j int) bool {
+		return scores[prefList[i].Name] > scores[prefList[j].Name]
+	})
+
+	return prefList, nil
+}
+
+func validateReplicaDistribution(region shipper.RegionRequirement) error {
+	distribution := region.ReplicaDistribution
+	if distribution == nil {
+		return nil
+	}
+
+	switch distribution.Mode {
+	case shipper.ReplicaDistributionModeEven, shipper.ReplicaDistributionModeWeight:
+		if len(distribution.Ratios) > 0 {
+			return fmt.Errorf("replica distribution of region %q can only have ratios in %q mode",
+				region.Name, shipper.ReplicaDistributionModeRatio)
+		}
+	case shipper.ReplicaDistributionModeRatio:
+		for cluster, ratio := range distribution.Ratios {
+			if ratio < 0 {
+				return fmt.Errorf("replica distribution of region %q has negative ratio %d for cluster %q",
+					region.Name, ratio, cluster)
+			}
+		}
+	default:
+		return fmt.Errorf("replica distribution of region %q has unknown mode %q, must be one of %q, %q or %q",
+			region.Name, distribution.Mode, shipper.ReplicaDistributionModeEven,
+			shipper.ReplicaDistributionModeWeight, shipper.ReplicaDistributionModeRatio)
+	}
+
+	return nil
+}
+
+// countZones returns the number of distinct zones clusters are in. Clusters
+// without a zone are not counted.
+func countZones(clusters []*shipper.Cluster) int {
+	zones := map[string]struct{}{}
+	for _, cluster := range clusters {
+		if cluster.Spec.Zone != "" {
+			zones[cluster.Spec.Zone] = struct{}{}
+		}
+	}
+
+	return len(zones)
+}
+
+// spreadOverZones picks n clusters from prefList, spanning at least the given
+// number of zones. The first cluster of each zone is picked, in preference
+// order, until enough zones are covered; the rest is filled in preference
+// order. When no spread is required, this is exactly the first n clusters
+// of prefList, so adding zones to a fleet does not move releases around more
+// than needed.
+func spreadOverZones(prefList []*shipper.Cluster, n, zones int) []*shipper.Cluster {
+	picked := make([]*shipper.Cluster, 0, n)
+	pickedNames := map[string]struct{}{}
+	pickedZones := map[string]struct{}{}
+
+	for _, cluster := range prefList {
+		if len(pickedZones) >= zones || len(picked) >= n {
+			break
+		}
+
+		zone := cluster.Spec.Zone
+		if zone == "" {
+			continue
+		}
+
+		if _, ok := pickedZones[zone]; ok {
+			continue
+		}
+
+		pickedZones[zone] = struct{}{}
+		pickedNames[cluster.Name] = struct{}{}
+		picked = append(picked, cluster)
+	}
+
+	for _, cluster := range prefList {
+		if len(picked) >= n {
+			break
+		}
+
+		if _, ok := pickedNames[cluster.Name]; ok {
+			continue
+		}
+
+		picked = append(picked, cluster)
+	}
+
+	return picked
+}
+
 func setReleaseClusters(rel *shipper.Release, clusters []*shipper.Cluster) {
 	clusterNames := make([]string, 0, len(clusters))
 	memo := make(map[string]struct{})
@@ -587,37 +952,90 @@ func (s *Scheduler) fetchChartAndExtractReplicaCount(rel *shipper.Release) (int3
 	return int32(replicas), nil
 }
 
+// fetchChartAndComputeRequests returns the resources requested by all the
+// pods of a release's Deployment or StatefulSet in a single cluster.
+func (s *Scheduler) fetchChartAndComputeRequests(rel *shipper.Release) (corev1.ResourceList, error) {
+	chart, err := s.chartFetcher(&rel.Spec.Environment.Chart)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := renderWorkloadForRel(chart, rel)
+	if err != nil {
+		return nil, err
+	}
+
+	podRequests := resources.Scheduling(resources.PodRequests(workload.template.Spec))
+	requests := resources.Multiply(podRequests, workload.replicaCount())
+
+	klog.V(4).Infof("Release %q requests %s", controller.MetaKey(rel), resources.String(requests))
+
+	return requests, nil
+}
+
 func extractReplicasFromChartForRel(chart *helmchart.Chart, rel *shipper.Release) (int32, error) {
+	workload, err := renderWorkloadForRel(chart, rel)
+	if err != nil {
+		return 0, err
+	}
+
+	return workload.replicaCount(), nil
+}
+
+// workload is what the scheduler needs to know about the Deployment or
+// StatefulSet running the pods of a release.
+type workload struct {
+	replicas *int32
+	template corev1.PodTemplateSpec
+}
+
+// replicaCount returns the number of replicas of a workload. Both
+// Deployments and StatefulSets default to 1 replica when replicas is nil or
+// unspecified. See k8s.io/api/apps/v1/types.go's DeploymentSpec and
+// StatefulSetSpec.
+func (w workload) replicaCount() int32 {
+	if w.replicas == nil {
+		return 1
+	}
+
+	return *w.replicas
+}
+
+func renderWorkloadForRel(chart *helmchart.Chart, rel *shipper.Release) (*workload, error) {
 	owners := rel.OwnerReferences
 	if l := len(owners); l != 1 {
-		return 0, shippererrors.NewMultipleOwnerReferencesError(rel.Name, l)
+		return nil, shippererrors.NewMultipleOwnerReferencesError(rel.Name, l)
 	}
 
 	applicationName := owners[0].Name
 	rendered, err := shipperchart.Render(chart, applicationName, rel.Namespace, rel.Spec.Environment.Values)
 	if err != nil {
-		return 0, shippererrors.NewBrokenChartSpecError(
+		return nil, shippererrors.NewBrokenChartSpecError(
 			&rel.Spec.Environment.Chart,
 			err,
 		)
 	}
 
 	deployments := shipperchart.GetDeployments(rendered)
-	if len(deployments) != 1 {
-		return 0, shippererrors.NewWrongChartDeploymentsError(
+	statefulSets := shipperchart.GetStatefulSets(rendered)
+	if len(deployments)+len(statefulSets) != 1 {
+		return nil, shippererrors.NewWrongChartDeploymentsError(
 			&rel.Spec.Environment.Chart,
-			len(deployments),
+			len(deployments)+len(statefulSets),
 		)
 	}
 
-	replicas := deployments[0].Spec.Replicas
-	// Deployments default to 1 replica when replicas is nil or unspecified. See
-	// k8s.io/api/apps/v1/types.go's DeploymentSpec.
-	if replicas == nil {
-		return 1, nil
+	if len(statefulSets) == 1 {
+		return &workload{
+			replicas: statefulSets[0].Spec.Replicas,
+			template: statefulSets[0].Spec.Template,
+		}, nil
 	}
 
-	return int32(*replicas), nil
+	return &workload{
+		replicas: deployments[0].Spec.Replicas,
+		template: deployments[0].Spec.Template,
+	}, nil
 }
 
 // The strings here are insane, but if you create a fresh release object for
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	kubetesting "k8s.io/client-go/testing"
@@ -282,7 +283,7 @@ func computeClusterTestCase(
 		clusters = append(clusters, generateClusterForTestCase(i, spec))
 	}
 
-	actualClusters, err := computeTargetClusters(release, clusters)
+	actualClusters, _, err := computeTargetClusters(release, clusters, nil)
 	if expectError {
 		if err == nil {
 			t.Errorf("test %q expected an error but didn't get one!", name)
@@ -405,6 +406,99 @@ func TestScheduleSkipsUnschedulable(t *testing.T) {
 	}
 }
 
+// TestScheduleSkipsClustersNotInService tests that clusters that failed their
+// last health check are not chosen for new releases, while clusters that were
+// never probed still are.
+func TestScheduleSkipsClustersNotInService(t *testing.T) {
+	clusterA := buildCluster("minikube-a")
+	clusterB := buildCluster("minikube-b")
+	clusterB.Status.Conditions = []shipper.ClusterCondition{
+		{
+			Type:   shipper.ClusterConditionTypeReachable,
+			Status: corev1.ConditionFalse,
+			Reason: "Unreachable",
+		},
+	}
+	clusterB.Status.InService = false
+	release := buildRelease()
+	fixtures := []runtime.Object{clusterA, clusterB, release}
+
+	c, _ := newScheduler(fixtures)
+
+	got, err := c.ChooseClusters(release.DeepCopy())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if clusters := got.Annotations[shipper.ReleaseClustersAnnotation]; clusters != clusterA.GetName() {
+		t.Errorf("expected release to have clusters %q, got %q", clusterA.GetName(), clusters)
+	}
+}
+
+// TestRebalancedClustersMovesAwayFromDrainedCluster checks that a release
+// scheduled on a cluster that has since been marked as unschedulable would be
+// moved to another cluster in the same region.
+func TestRebalancedClustersMovesAwayFromDrainedCluster(t *testing.T) {
+	clusterA := buildCluster("minikube-a")
+	clusterA.Spec.Scheduler.Unschedulable = true
+	clusterB := buildCluster("minikube-b")
+	release := buildRelease()
+	release.Annotations[shipper.ReleaseClustersAnnotation] = clusterA.GetName()
+	fixtures := []runtime.Object{clusterA, clusterB, release}
+
+	c, _ := newScheduler(fixtures)
+
+	clusters, err := c.RebalancedClusters(release.DeepCopy())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(clusters) != 1 || clusters[0] != clusterB.GetName() {
+		t.Errorf("expected release to be rebalanced to %q, got %v", clusterB.GetName(), clusters)
+	}
+}
+
+// TestRebalancedClustersIgnoresHealthOfCurrentClusters checks that a release
+// is not moved away from a cluster only because it is out of service, as a
+// failed health check would otherwise roll out every release on it again.
+func TestRebalancedClustersIgnoresHealthOfCurrentClusters(t *testing.T) {
+	clusterA := buildCluster("minikube-a")
+	clusterB := buildCluster("minikube-b")
+	release := buildRelease()
+
+	c, _ := newScheduler([]runtime.Object{clusterA, clusterB, release})
+	scheduled, err := c.ChooseClusters(release.DeepCopy())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current := scheduled.Annotations[shipper.ReleaseClustersAnnotation]
+	for _, cluster := range []*shipper.Cluster{clusterA, clusterB} {
+		if cluster.Name != current {
+			continue
+		}
+
+		cluster.Status.Conditions = []shipper.ClusterCondition{
+			{
+				Type:   shipper.ClusterConditionTypeReachable,
+				Status: corev1.ConditionFalse,
+				Reason: "Unreachable",
+			},
+		}
+		cluster.Status.InService = false
+	}
+
+	c, _ = newScheduler([]runtime.Object{clusterA, clusterB, scheduled})
+	clusters, err := c.RebalancedClusters(scheduled.DeepCopy())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(clusters) != 1 || clusters[0] != current {
+		t.Errorf("expected release to stay on %q, got %v", current, clusters)
+	}
+}
+
 // TestCreateAssociatedObjects checks whether the associated object set is being
 // created while a release is being scheduled. In a normal case scenario, all 3
 // objects do not exist by the moment of scheduling, therefore 3 extra create
@@ -832,7 +926,7 @@ func TestCreateAssociatedObjectsDuplicateCapacityTargetSameOwner(t *testing.T) {
 	release := buildRelease()
 	release.Annotations[shipper.ReleaseClustersAnnotation] = cluster.GetName()
 
-	var totalReplicaCount int32 = 1
+	var totalReplicaCount int32 = 12
 
 	capacitytarget := &shipper.CapacityTarget{
 		ObjectMeta: metav1.ObjectMeta{
@@ -843,7 +937,7 @@ func TestCreateAssociatedObjectsDuplicateCapacityTargetSameOwner(t *testing.T) {
 			},
 		},
 	}
-	setCapacityTargetClusters(capacitytarget, []string{cluster.Name}, totalReplicaCount)
+	setCapacityTargetClusters(capacitytarget, []string{cluster.Name}, map[string]int32{cluster.Name: totalReplicaCount})
 	fixtures := []runtime.Object{cluster, release, capacitytarget}
 
 	// Expected release and actions. Even with an existing capacitytarget object
@@ -919,6 +1013,92 @@ func TestCreateAssociatedObjectsDuplicateCapacityTargetNoOwner(t *testing.T) {
 	}
 }
 
+// TestCreateCapacityTargetDistributesReplicasByWeight checks that a region
+// asking for its replicas to be split by cluster weight gets capacity target
+// totals in proportion to the weight of its clusters, adding up to what every
+// cluster would have had otherwise.
+func TestCreateCapacityTargetDistributesReplicasByWeight(t *testing.T) {
+	small := buildCluster("minikube-a")
+	small.Spec.Scheduler.Weight = pint32(100)
+	large := buildCluster("minikube-b")
+	large.Spec.Scheduler.Weight = pint32(300)
+
+	release := buildRelease()
+	release.Spec.Environment.ClusterRequirements.Regions[0].ReplicaDistribution = &shipper.ReplicaDistribution{
+		Mode: shipper.ReplicaDistributionModeWeight,
+	}
+	release.Annotations[shipper.ReleaseClustersAnnotation] = strings.Join([]string{small.Name, large.Name}, ",")
+	fixtures := []runtime.Object{small, large, release}
+
+	c, _ := newScheduler(fixtures)
+
+	ct, err := c.CreateOrUpdateCapacityTarget(release.DeepCopy(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int32{small.Name: 5, large.Name: 15}
+	for _, spec := range ct.Spec.Clusters {
+		if spec.TotalReplicaCount != expected[spec.Name] {
+			t.Errorf("expected %d total replicas in cluster %q, got %d",
+				expected[spec.Name], spec.Name, spec.TotalReplicaCount)
+		}
+	}
+}
+
+// TestUpdateCapacityTargetReplicaCounts checks that changes to cluster
+// weights reach the capacity target of a release already scheduled on them,
+// without touching the capacity it should have at its current step.
+func TestUpdateCapacityTargetReplicaCounts(t *testing.T) {
+	small := buildCluster("minikube-a")
+	small.Spec.Scheduler.Weight = pint32(100)
+	large := buildCluster("minikube-b")
+	large.Spec.Scheduler.Weight = pint32(300)
+
+	release := buildRelease()
+	release.Spec.Environment.ClusterRequirements.Regions[0].ReplicaDistribution = &shipper.ReplicaDistribution{
+		Mode: shipper.ReplicaDistributionModeWeight,
+	}
+	release.Annotations[shipper.ReleaseClustersAnnotation] = strings.Join([]string{small.Name, large.Name}, ",")
+
+	// The capacity target still has the totals from before the large
+	// cluster was given more weight.
+	capacityTarget := &shipper.CapacityTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      release.Name,
+			Namespace: release.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				createOwnerRefFromRelease(release),
+			},
+		},
+	}
+	setCapacityTargetClusters(capacityTarget, []string{small.Name, large.Name},
+		map[string]int32{small.Name: 10, large.Name: 10})
+	for i := range capacityTarget.Spec.Clusters {
+		capacityTarget.Spec.Clusters[i].Percent = 50
+	}
+
+	c, _ := newScheduler([]runtime.Object{small, large, release, capacityTarget})
+
+	ct, err := c.CreateOrUpdateCapacityTarget(release.DeepCopy(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int32{small.Name: 5, large.Name: 15}
+	for _, spec := range ct.Spec.Clusters {
+		if spec.TotalReplicaCount != expected[spec.Name] {
+			t.Errorf("expected %d total replicas in cluster %q, got %d",
+				expected[spec.Name], spec.Name, spec.TotalReplicaCount)
+		}
+
+		if spec.Percent != 50 {
+			t.Errorf("expected cluster %q to keep its capacity of 50%%, got %d%%",
+				spec.Name, spec.Percent)
+		}
+	}
+}
+
 // TestComputeTargetClusters works the core of the scheduler logic: matching
 // regions and capabilities between releases and clusters NOTE: the "expected"
 // clusters are due to the particular prefList outcomes, and as such should be
@@ -1171,4 +1351,175 @@ func TestComputeTargetClusters(t *testing.T) {
 		expected{"cluster-0", "cluster-2"},
 		passingCase,
 	)
+
+	computeClusterTestCase(t, "zone spread passes over clusters in the same zone",
+		requirements{
+			Regions: []shipper.RegionRequirement{
+				{Name: "matches", Replicas: pint32(2), Zones: pint32(2)},
+			},
+		},
+		clusters{
+			// Without the zone spread, cluster-0 and cluster-1 would
+			// be at the front of the preference list.
+			{Region: "matches", Zone: "a"},
+			{Region: "matches", Zone: "a"},
+			{
+				Region:    "matches",
+				Zone:      "b",
+				Scheduler: shipper.ClusterSchedulerSettings{Weight: pint32(0)},
+			},
+		},
+		expected{"cluster-1", "cluster-2"},
+		passingCase,
+	)
+
+	computeClusterTestCase(t, "not enough zones in region",
+		requirements{
+			Regions: []shipper.RegionRequirement{
+				{Name: "matches", Replicas: pint32(2), Zones: pint32(2)},
+			},
+		},
+		clusters{
+			// Clusters without a zone don't count towards the spread.
+			{Region: "matches", Zone: "a"},
+			{Region: "matches", Zone: "a"},
+			{Region: "matches"},
+		},
+		expected{},
+		errorCase,
+	)
+
+	computeClusterTestCase(t, "reject unknown replica distribution mode",
+		requirements{
+			Regions: []shipper.RegionRequirement{
+				{
+					Name:                "matches",
+					ReplicaDistribution: &shipper.ReplicaDistribution{Mode: "random"},
+				},
+			},
+		},
+		clusters{
+			{Region: "matches"},
+		},
+		expected{},
+		errorCase,
+	)
+
+	computeClusterTestCase(t, "reject zone spread wider than replicas",
+		requirements{
+			Regions: []shipper.RegionRequirement{
+				{Name: "matches", Replicas: pint32(1), Zones: pint32(2)},
+			},
+		},
+		clusters{
+			{Region: "matches", Zone: "a"},
+			{Region: "matches", Zone: "b"},
+		},
+		expected{},
+		errorCase,
+	)
+}
+
+// TestComputeTargetClustersSkipsClustersWithoutCapacity checks that clusters
+// that cannot fit the resources a release requires are not picked, and that
+// the reason they were skipped is reported.
+func TestComputeTargetClustersSkipsClustersWithoutCapacity(t *testing.T) {
+	capacity := func(cpu, memory string) *shipper.ClusterCapacity {
+		return &shipper.ClusterCapacity{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("16"),
+				corev1.ResourceMemory: resource.MustParse("64Gi"),
+			},
+			Requested: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		}
+	}
+
+	release := generateReleaseForTestCase(shipper.ClusterRequirements{
+		Regions: []shipper.RegionRequirement{{Name: shippertesting.TestRegion, Replicas: pint32(1)}},
+	})
+
+	full := generateClusterForTestCase(0, shipper.ClusterSpec{Region: shippertesting.TestRegion})
+	full.Status.Capacity = capacity("15", "8Gi")
+	roomy := generateClusterForTestCase(1, shipper.ClusterSpec{Region: shippertesting.TestRegion})
+	roomy.Status.Capacity = capacity("4", "8Gi")
+	unschedulable := generateClusterForTestCase(2, shipper.ClusterSpec{Region: shippertesting.TestRegion})
+	unschedulable.Spec.Scheduler.Unschedulable = true
+
+	required := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("4Gi"),
+	}
+
+	selected, skipped, err := computeTargetClusters(release, []*shipper.Cluster{full, roomy, unschedulable}, required)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(selected) != 1 || selected[0].Name != roomy.Name {
+		t.Errorf("expected only cluster %q to be selected, got %v", roomy.Name, selected)
+	}
+
+	sort.Strings(skipped)
+	expectedSkipped := []string{
+		"cluster-0: not enough [cpu] available (requires cpu=2,memory=4Gi, has cpu=1,memory=56Gi)",
+		"cluster-2: unschedulable",
+	}
+	if strings.Join(skipped, "\n") != strings.Join(expectedSkipped, "\n") {
+		t.Errorf("expected skipped clusters %q, got %q", expectedSkipped, skipped)
+	}
+
+	_, _, err = computeTargetClusters(release, []*shipper.Cluster{full}, required)
+	if _, ok := err.(shippererrors.NotEnoughClustersInRegionError); !ok {
+		t.Errorf("expected a NotEnoughClustersInRegionError, got %v", err)
+	}
+}
+
+func TestComputeTargetClustersHonoursAffinity(t *testing.T) {
+	release := generateReleaseForTestCase(shipper.ClusterRequirements{
+		Regions: []shipper.RegionRequirement{{Name: shippertesting.TestRegion, Replicas: pint32(1)}},
+		Affinity: &shipper.ClusterAffinity{
+			Preferred: []shipper.PreferredClusterSelector{
+				{
+					Weight:   100,
+					Selector: metav1.LabelSelector{MatchLabels: map[string]string{"ssd": "true"}},
+				},
+			},
+		},
+		AntiAffinity: &shipper.ClusterAffinity{
+			Required: []metav1.LabelSelector{
+				{MatchLabels: map[string]string{"tier": "legacy"}},
+			},
+		},
+	})
+
+	clusterSpec := shipper.ClusterSpec{Region: shippertesting.TestRegion}
+	legacy := generateClusterForTestCase(0, clusterSpec)
+	legacy.Labels = map[string]string{"tier": "legacy"}
+	plain := generateClusterForTestCase(1, clusterSpec)
+	ssd := generateClusterForTestCase(2, clusterSpec)
+	ssd.Labels = map[string]string{"ssd": "true"}
+	// Without preferences, this puts the cluster with SSDs at the end of
+	// the preference list.
+	ssd.Spec.Scheduler.Weight = pint32(0)
+
+	selected, skipped, err := computeTargetClusters(release, []*shipper.Cluster{legacy, plain, ssd}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	names := make([]string, 0, len(selected))
+	for _, cluster := range selected {
+		names = append(names, cluster.Name)
+	}
+	if expected := "cluster-2"; strings.Join(names, ",") != expected {
+		t.Errorf("expected clusters %q, got %q", expected, strings.Join(names, ","))
+	}
+
+	expectedSkipped := `cluster-0: matches cluster anti-affinity "tier=legacy"`
+	if strings.Join(skipped, "\n") != expectedSkipped {
+		t.Errorf("expected skipped clusters %q, got %q", expectedSkipped, skipped)
+	}
 }
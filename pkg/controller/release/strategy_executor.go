@@ -21,6 +21,8 @@ const (
 
 type context struct {
 	release    *shipper.Release
+	strategy   *shipper.RolloutStrategy
+	clusters   map[string]*shipper.Cluster
 	step       int32
 	isHead     bool
 	isLastStep bool
@@ -30,6 +32,8 @@ type context struct {
 func (ctx *context) Copy() *context {
 	return &context{
 		release:    ctx.release,
+		strategy:   ctx.strategy,
+		clusters:   ctx.clusters,
 		step:       ctx.step,
 		isHead:     ctx.isHead,
 		isLastStep: ctx.isLastStep,
@@ -37,6 +41,27 @@ func (ctx *context) Copy() *context {
 	}
 }
 
+// clusterStepValues returns the capacity and traffic values the current
+// step defines for the given cluster.
+func (ctx *context) clusterStepValues(clusterName string) (shipper.RolloutStrategyStepValue, shipper.RolloutStrategyStepValue) {
+	cluster, ok := ctx.clusters[clusterName]
+	if !ok {
+		// A cluster we know nothing about can still be selected by
+		// name.
+		cluster = &shipper.Cluster{}
+		cluster.Name = clusterName
+	}
+
+	capacity, traffic, err := releaseutil.ClusterStepValues(ctx.strategy, ctx.step, cluster)
+	if err != nil {
+		// Cluster selectors are validated before a strategy is
+		// executed, so this should never happen.
+		klog.Errorf("Failed to resolve step values for cluster %q: %s", clusterName, err)
+	}
+
+	return capacity, traffic
+}
+
 type PipelineStep func(shipper.RolloutStrategyStep, conditions.StrategyConditionsMap) (PipelineContinuation, []StrategyPatch, []ReleaseStrategyStateTransition)
 
 type Pipeline interface {
@@ -95,13 +120,20 @@ type StrategyExecutor struct {
 	strategy            *shipper.RolloutStrategy
 	step                int32
 	isSteppingBackwards bool
+	clusters            map[string]*shipper.Cluster
 }
 
-func NewStrategyExecutor(strategy *shipper.RolloutStrategy, step int32, isSteppingBackwards bool) *StrategyExecutor {
+func NewStrategyExecutor(strategy *shipper.RolloutStrategy, step int32, isSteppingBackwards bool, clusters []*shipper.Cluster) *StrategyExecutor {
+	clustersByName := make(map[string]*shipper.Cluster, len(clusters))
+	for _, cluster := range clusters {
+		clustersByName[cluster.Name] = cluster
+	}
+
 	return &StrategyExecutor{
 		strategy:            strategy,
 		step:                step,
 		isSteppingBackwards: isSteppingBackwards,
+		clusters:            clustersByName,
 	}
 }
 
@@ -165,6 +197,8 @@ func (e *StrategyExecutor) Execute(prev, curr, succ *releaseInfo, pipeline Pipel
 
 	ctx := &context{
 		release:    curr.release,
+		strategy:   e.strategy,
+		clusters:   e.clusters,
 		hasTail:    hasTail,
 		isLastStep: isLastStep,
 		step:       e.step,
@@ -177,7 +211,24 @@ func (e *StrategyExecutor) Execute(prev, curr, succ *releaseInfo, pipeline Pipel
 	// a release is "not isSteppingBackwards" when the targetStep >= achieved step.
 	// we include the state where the steps are equal because this state does not require
 	// reversing the direction of the strategy executor
-	if e.isSteppingBackwards {
+	if e.strategy.BlueGreen != nil && hasTail {
+		// Blue/green rollouts never interleave capacity and traffic
+		// changes: whichever release needs more capacity gets it
+		// before traffic is switched over in one go, and capacity is
+		// only taken away from the incumbent once it no longer gets
+		// any traffic.
+		prevctx := ctx.Copy()
+		prevctx.isHead = false
+
+		pipeline.Enqueue(genCapacityEnforcer(ctx, curr, succ))
+		if e.isSteppingBackwards {
+			pipeline.Enqueue(genCapacityEnforcer(prevctx, prev, curr))
+		}
+		pipeline.Enqueue(genTrafficSwitchEnforcer(ctx, prev, curr))
+		if !e.isSteppingBackwards {
+			pipeline.Enqueue(genCapacityEnforcer(prevctx, prev, curr))
+		}
+	} else if e.isSteppingBackwards {
 		// release isSteppingBackwards (achieved step > target step):
 		// increase capacity for previous release
 		// increase traffic for previous release
@@ -258,7 +309,6 @@ func genInstallationEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep
 func genCapacityEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep {
 	return func(strategyStep shipper.RolloutStrategyStep, cond conditions.StrategyConditionsMap) (PipelineContinuation, []StrategyPatch, []ReleaseStrategyStateTransition) {
 		var condType shipper.StrategyConditionType
-		var capacityWeight int32
 		isHead := succ == nil
 		isInitiator := releasesIdentical(ctx.release, curr.release)
 
@@ -267,13 +317,54 @@ func genCapacityEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep {
 		} else {
 			condType = shipper.StrategyConditionIncumbentAchievedCapacity
 		}
-		if isHead {
-			capacityWeight = strategyStep.Capacity.Contender
-		} else {
-			capacityWeight = strategyStep.Capacity.Incumbent
+
+		clusterNames := make([]string, 0, len(curr.capacityTarget.Spec.Clusters))
+		for _, spec := range curr.capacityTarget.Spec.Clusters {
+			clusterNames = append(clusterNames, spec.Name)
+		}
+
+		capacityWeights := make(map[string]clusterCapacity)
+		for _, spec := range curr.capacityTarget.Spec.Clusters {
+			capacity, _ := ctx.clusterStepValues(spec.Name)
+			if isHead {
+				capacityWeights[spec.Name] = clusterCapacity{
+					percent:  capacity.Contender,
+					replicas: releaseutil.ContenderReplicas(capacity, spec.Name, clusterNames),
+				}
+			} else {
+				capacityWeights[spec.Name] = clusterCapacity{percent: capacity.Incumbent}
+			}
+		}
+
+		for _, spec := range curr.capacityTarget.Spec.Clusters {
+			replicas := capacityWeights[spec.Name].replicas
+			if replicas == nil || *replicas <= spec.TotalReplicaCount {
+				continue
+			}
+
+			// The webhook would reject such a capacity target, so
+			// the step can't be achieved until the strategy or the
+			// chart changes.
+			cond.SetFalse(
+				condType,
+				conditions.StrategyConditionsUpdate{
+					Reason:             ReplicaCountTooHigh,
+					Message:            fmt.Sprintf("step asks for %d replicas of release %q in cluster %q, but it only requests %d", *replicas, curr.release.GetName(), spec.Name, spec.TotalReplicaCount),
+					Step:               ctx.step,
+					LastTransitionTime: time.Now(),
+				},
+			)
+
+			patches := make([]StrategyPatch, 0, 1)
+			relPatch := buildContenderStrategyConditionsPatch(ctx, cond)
+			if relPatch.Alters(ctx.release) {
+				patches = append(patches, relPatch)
+			}
+
+			return PipelineBreak, patches, nil
 		}
 
-		if achieved, newSpec, clustersNotReady := checkCapacity(curr.capacityTarget, capacityWeight); !achieved {
+		if achieved, newSpec, clustersNotReady := checkCapacity(curr.capacityTarget, capacityWeights); !achieved {
 			klog.Infof("Release %q %s", controller.MetaKey(curr.release), "hasn't achieved capacity yet")
 
 			patches := make([]StrategyPatch, 0, 2)
@@ -323,7 +414,6 @@ func genCapacityEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep {
 func genTrafficEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep {
 	return func(strategyStep shipper.RolloutStrategyStep, cond conditions.StrategyConditionsMap) (PipelineContinuation, []StrategyPatch, []ReleaseStrategyStateTransition) {
 		var condType shipper.StrategyConditionType
-		var trafficWeight int32
 		isHead := succ == nil
 		isInitiator := releasesIdentical(ctx.release, curr.release)
 
@@ -332,13 +422,10 @@ func genTrafficEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep {
 		} else {
 			condType = shipper.StrategyConditionIncumbentAchievedTraffic
 		}
-		if isHead {
-			trafficWeight = strategyStep.Traffic.Contender
-		} else {
-			trafficWeight = strategyStep.Traffic.Incumbent
-		}
 
-		if achieved, newSpec, reason := checkTraffic(curr.trafficTarget, uint32(trafficWeight)); !achieved {
+		trafficWeights := stepTrafficWeights(ctx, curr, isHead)
+
+		if achieved, newSpec, reason := checkTraffic(curr.trafficTarget, trafficWeights); !achieved {
 			klog.Infof("Release %q %s", controller.MetaKey(curr.release), "hasn't achieved traffic yet")
 
 			patches := make([]StrategyPatch, 0, 2)
@@ -385,6 +472,92 @@ func genTrafficEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep {
 	}
 }
 
+// genTrafficSwitchEnforcer moves traffic between the incumbent and the
+// contender at once, patching both of their traffic targets together instead
+// of waiting for one of them to achieve traffic before touching the other.
+func genTrafficSwitchEnforcer(ctx *context, prev, curr *releaseInfo) PipelineStep {
+	return func(strategyStep shipper.RolloutStrategyStep, cond conditions.StrategyConditionsMap) (PipelineContinuation, []StrategyPatch, []ReleaseStrategyStateTransition) {
+		patches := make([]StrategyPatch, 0, 3)
+		achieved := true
+
+		targets := []struct {
+			relinfo  *releaseInfo
+			isHead   bool
+			condType shipper.StrategyConditionType
+		}{
+			{curr, true, shipper.StrategyConditionContenderAchievedTraffic},
+			{prev, false, shipper.StrategyConditionIncumbentAchievedTraffic},
+		}
+
+		for _, t := range targets {
+			trafficWeights := stepTrafficWeights(ctx, t.relinfo, t.isHead)
+			if ok, newSpec, reason := checkTraffic(t.relinfo.trafficTarget, trafficWeights); !ok {
+				klog.Infof("Release %q %s", controller.MetaKey(t.relinfo.release), "hasn't achieved traffic yet")
+				achieved = false
+
+				cond.SetFalse(
+					t.condType,
+					conditions.StrategyConditionsUpdate{
+						Reason:             ClustersNotReady,
+						Message:            fmt.Sprintf("release %q hasn't achieved traffic in clusters: %s. for more details try `kubectl describe tt %s`", t.relinfo.release.GetName(), reason, t.relinfo.trafficTarget.GetName()),
+						Step:               ctx.step,
+						LastTransitionTime: time.Now(),
+					},
+				)
+
+				ttPatch := &TrafficTargetSpecPatch{
+					NewSpec: newSpec,
+					Name:    t.relinfo.release.GetName(),
+				}
+				if ttPatch.Alters(t.relinfo.trafficTarget) {
+					patches = append(patches, ttPatch)
+				}
+
+				continue
+			}
+
+			klog.Infof("Release %q %s", controller.MetaKey(t.relinfo.release), "has achieved traffic")
+
+			cond.SetTrue(
+				t.condType,
+				conditions.StrategyConditionsUpdate{
+					Step:               ctx.step,
+					LastTransitionTime: time.Now(),
+					Message:            "",
+					Reason:             "",
+				},
+			)
+		}
+
+		if achieved {
+			return PipelineContinue, nil, nil
+		}
+
+		relPatch := buildContenderStrategyConditionsPatch(ctx, cond)
+		if relPatch.Alters(ctx.release) {
+			patches = append(patches, relPatch)
+		}
+
+		return PipelineBreak, patches, nil
+	}
+}
+
+// stepTrafficWeights returns the traffic weights the current step defines
+// for a release in every cluster it is scheduled on.
+func stepTrafficWeights(ctx *context, relinfo *releaseInfo, isHead bool) map[string]uint32 {
+	trafficWeights := make(map[string]uint32)
+	for _, spec := range relinfo.trafficTarget.Spec.Clusters {
+		_, traffic := ctx.clusterStepValues(spec.Name)
+		if isHead {
+			trafficWeights[spec.Name] = uint32(traffic.Contender)
+		} else {
+			trafficWeights[spec.Name] = uint32(traffic.Incumbent)
+		}
+	}
+
+	return trafficWeights
+}
+
 func genReleaseStrategyStateEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep {
 	return func(strategyStep shipper.RolloutStrategyStep, cond conditions.StrategyConditionsMap) (PipelineContinuation, []StrategyPatch, []ReleaseStrategyStateTransition) {
 		var releaseStrategyStateTransitions []ReleaseStrategyStateTransition
@@ -258,7 +258,7 @@ func TestExecute(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(tt *testing.T) {
-			executor := NewStrategyExecutor(test.curr.release.Spec.Environment.Strategy, test.curr.release.Spec.TargetStep, test.isSteppingBackwards)
+			executor := NewStrategyExecutor(test.curr.release.Spec.Environment.Strategy, test.curr.release.Spec.TargetStep, test.isSteppingBackwards, nil)
 			// write  to buffer
 			pipeline := &fakePipeline{
 				steps:     []string{},
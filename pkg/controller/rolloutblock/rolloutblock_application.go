@@ -32,6 +32,7 @@ func (c *Controller) addApplicationsToRolloutBlocks(rolloutBlockKey string, roll
 		for rbKey := range overrideRBs {
 			if rbKey == rolloutBlockKey {
 				appsKeys.Add(appKey)
+				c.recordOverride(rolloutBlock, "Application", appKey, app)
 			}
 		}
 	}
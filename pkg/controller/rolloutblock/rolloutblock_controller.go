@@ -2,9 +2,12 @@ package rolloutblock
 
 import (
 	"fmt"
+	"reflect"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -32,6 +35,11 @@ const (
 //
 // RolloutBlock Controller has one primary workqueues: a rolloutblock updater
 // object queue.
+//
+// Blocks can be restricted to a time window or a recurring schedule. The
+// controller reflects whether they are in effect in their status, and syncs
+// them again whenever that is due to change, so that the application and
+// release controllers notice.
 type Controller struct {
 	shipperClientset clientset.Interface
 	recorder         record.EventRecorder
@@ -111,7 +119,10 @@ func NewController(
 
 	rolloutBlockInformer.Informer().AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
-			AddFunc:    controller.onAddRolloutBlock,
+			AddFunc: controller.onAddRolloutBlock,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				controller.onUpdateRolloutBlock(oldObj, newObj)
+			},
 			DeleteFunc: controller.onDeleteRolloutBlock,
 		})
 
@@ -190,6 +201,28 @@ func (c *Controller) onAddRolloutBlock(obj interface{}) {
 	c.rolloutblockWorkqueue.Add(key)
 }
 
+func (c *Controller) onUpdateRolloutBlock(oldObj, newObj interface{}) {
+	oldRB, ok := oldObj.(*shipper.RolloutBlock)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a shipper.RolloutBlock: %#v", oldObj))
+		return
+	}
+
+	newRB, ok := newObj.(*shipper.RolloutBlock)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a shipper.RolloutBlock: %#v", newObj))
+		return
+	}
+
+	// Status updates are our own doing, but a change in the time window
+	// or schedule of a block can change whether it is in effect.
+	if reflect.DeepEqual(oldRB.Spec, newRB.Spec) {
+		return
+	}
+
+	c.onAddRolloutBlock(newRB)
+}
+
 func (c *Controller) onDeleteRolloutBlock(obj interface{}) {
 	rb, ok := obj.(*shipper.RolloutBlock)
 	if !ok {
@@ -385,6 +418,9 @@ func (c *Controller) syncRolloutBlock(key string) error {
 		return err
 	}
 
+	rolloutBlock = rolloutBlock.DeepCopy()
+	c.updateActivity(key, rolloutBlock)
+
 	var appListerFunc func(selector labels.Selector) (ret []*shipper.Application, err error)
 	var relListerFunc func(selector labels.Selector) (ret []*shipper.Release, err error)
 	switch ns {
@@ -420,3 +456,52 @@ func (c *Controller) syncRolloutBlock(key string) error {
 
 	return nil
 }
+
+// updateActivity reflects whether a rollout block is currently in effect in
+// its status, and schedules it to be synced again when that changes.
+func (c *Controller) updateActivity(key string, rolloutBlock *shipper.RolloutBlock) {
+	now := time.Now()
+	active, next, err := rolloutblock.Activity(rolloutBlock.Spec, now)
+	if err != nil {
+		// Blocks with a malformed schedule stay in effect.
+		active = true
+		if rolloutBlock.Status.ScheduleError == "" {
+			c.recorder.Event(rolloutBlock, corev1.EventTypeWarning, "InvalidSchedule", err.Error())
+		}
+		rolloutBlock.Status.ScheduleError = err.Error()
+	} else {
+		rolloutBlock.Status.ScheduleError = ""
+	}
+
+	if active != rolloutBlock.Status.Active {
+		klog.V(4).Infof("RolloutBlock %q is now active: %t", key, active)
+	}
+
+	rolloutBlock.Status.Active = active
+	rolloutBlock.Status.NextTransition = nil
+	if !next.IsZero() {
+		rolloutBlock.Status.NextTransition = &metav1.Time{Time: next}
+		c.rolloutblockWorkqueue.AddAfter(key, next.Sub(now))
+	}
+}
+
+// recordOverride adds an object overriding a rollout block to the audit
+// history of the block, and emits an event on it the first time around.
+func (c *Controller) recordOverride(rolloutBlock *shipper.RolloutBlock, kind, key string, obj metav1.Object) {
+	record, ok := rolloutblock.OverrideRecord(kind, key, obj)
+	if !ok {
+		return
+	}
+
+	if !rolloutblock.RecordOverride(&rolloutBlock.Status, record) {
+		return
+	}
+
+	c.recorder.Eventf(
+		rolloutBlock,
+		corev1.EventTypeNormal,
+		"RolloutBlockOverridden",
+		"%s %q overrode this block on behalf of %q: %s",
+		kind, key, record.Author, record.Justification,
+	)
+}
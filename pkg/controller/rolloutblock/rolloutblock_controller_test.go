@@ -64,6 +64,7 @@ func TestNewRolloutBlockWithoutOverrides(t *testing.T) {
 	f.objects = append(f.objects, rolloutblock, app, rel)
 
 	expectedRolloutBlock := rolloutblock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = ""
 	expectedRolloutBlock.Status.Overrides.Release = ""
 
@@ -83,6 +84,7 @@ func TestNewRolloutBlockWithOverrides(t *testing.T) {
 	f.objects = append(f.objects, rolloutBlock)
 
 	expectedRolloutBlock := rolloutBlock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testAppName)
 	expectedRolloutBlock.Status.Overrides.Release = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testReleaseName)
 
@@ -102,6 +104,7 @@ func TestAddApplicationToRolloutBlockStatus(t *testing.T) {
 	f.objects = append(f.objects, app)
 
 	expectedRolloutBlock := rolloutblock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testAppName)
 	expectedRolloutBlock.Status.Overrides.Release = ""
 
@@ -109,6 +112,38 @@ func TestAddApplicationToRolloutBlockStatus(t *testing.T) {
 	f.run()
 }
 
+func TestRecordApplicationOverrideInRolloutBlockHistory(t *testing.T) {
+	f := newFixture(t)
+
+	rolloutblock := newRolloutBlock(testRolloutBlockName, shippertesting.TestNamespace)
+	f.objects = append(f.objects, rolloutblock)
+
+	app := newApplication(testAppName)
+	app.Annotations[shipper.RolloutBlocksOverrideAnnotation] = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testRolloutBlockName)
+	app.Annotations[shipper.RolloutBlocksOverrideAuthorAnnotation] = "jdoe"
+	app.Annotations[shipper.RolloutBlocksOverrideTimestampAnnotation] = "2020-01-10T12:00:00Z"
+	app.Annotations[shipper.RolloutBlocksOverrideJustificationAnnotation] = "hotfix for INC-42"
+
+	f.objects = append(f.objects, app)
+
+	expectedRolloutBlock := rolloutblock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
+	expectedRolloutBlock.Status.Overrides.Application = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testAppName)
+	expectedRolloutBlock.Status.Overrides.Release = ""
+	expectedRolloutBlock.Status.OverrideHistory = []shipper.RolloutBlockOverrideRecord{
+		{
+			Kind:          "Application",
+			Name:          fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testAppName),
+			Author:        "jdoe",
+			Timestamp:     metav1.NewTime(time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC)),
+			Justification: "hotfix for INC-42",
+		},
+	}
+
+	f.expectRolloutBlockUpdate(expectedRolloutBlock)
+	f.run()
+}
+
 func TestAddApplicationAndReleaseToRolloutBlockStatus(t *testing.T) {
 	f := newFixture(t)
 
@@ -122,6 +157,7 @@ func TestAddApplicationAndReleaseToRolloutBlockStatus(t *testing.T) {
 	f.objects = append(f.objects, app, rel)
 
 	expectedRolloutBlock := rolloutblock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testAppName)
 	expectedRolloutBlock.Status.Overrides.Release = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testReleaseName)
 
@@ -142,6 +178,7 @@ func TestAddReleaseToRolloutBlockStatus(t *testing.T) {
 	f.objects = append(f.objects, app, rel)
 
 	expectedRolloutBlock := rolloutblock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = ""
 	expectedRolloutBlock.Status.Overrides.Release = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testReleaseName)
 
@@ -163,6 +200,7 @@ func TestRemoveAppFromRolloutBlockStatus(t *testing.T) {
 	app.Annotations[shipper.RolloutBlocksOverrideAnnotation] = ""
 
 	expectedRolloutBlock := rolloutblock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = ""
 	expectedRolloutBlock.Status.Overrides.Release = ""
 
@@ -185,6 +223,7 @@ func TestRemoveReleaseFromRolloutBlockStatus(t *testing.T) {
 	rel.Annotations[shipper.RolloutBlocksOverrideAnnotation] = ""
 
 	expectedRolloutBlock := rolloutblock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testAppName)
 	expectedRolloutBlock.Status.Overrides.Release = ""
 
@@ -203,6 +242,7 @@ func TestNewGlobalRolloutBlockWithoutOverrides(t *testing.T) {
 	f.objects = append(f.objects, rolloutblock, app, rel)
 
 	expectedRolloutBlock := rolloutblock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = ""
 	expectedRolloutBlock.Status.Overrides.Release = ""
 
@@ -222,6 +262,7 @@ func TestNewGlobalRolloutBlockWithOverrides(t *testing.T) {
 	f.objects = append(f.objects, rolloutBlock)
 
 	expectedRolloutBlock := rolloutBlock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testAppName)
 	expectedRolloutBlock.Status.Overrides.Release = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testReleaseName)
 
@@ -241,6 +282,7 @@ func TestAddApplicationToGlobalRolloutBlockStatus(t *testing.T) {
 	f.objects = append(f.objects, app)
 
 	expectedRolloutBlock := rolloutblock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testAppName)
 	expectedRolloutBlock.Status.Overrides.Release = ""
 
@@ -261,6 +303,7 @@ func TestAddApplicationAndReleaseToGlobalRolloutBlockStatus(t *testing.T) {
 	f.objects = append(f.objects, app, rel)
 
 	expectedRolloutBlock := rolloutblock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testAppName)
 	expectedRolloutBlock.Status.Overrides.Release = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testReleaseName)
 
@@ -281,6 +324,7 @@ func TestAddReleaseToGlobalRolloutBlockStatus(t *testing.T) {
 	f.objects = append(f.objects, app, rel)
 
 	expectedRolloutBlock := rolloutblock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = ""
 	expectedRolloutBlock.Status.Overrides.Release = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testReleaseName)
 
@@ -302,6 +346,7 @@ func TestRemoveAppFromGlobalRolloutBlockStatus(t *testing.T) {
 	app.Annotations[shipper.RolloutBlocksOverrideAnnotation] = ""
 
 	expectedRolloutBlock := rolloutblock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = ""
 	expectedRolloutBlock.Status.Overrides.Release = ""
 
@@ -324,6 +369,7 @@ func TestRemoveReleaseFromGlobalRolloutBlockStatus(t *testing.T) {
 	rel.Annotations[shipper.RolloutBlocksOverrideAnnotation] = ""
 
 	expectedRolloutBlock := rolloutblock.DeepCopy()
+	expectedRolloutBlock.Status.Active = true
 	expectedRolloutBlock.Status.Overrides.Application = fmt.Sprintf("%s/%s", shippertesting.TestNamespace, testAppName)
 	expectedRolloutBlock.Status.Overrides.Release = ""
 
@@ -331,6 +377,40 @@ func TestRemoveReleaseFromGlobalRolloutBlockStatus(t *testing.T) {
 	f.run()
 }
 
+func TestInvalidScheduleEventOnlyOnTransition(t *testing.T) {
+	f := newFixture(t)
+	c, _ := f.newController()
+	recorder := record.NewFakeRecorder(42)
+	c.recorder = recorder
+
+	rb := newRolloutBlock(testRolloutBlockName, shippertesting.TestNamespace)
+	rb.Spec.Schedule = &shipper.RolloutBlockSchedule{Start: "0 18 * * 5", Duration: "forever"}
+
+	for i := 0; i < 3; i++ {
+		c.updateActivity(testRolloutBlockName, rb)
+	}
+
+	if !rb.Status.Active {
+		t.Errorf("expected a block with an invalid schedule to stay in effect")
+	}
+	if rb.Status.ScheduleError == "" {
+		t.Errorf("expected the schedule error to be reflected in the status")
+	}
+	if n := len(recorder.Events); n != 1 {
+		t.Fatalf("expected a single InvalidSchedule event, got %d", n)
+	}
+
+	rb.Spec.Schedule.Duration = "64h"
+	c.updateActivity(testRolloutBlockName, rb)
+
+	if rb.Status.ScheduleError != "" {
+		t.Errorf("expected the schedule error to be cleared, got %q", rb.Status.ScheduleError)
+	}
+	if n := len(recorder.Events); n != 1 {
+		t.Errorf("expected no further events for a valid schedule, got %d", n)
+	}
+}
+
 func (f *fixture) expectRolloutBlockUpdate(rb *shipper.RolloutBlock) {
 	gvr := shipper.SchemeGroupVersion.WithResource("rolloutblocks")
 	action := kubetesting.NewUpdateAction(gvr, rb.GetNamespace(), rb)
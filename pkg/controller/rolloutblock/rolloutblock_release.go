@@ -32,6 +32,7 @@ func (c *Controller) addReleasesToRolloutBlocks(rolloutBlockKey string, rolloutB
 		for rbKey := range overrideRBs {
 			if rbKey == rolloutBlockKey {
 				relsKeys.Add(relKey)
+				c.recordOverride(rolloutBlock, "Release", relKey, release)
 			}
 		}
 	}
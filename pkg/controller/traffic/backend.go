@@ -0,0 +1,59 @@
+package traffic
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+// DynamicClientBuilderFunc returns a dynamic client for an application
+// cluster, for backends that manage objects Shipper has no typed client for.
+type DynamicClientBuilderFunc func(clusterName string, restConfig *rest.Config) (dynamic.Interface, error)
+
+// TrafficBackend shifts the traffic of an application in an application
+// cluster between its releases.
+type TrafficBackend interface {
+	// Shift moves traffic in a cluster towards the weights its releases
+	// should have, and reports how far along the release of the traffic
+	// target being processed is.
+	Shift(traffic *clusterTraffic) (trafficShiftingStatus, error)
+}
+
+// clusterTraffic is what a TrafficBackend gets to know about an application
+// in a cluster.
+type clusterTraffic struct {
+	cluster     string
+	clientset   kubernetes.Interface
+	namespace   string
+	appName     string
+	releaseName string
+
+	clusterReleaseWeights clusterReleaseWeights
+
+	// service is the production Service of the application, and
+	// endpoints are its Endpoints.
+	service   *corev1.Service
+	endpoints *corev1.Endpoints
+	appPods   []*corev1.Pod
+}
+
+func (c *Controller) getTrafficBackend(tt *shipper.TrafficTarget) (TrafficBackend, error) {
+	backendType := tt.Spec.Backend
+	if backendType == "" {
+		backendType = shipper.TrafficBackendPodLabels
+	}
+
+	backend, ok := c.trafficBackends[backendType]
+	if !ok {
+		return nil, shippererrors.NewUnrecoverableError(
+			fmt.Errorf("unknown traffic backend %q", backendType))
+	}
+
+	return backend, nil
+}
@@ -0,0 +1,321 @@
+package traffic
+
+import (
+	"math"
+	"reflect"
+	"sort"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/clusterclientstore"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+var (
+	istioNetworkingGroupVersion = schema.GroupVersion{Group: "networking.istio.io", Version: "v1alpha3"}
+
+	virtualServiceGVK  = istioNetworkingGroupVersion.WithKind("VirtualService")
+	virtualServiceGVR  = istioNetworkingGroupVersion.WithResource("virtualservices")
+	destinationRuleGVK = istioNetworkingGroupVersion.WithKind("DestinationRule")
+	destinationRuleGVR = istioNetworkingGroupVersion.WithResource("destinationrules")
+)
+
+// istioBackend shifts traffic with an Istio VirtualService and DestinationRule
+// pair named after the production Service of an application. The
+// DestinationRule has a subset for the pods of each release, and the
+// VirtualService routes to each subset the share of traffic that the weight
+// of its release calls for, regardless of how many pods it has. Every pod of
+// a release with weight is labeled to receive traffic, so the Service selects
+// all of them.
+type istioBackend struct {
+	clusterClientStore clusterclientstore.Interface
+	buildDynamicClient DynamicClientBuilderFunc
+}
+
+var _ TrafficBackend = (*istioBackend)(nil)
+
+func newIstioBackend(
+	store clusterclientstore.Interface,
+	buildDynamicClient DynamicClientBuilderFunc,
+) *istioBackend {
+	return &istioBackend{
+		clusterClientStore: store,
+		buildDynamicClient: buildDynamicClient,
+	}
+}
+
+func (b *istioBackend) Shift(traffic *clusterTraffic) (trafficShiftingStatus, error) {
+	releaseWeights := traffic.clusterReleaseWeights[traffic.cluster]
+
+	releaseSelector := labels.Set(map[string]string{
+		shipper.AppLabel:     traffic.appName,
+		shipper.ReleaseLabel: traffic.releaseName,
+	}).AsSelector()
+
+	podsByTrafficStatus, podsInRelease, podsReady, podsNotReady := summarizePods(
+		traffic.appPods, traffic.endpoints, releaseSelector)
+
+	podTrafficStatus := podTrafficStatusForRelease(
+		traffic.cluster, traffic.releaseName, traffic.clusterReleaseWeights)
+
+	podsToLabel := 0
+	if podTrafficStatus == shipper.Enabled {
+		podsToLabel = podsInRelease
+	}
+
+	status := trafficShiftingStatus{
+		podsReady:    podsReady,
+		podsNotReady: podsNotReady,
+		podsLabeled:  len(podsByTrafficStatus[shipper.Enabled]),
+		podsToShift:  buildPodsToShift(podsByTrafficStatus, podsToLabel),
+	}
+
+	if status.podsToShift != nil {
+		err := shiftPodLabels(traffic.clientset, status.podsToShift)
+		if err != nil {
+			return status, err
+		}
+	}
+
+	client, err := b.getDynamicClient(traffic.cluster)
+	if err != nil {
+		return status, err
+	}
+
+	// The DestinationRule goes first, so the VirtualService never routes
+	// to a subset that doesn't exist yet.
+	destinationRule := buildDestinationRule(traffic, releaseWeights)
+	_, err = createOrUpdateSpec(client, destinationRuleGVR, destinationRuleGVK, destinationRule)
+	if err != nil {
+		return status, err
+	}
+
+	// Without any weight, there's nowhere to route traffic to, so we
+	// leave the VirtualService as it is.
+	routeWeights := buildRouteWeights(releaseWeights)
+	if len(routeWeights) > 0 {
+		virtualService := buildVirtualService(traffic, routeWeights)
+		virtualService, err = createOrUpdateSpec(client, virtualServiceGVR, virtualServiceGVK, virtualService)
+		if err != nil {
+			return status, err
+		}
+
+		// The achieved weight is whatever the VirtualService in the
+		// cluster actually routes to the release, as long as it has
+		// pods to take it.
+		if podsReady > 0 {
+			routedPercent := getRouteWeights(virtualService)[traffic.releaseName]
+			status.achievedTrafficWeight = uint32(math.Round(
+				float64(routedPercent) * float64(sumWeights(releaseWeights)) / 100))
+		}
+	}
+
+	status.ready = status.podsToShift == nil && podsReady == podsToLabel
+
+	return status, nil
+}
+
+func (b *istioBackend) getDynamicClient(cluster string) (dynamic.Interface, error) {
+	restConfig, err := b.clusterClientStore.GetConfig(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.buildDynamicClient(cluster, restConfig)
+}
+
+// buildRouteWeights turns the weights of the releases of an application into
+// the percentages an Istio route needs, which must add up to 100. Rounding
+// goes to the releases that lose the most to it. Releases without weight
+// aren't routed to at all.
+func buildRouteWeights(releaseWeights map[string]uint32) map[string]int64 {
+	total := sumWeights(releaseWeights)
+	if total == 0 {
+		return nil
+	}
+
+	releases := make([]string, 0, len(releaseWeights))
+	for release, weight := range releaseWeights {
+		if weight > 0 {
+			releases = append(releases, release)
+		}
+	}
+
+	sort.Strings(releases)
+
+	routeWeights := make(map[string]int64, len(releases))
+	remainders := make(map[string]uint64, len(releases))
+	left := int64(100)
+	for _, release := range releases {
+		scaled := uint64(releaseWeights[release]) * 100
+		routeWeights[release] = int64(scaled / uint64(total))
+		remainders[release] = scaled % uint64(total)
+		left -= routeWeights[release]
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return remainders[releases[i]] > remainders[releases[j]]
+	})
+
+	for i := int64(0); i < left; i++ {
+		routeWeights[releases[i]]++
+	}
+
+	return routeWeights
+}
+
+func sumWeights(releaseWeights map[string]uint32) uint32 {
+	total := uint32(0)
+	for _, weight := range releaseWeights {
+		total += weight
+	}
+
+	return total
+}
+
+func buildDestinationRule(traffic *clusterTraffic, releaseWeights map[string]uint32) *unstructured.Unstructured {
+	releases := make([]string, 0, len(releaseWeights))
+	for release := range releaseWeights {
+		releases = append(releases, release)
+	}
+
+	sort.Strings(releases)
+
+	subsets := make([]interface{}, 0, len(releases))
+	for _, release := range releases {
+		subsets = append(subsets, map[string]interface{}{
+			"name": release,
+			"labels": map[string]interface{}{
+				shipper.ReleaseLabel: release,
+			},
+		})
+	}
+
+	destinationRule := newIstioObject(traffic, destinationRuleGVK)
+	destinationRule.Object["spec"] = map[string]interface{}{
+		"host":    traffic.service.Name,
+		"subsets": subsets,
+	}
+
+	return destinationRule
+}
+
+func buildVirtualService(traffic *clusterTraffic, routeWeights map[string]int64) *unstructured.Unstructured {
+	releases := make([]string, 0, len(routeWeights))
+	for release := range routeWeights {
+		releases = append(releases, release)
+	}
+
+	sort.Strings(releases)
+
+	destinations := make([]interface{}, 0, len(releases))
+	for _, release := range releases {
+		destinations = append(destinations, map[string]interface{}{
+			"destination": map[string]interface{}{
+				"host":   traffic.service.Name,
+				"subset": release,
+			},
+			"weight": routeWeights[release],
+		})
+	}
+
+	virtualService := newIstioObject(traffic, virtualServiceGVK)
+	virtualService.Object["spec"] = map[string]interface{}{
+		"hosts": []interface{}{traffic.service.Name},
+		"http": []interface{}{
+			map[string]interface{}{
+				"route": destinations,
+			},
+		},
+	}
+
+	return virtualService
+}
+
+func newIstioObject(traffic *clusterTraffic, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(traffic.service.Name)
+	obj.SetNamespace(traffic.namespace)
+	obj.SetLabels(map[string]string{
+		shipper.AppLabel: traffic.appName,
+	})
+
+	return obj
+}
+
+// getRouteWeights returns the weight a VirtualService routes to each subset
+// in its first HTTP route, which is the only one Shipper writes.
+func getRouteWeights(virtualService *unstructured.Unstructured) map[string]int64 {
+	routeWeights := make(map[string]int64)
+
+	httpRoutes, _, _ := unstructured.NestedSlice(virtualService.Object, "spec", "http")
+	if len(httpRoutes) == 0 {
+		return routeWeights
+	}
+
+	httpRoute, ok := httpRoutes[0].(map[string]interface{})
+	if !ok {
+		return routeWeights
+	}
+
+	destinations, _, _ := unstructured.NestedSlice(httpRoute, "route")
+	for _, d := range destinations {
+		destination, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		subset, _, _ := unstructured.NestedString(destination, "destination", "subset")
+		weight, _, _ := unstructured.NestedInt64(destination, "weight")
+		routeWeights[subset] = weight
+	}
+
+	return routeWeights
+}
+
+// createOrUpdateSpec makes sure an object exists in a cluster with the spec of
+// desired, and returns it as it is in the cluster.
+func createOrUpdateSpec(
+	client dynamic.Interface,
+	gvr schema.GroupVersionResource,
+	gvk schema.GroupVersionKind,
+	desired *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	namespace, name := desired.GetNamespace(), desired.GetName()
+	resourceClient := client.Resource(gvr).Namespace(namespace)
+
+	existing, err := resourceClient.Get(name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		created, err := resourceClient.Create(desired, metav1.CreateOptions{})
+		if err != nil {
+			return nil, shippererrors.NewKubeclientCreateError(desired, err).
+				WithKind(gvk)
+		}
+
+		return created, nil
+	} else if err != nil {
+		return nil, shippererrors.NewKubeclientGetError(namespace, name, err).
+			WithKind(gvk)
+	}
+
+	if reflect.DeepEqual(existing.Object["spec"], desired.Object["spec"]) {
+		return existing, nil
+	}
+
+	existing = existing.DeepCopy()
+	existing.Object["spec"] = desired.Object["spec"]
+	updated, err := resourceClient.Update(existing, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, shippererrors.NewKubeclientUpdateError(existing, err).
+			WithKind(gvk)
+	}
+
+	return updated, nil
+}
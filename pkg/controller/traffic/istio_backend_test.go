@@ -0,0 +1,140 @@
+package traffic
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func TestBuildRouteWeights(t *testing.T) {
+	var tests = []struct {
+		title          string
+		releaseWeights map[string]uint32
+		expected       map[string]int64
+	}{
+		{
+			"no weight",
+			map[string]uint32{"foobar-a": 0},
+			nil,
+		},
+		{
+			"weights already add up to 100",
+			map[string]uint32{"foobar-a": 60, "foobar-b": 40},
+			map[string]int64{"foobar-a": 60, "foobar-b": 40},
+		},
+		{
+			"weights are scaled to 100",
+			map[string]uint32{"foobar-a": 3, "foobar-b": 1},
+			map[string]int64{"foobar-a": 75, "foobar-b": 25},
+		},
+		{
+			"rounding goes to the largest remainders",
+			map[string]uint32{"foobar-a": 1, "foobar-b": 1, "foobar-c": 1},
+			map[string]int64{"foobar-a": 34, "foobar-b": 33, "foobar-c": 33},
+		},
+		{
+			"releases without weight are left out",
+			map[string]uint32{"foobar-a": 10, "foobar-b": 0},
+			map[string]int64{"foobar-a": 100},
+		},
+	}
+
+	for _, test := range tests {
+		routeWeights := buildRouteWeights(test.releaseWeights)
+		eq, diff := shippertesting.DeepEqualDiff(test.expected, routeWeights)
+		if !eq {
+			t.Errorf("testing %s: route weights different from expected:\n%s",
+				test.title, diff)
+		}
+	}
+}
+
+// TestIstioBackend verifies that the traffic controller shifts traffic for
+// traffic targets with the istio backend by routing to each release the share
+// of traffic its weight calls for, regardless of how many pods each release
+// has, and that all pods of releases with weight get traffic.
+func TestIstioBackend(t *testing.T) {
+	foobarA := buildTrafficTarget(
+		shippertesting.TestApp, "foobar-a",
+		map[string]uint32{clusterA: 60},
+	)
+	foobarA.Spec.Backend = shipper.TrafficBackendIstio
+	foobarB := buildTrafficTarget(
+		shippertesting.TestApp, "foobar-b",
+		map[string]uint32{clusterA: 40},
+	)
+	foobarB.Spec.Backend = shipper.TrafficBackendIstio
+
+	// With pod labels, 5 pods each can't do better than 50/40. The
+	// VirtualService doesn't care about pod counts.
+	podCount := 5
+	objects := []runtime.Object{
+		buildService(shippertesting.TestApp),
+		buildEndpoints(shippertesting.TestApp),
+	}
+	objects = addPodsToList(objects,
+		buildPods(shippertesting.TestApp, foobarA.Name, podCount, noTraffic))
+	objects = addPodsToList(objects,
+		buildPods(shippertesting.TestApp, foobarB.Name, podCount, noTraffic))
+
+	f := runTrafficControllerTest(t,
+		map[string][]runtime.Object{clusterA: objects},
+		[]trafficTargetTestExpectation{
+			{
+				trafficTarget: foobarA,
+				status:        buildSuccessStatus(foobarA.Spec.Clusters),
+				podsByCluster: map[string]podStatus{
+					clusterA: {withTraffic: podCount},
+				},
+			},
+			{
+				trafficTarget: foobarB,
+				status:        buildSuccessStatus(foobarB.Spec.Clusters),
+				podsByCluster: map[string]podStatus{
+					clusterA: {withTraffic: podCount},
+				},
+			},
+		},
+	)
+
+	service := buildService(shippertesting.TestApp)
+	client := f.Clusters[clusterA].DynamicClient
+
+	virtualService, err := client.Resource(virtualServiceGVR).
+		Namespace(shippertesting.TestNamespace).Get(service.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("could not Get VirtualService %q: %s", service.Name, err)
+	}
+
+	expectedRouteWeights := map[string]int64{foobarA.Name: 60, foobarB.Name: 40}
+	eq, diff := shippertesting.DeepEqualDiff(expectedRouteWeights, getRouteWeights(virtualService))
+	if !eq {
+		t.Errorf("VirtualService routes different from expected:\n%s", diff)
+	}
+
+	destinationRule, err := client.Resource(destinationRuleGVR).
+		Namespace(shippertesting.TestNamespace).Get(service.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("could not Get DestinationRule %q: %s", service.Name, err)
+	}
+
+	subsets, _, _ := unstructured.NestedSlice(destinationRule.Object, "spec", "subsets")
+	subsetLabels := make(map[string]string)
+	for _, s := range subsets {
+		subset := s.(map[string]interface{})
+		name, _, _ := unstructured.NestedString(subset, "name")
+		release, _, _ := unstructured.NestedString(subset, "labels", shipper.ReleaseLabel)
+		subsetLabels[name] = release
+	}
+
+	expectedSubsetLabels := map[string]string{foobarA.Name: foobarA.Name, foobarB.Name: foobarB.Name}
+	eq, diff = shippertesting.DeepEqualDiff(expectedSubsetLabels, subsetLabels)
+	if !eq {
+		t.Errorf("DestinationRule subsets different from expected:\n%s", diff)
+	}
+}
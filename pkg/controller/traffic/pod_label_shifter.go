@@ -21,6 +21,28 @@ type patchOperation struct {
 	Value string `json:"value"`
 }
 
+// podLabelBackend shifts traffic by labelling just enough of the pods of a
+// release to receive traffic for it to get its weight. As the production
+// Service of the application balances traffic evenly among the pods it
+// selects, the weight a release gets is only as precise as its number of
+// pods allows.
+type podLabelBackend struct{}
+
+var _ TrafficBackend = podLabelBackend{}
+
+func (podLabelBackend) Shift(traffic *clusterTraffic) (trafficShiftingStatus, error) {
+	status := buildTrafficShiftingStatus(
+		traffic.cluster, traffic.appName, traffic.releaseName,
+		traffic.clusterReleaseWeights,
+		traffic.endpoints, traffic.appPods)
+
+	if status.ready || status.podsToShift == nil {
+		return status, nil
+	}
+
+	return status, shiftPodLabels(traffic.clientset, status.podsToShift)
+}
+
 // shiftPodLabels ensures that the pods in podsToShift have the
 // shipper.PodTrafficStatusLabel label set to the specified values.
 func shiftPodLabels(
@@ -0,0 +1,72 @@
+package traffic
+
+import (
+	"encoding/json"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/util/anchor"
+)
+
+// podTrafficStatusForRelease returns the value of shipper.PodTrafficStatusLabel
+// that new pods of a release should start with in a cluster. Any release with
+// weight gets traffic on its new pods right away; if that's more pods than its
+// weight calls for, the next evaluation of its traffic target takes traffic
+// away from the extra ones.
+func podTrafficStatusForRelease(
+	cluster, releaseName string,
+	clusterReleaseWeights clusterReleaseWeights,
+) string {
+	if clusterReleaseWeights[cluster][releaseName] > 0 {
+		return shipper.Enabled
+	}
+
+	return shipper.Disabled
+}
+
+// publishPodTrafficStatus records the traffic decision for new pods of a
+// release on its anchor in an application cluster, so pods created while
+// Shipper is not around can still be labeled by the pod labelling webhook.
+// Releases that have not been installed yet have no anchor, and are skipped.
+func (c *Controller) publishPodTrafficStatus(
+	clientset kubernetes.Interface,
+	cluster, namespace, releaseName, value string,
+) error {
+	informerFactory, err := c.clusterClientStore.GetInformerFactory(cluster)
+	if err != nil {
+		return err
+	}
+
+	name := anchor.ReleaseAnchorName(releaseName)
+	configMap, err := informerFactory.Core().V1().ConfigMaps().Lister().
+		ConfigMaps(namespace).Get(name)
+	if kerrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return shippererrors.NewKubeclientGetError(namespace, name, err).
+			WithCoreV1Kind("ConfigMap")
+	}
+
+	if configMap.Data[anchor.PodTrafficStatus] == value {
+		return nil
+	}
+
+	patch, _ := json.Marshal(map[string]interface{}{
+		"data": map[string]string{
+			anchor.PodTrafficStatus: value,
+		},
+	})
+
+	_, err = clientset.CoreV1().ConfigMaps(namespace).
+		Patch(name, types.MergePatchType, patch)
+	if err != nil {
+		return shippererrors.NewKubeclientPatchError(namespace, name, err).
+			WithCoreV1Kind("ConfigMap")
+	}
+
+	return nil
+}
@@ -55,6 +55,8 @@ type Controller struct {
 	trafficTargetsSynced cache.InformerSynced
 	workqueue            workqueue.RateLimitingInterface
 	recorder             record.EventRecorder
+
+	trafficBackends map[shipper.TrafficBackendType]TrafficBackend
 }
 
 // NewController returns a new TrafficTarget controller.
@@ -63,6 +65,7 @@ func NewController(
 	shipperInformerFactory informers.SharedInformerFactory,
 	store clusterclientstore.Interface,
 	recorder record.EventRecorder,
+	dynamicClientBuilderFunc DynamicClientBuilderFunc,
 ) *Controller {
 
 	// Obtain references to shared index informers for the TrafficTarget type.
@@ -76,6 +79,11 @@ func NewController(
 		trafficTargetsSynced: trafficTargetInformer.Informer().HasSynced,
 		workqueue:            workqueue.NewNamedRateLimitingQueue(shipperworkqueue.NewDefaultControllerRateLimiter(), "traffic_controller_traffictargets"),
 		recorder:             recorder,
+
+		trafficBackends: map[shipper.TrafficBackendType]TrafficBackend{
+			shipper.TrafficBackendPodLabels: podLabelBackend{},
+			shipper.TrafficBackendIstio:     newIstioBackend(store, dynamicClientBuilderFunc),
+		},
 	}
 
 	klog.Info("Setting up event handlers")
@@ -126,6 +134,7 @@ func (c *Controller) subscribeToAppClusterEvents(informerFactory kubeinformers.S
 	informerFactory.Core().V1().Pods().Informer()
 	informerFactory.Core().V1().Services().Informer()
 	informerFactory.Core().V1().Endpoints().Informer()
+	informerFactory.Core().V1().ConfigMaps().Informer()
 }
 
 // Run will set up the event handlers for types we are interested in, as well as
@@ -338,6 +347,18 @@ func (c *Controller) processTrafficTargetOnCluster(
 		c.reportConditionChange(tt, ClusterTrafficConditionChanged, diff)
 	}()
 
+	backend, err := c.getTrafficBackend(tt)
+	if err != nil {
+		operationalCond = trafficutil.NewClusterTrafficCondition(
+			shipper.ClusterConditionTypeOperational,
+			corev1.ConditionFalse,
+			InternalError,
+			err.Error(),
+		)
+
+		return err
+	}
+
 	clientset, err := c.clusterClientStore.GetClient(spec.Name, AgentName)
 	if err != nil {
 		operationalCond = trafficutil.NewClusterTrafficCondition(
@@ -353,7 +374,7 @@ func (c *Controller) processTrafficTargetOnCluster(
 	appName := tt.Labels[shipper.AppLabel]
 	releaseName := tt.Labels[shipper.ReleaseLabel]
 
-	appPods, endpoints, err := c.getClusterObjects(spec.Name, tt.Namespace, appName)
+	appPods, service, endpoints, err := c.getClusterObjects(spec.Name, tt.Namespace, appName)
 	if err != nil {
 		operationalCond = trafficutil.NewClusterTrafficCondition(
 			shipper.ClusterConditionTypeOperational,
@@ -372,10 +393,40 @@ func (c *Controller) processTrafficTargetOnCluster(
 		"",
 	)
 
-	trafficStatus := buildTrafficShiftingStatus(
-		spec.Name, appName, releaseName,
-		clusterReleaseWeights,
-		endpoints, appPods)
+	podTrafficStatus := podTrafficStatusForRelease(spec.Name, releaseName, clusterReleaseWeights)
+	err = c.publishPodTrafficStatus(clientset, spec.Name, tt.Namespace, releaseName, podTrafficStatus)
+	if err != nil {
+		readyCond = trafficutil.NewClusterTrafficCondition(
+			shipper.ClusterConditionTypeReady,
+			corev1.ConditionFalse,
+			InternalError,
+			err.Error(),
+		)
+
+		return err
+	}
+
+	trafficStatus, err := backend.Shift(&clusterTraffic{
+		cluster:               spec.Name,
+		clientset:             clientset,
+		namespace:             tt.Namespace,
+		appName:               appName,
+		releaseName:           releaseName,
+		clusterReleaseWeights: clusterReleaseWeights,
+		service:               service,
+		endpoints:             endpoints,
+		appPods:               appPods,
+	})
+	if err != nil {
+		readyCond = trafficutil.NewClusterTrafficCondition(
+			shipper.ClusterConditionTypeReady,
+			corev1.ConditionFalse,
+			InternalError,
+			err.Error(),
+		)
+
+		return err
+	}
 
 	// achievedTraffic is used by the defer at the top of this func
 	achievedTraffic = trafficStatus.achievedTrafficWeight
@@ -392,21 +443,8 @@ func (c *Controller) processTrafficTargetOnCluster(
 	}
 
 	if trafficStatus.podsToShift != nil {
-		// If we have pods to shift, our job can only be done after the
-		// change is made and observed, so we definitely still in
-		// progress.
-		err := shiftPodLabels(clientset, trafficStatus.podsToShift)
-		if err != nil {
-			readyCond = trafficutil.NewClusterTrafficCondition(
-				shipper.ClusterConditionTypeReady,
-				corev1.ConditionFalse,
-				InternalError,
-				err.Error(),
-			)
-
-			return err
-		}
-
+		// If we had pods to shift, our job can only be done after the
+		// change is observed, so we're definitely still in progress.
 		readyCond = trafficutil.NewClusterTrafficCondition(
 			shipper.ClusterConditionTypeReady,
 			corev1.ConditionFalse,
@@ -444,17 +482,17 @@ func (c *Controller) processTrafficTargetOnCluster(
 	return nil
 }
 
-func (c *Controller) getClusterObjects(cluster, ns, appName string) ([]*corev1.Pod, *corev1.Endpoints, error) {
+func (c *Controller) getClusterObjects(cluster, ns, appName string) ([]*corev1.Pod, *corev1.Service, *corev1.Endpoints, error) {
 	informerFactory, err := c.clusterClientStore.GetInformerFactory(cluster)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	appSelector := labels.Set{shipper.AppLabel: appName}.AsSelector()
 	appPods, err := informerFactory.Core().V1().Pods().Lister().
 		Pods(ns).List(appSelector)
 	if err != nil {
-		return nil, nil, shippererrors.NewKubeclientListError(
+		return nil, nil, nil, shippererrors.NewKubeclientListError(
 			corev1.SchemeGroupVersion.WithKind("Pod"),
 			ns, appSelector, err)
 	}
@@ -467,14 +505,14 @@ func (c *Controller) getClusterObjects(cluster, ns, appName string) ([]*corev1.P
 	services, err := informerFactory.Core().V1().Services().Lister().
 		Services(ns).List(serviceSelector)
 	if err != nil {
-		return nil, nil, shippererrors.NewKubeclientListError(
+		return nil, nil, nil, shippererrors.NewKubeclientListError(
 			serviceGVK, ns, serviceSelector, err)
 	}
 
 	if len(services) != 1 {
 		err := shippererrors.NewUnexpectedObjectCountFromSelectorError(
 			serviceSelector, serviceGVK, 1, len(services))
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	svc := services[0]
@@ -482,11 +520,11 @@ func (c *Controller) getClusterObjects(cluster, ns, appName string) ([]*corev1.P
 	endpoints, err := informerFactory.Core().V1().Endpoints().Lister().
 		Endpoints(svc.Namespace).Get(svc.Name)
 	if err != nil {
-		return nil, nil, shippererrors.NewKubeclientGetError(svc.Namespace, svc.Name, err).
+		return nil, nil, nil, shippererrors.NewKubeclientGetError(svc.Namespace, svc.Name, err).
 			WithCoreV1Kind("Endpoints")
 	}
 
-	return appPods, endpoints, nil
+	return appPods, svc, endpoints, nil
 }
 
 // enqueueTrafficTarget takes a TrafficTarget resource and converts it into a
@@ -12,10 +12,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	"github.com/bookingcom/shipper/pkg/util/anchor"
 	targetutil "github.com/bookingcom/shipper/pkg/util/target"
 	trafficutil "github.com/bookingcom/shipper/pkg/util/traffic"
 )
@@ -249,17 +252,72 @@ func TestTrafficShiftingWithPodsNotReady(t *testing.T) {
 	)
 }
 
+// TestPublishesPodTrafficStatus verifies that the traffic controller records
+// on the anchor of each release whether its new pods should get traffic, for
+// the pod labelling webhook to pick up.
+func TestPublishesPodTrafficStatus(t *testing.T) {
+	foobarA := buildTrafficTarget(
+		shippertesting.TestApp, "foobar-a",
+		map[string]uint32{clusterA: 100},
+	)
+	foobarB := buildTrafficTarget(
+		shippertesting.TestApp, "foobar-b",
+		map[string]uint32{clusterA: 0},
+	)
+
+	objects := []runtime.Object{
+		buildService(shippertesting.TestApp),
+		buildEndpoints(shippertesting.TestApp),
+		buildAnchor(foobarA.Name),
+		buildAnchor(foobarB.Name),
+	}
+	objects = addPodsToList(objects,
+		buildPods(shippertesting.TestApp, foobarA.Name, 1, noTraffic))
+	objects = addPodsToList(objects,
+		buildPods(shippertesting.TestApp, foobarB.Name, 1, withTraffic))
+
+	f := shippertesting.NewControllerTestFixture()
+	f.AddNamedCluster(clusterA).AddMany(objects)
+	f.ShipperClient.Tracker().Add(foobarA)
+	f.ShipperClient.Tracker().Add(foobarB)
+
+	runController(f)
+
+	expected := map[string]string{
+		foobarA.Name: shipper.Enabled,
+		foobarB.Name: shipper.Disabled,
+	}
+
+	configMapGVR := corev1.SchemeGroupVersion.WithResource("configmaps")
+	for release, podTrafficStatus := range expected {
+		name := anchor.ReleaseAnchorName(release)
+		object, err := f.Clusters[clusterA].Client.Tracker().Get(
+			configMapGVR, shippertesting.TestNamespace, name)
+		if err != nil {
+			t.Errorf("could not Get ConfigMap %q: %s", name, err)
+			continue
+		}
+
+		got := object.(*corev1.ConfigMap).Data[anchor.PodTrafficStatus]
+		if got != podTrafficStatus {
+			t.Errorf("expected anchor %q to have pod traffic status %q, got %q",
+				name, podTrafficStatus, got)
+		}
+	}
+}
+
 func runTrafficControllerTest(
 	t *testing.T,
 	objectsByCluster map[string][]runtime.Object,
 	expectations []trafficTargetTestExpectation,
-) {
+) *shippertesting.ControllerTestFixture {
 	f := shippertesting.NewControllerTestFixture()
 
 	clusterNames := []string{}
 	for clusterName, objects := range objectsByCluster {
 		cluster := f.AddNamedCluster(clusterName)
 		cluster.AddMany(objects)
+		cluster.InitializeDynamicClient(nil)
 		clusterNames = append(clusterNames, clusterName)
 	}
 
@@ -304,6 +362,8 @@ func runTrafficControllerTest(
 			assertPodTraffic(t, tt, f.Clusters[clusterName], expectedPods)
 		}
 	}
+
+	return f
 }
 
 func assertPodTraffic(
@@ -373,6 +433,14 @@ func runController(f *shippertesting.ControllerTestFixture) {
 		f.ShipperInformerFactory,
 		f.ClusterClientStore,
 		f.Recorder,
+		func(clusterName string, restConfig *rest.Config) (dynamic.Interface, error) {
+			cluster, ok := f.Clusters[clusterName]
+			if !ok || cluster.DynamicClient == nil {
+				return nil, fmt.Errorf("no dynamic client for cluster %q", clusterName)
+			}
+
+			return cluster.DynamicClient, nil
+		},
 	)
 
 	stopCh := make(chan struct{})
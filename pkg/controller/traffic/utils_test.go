@@ -9,6 +9,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/bookingcom/shipper/pkg/util/anchor"
 )
 
 const (
@@ -186,6 +188,18 @@ func buildEndpoints(app string) *corev1.Endpoints {
 	}
 }
 
+func buildAnchor(release string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      anchor.ReleaseAnchorName(release),
+			Namespace: shippertesting.TestNamespace,
+		},
+		Data: map[string]string{
+			anchor.InstallationTargetUID: release,
+		},
+	}
+}
+
 var podId int
 
 func buildPods(app, release string, count int, withTraffic bool) []*corev1.Pod {
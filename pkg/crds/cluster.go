@@ -26,6 +26,9 @@ var Cluster = &apiextensionv1beta1.CustomResourceDefinition{
 			Categories: []string{"shipper"},
 		},
 		Scope: apiextensionv1beta1.ClusterScoped,
+		Subresources: &apiextensionv1beta1.CustomResourceSubresources{
+			Status: &apiextensionv1beta1.CustomResourceSubresourceStatus{},
+		},
 		Validation: &apiextensionv1beta1.CustomResourceValidation{
 			OpenAPIV3Schema: &apiextensionv1beta1.JSONSchemaProps{
 				Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
@@ -39,6 +42,9 @@ var Cluster = &apiextensionv1beta1.CustomResourceDefinition{
 							"region": apiextensionv1beta1.JSONSchemaProps{
 								Type: "string",
 							},
+							"zone": apiextensionv1beta1.JSONSchemaProps{
+								Type: "string",
+							},
 							"apiMaster": apiextensionv1beta1.JSONSchemaProps{
 								Type: "string",
 							},
@@ -54,14 +54,29 @@ var environmentValidation = apiextensionv1beta1.JSONSchemaProps{
 						},
 					},
 				},
+				"affinity":     clusterAffinityValidation,
+				"antiAffinity": clusterAffinityValidation,
 			},
 		},
 		"strategy": apiextensionv1beta1.JSONSchemaProps{
 			Type: "object",
-			Required: []string{
-				"steps",
-			},
 			Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+				"blueGreen": apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+						"incumbentRetention": apiextensionv1beta1.JSONSchemaProps{
+							Type:    "string",
+							Pattern: `^(manual|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`,
+						},
+					},
+				},
+				"progressDeadlineSeconds": apiextensionv1beta1.JSONSchemaProps{
+					Type:    "integer",
+					Minimum: &zero,
+				},
+				"rollbackOnProgressDeadline": apiextensionv1beta1.JSONSchemaProps{
+					Type: "boolean",
+				},
 				"steps": apiextensionv1beta1.JSONSchemaProps{
 					Type: "array",
 					Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
@@ -76,11 +91,79 @@ var environmentValidation = apiextensionv1beta1.JSONSchemaProps{
 								"name": apiextensionv1beta1.JSONSchemaProps{
 									Type: "string",
 								},
+								"pause": apiextensionv1beta1.JSONSchemaProps{
+									Type:    "string",
+									Pattern: `^(manual|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`,
+								},
+								"approvals": apiextensionv1beta1.JSONSchemaProps{
+									Type:    "integer",
+									Minimum: &zero,
+								},
+								"clusters": apiextensionv1beta1.JSONSchemaProps{
+									Type: "object",
+									Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+										"names": apiextensionv1beta1.JSONSchemaProps{
+											Type: "array",
+											Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+												Schema: &apiextensionv1beta1.JSONSchemaProps{
+													Type: "string",
+												},
+											},
+										},
+										"selector": apiextensionv1beta1.JSONSchemaProps{
+											Type: "object",
+										},
+									},
+								},
+								"analysis": apiextensionv1beta1.JSONSchemaProps{
+									Type: "array",
+									Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+										Schema: &apiextensionv1beta1.JSONSchemaProps{
+											Type: "object",
+											Required: []string{
+												"name",
+												"query",
+												"operator",
+												"threshold",
+											},
+											Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+												"name": apiextensionv1beta1.JSONSchemaProps{
+													Type: "string",
+												},
+												"query": apiextensionv1beta1.JSONSchemaProps{
+													Type: "string",
+												},
+												"operator": apiextensionv1beta1.JSONSchemaProps{
+													Type: "string",
+													Enum: []apiextensionv1beta1.JSON{
+														apiextensionv1beta1.JSON{Raw: []byte(`"<"`)},
+														apiextensionv1beta1.JSON{Raw: []byte(`"<="`)},
+														apiextensionv1beta1.JSON{Raw: []byte(`">"`)},
+														apiextensionv1beta1.JSON{Raw: []byte(`">="`)},
+													},
+												},
+												"threshold": apiextensionv1beta1.JSONSchemaProps{
+													Type: "number",
+												},
+												"interval": apiextensionv1beta1.JSONSchemaProps{
+													Type: "string",
+												},
+												"count": apiextensionv1beta1.JSONSchemaProps{
+													Type:    "integer",
+													Minimum: &zero,
+												},
+												"failureLimit": apiextensionv1beta1.JSONSchemaProps{
+													Type:    "integer",
+													Minimum: &zero,
+												},
+											},
+										},
+									},
+								},
 								"capacity": apiextensionv1beta1.JSONSchemaProps{
 									Type: "object",
 									Required: []string{
 										"incumbent",
-										"contender",
 									},
 									Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
 										"incumbent": apiextensionv1beta1.JSONSchemaProps{
@@ -93,6 +176,19 @@ var environmentValidation = apiextensionv1beta1.JSONSchemaProps{
 											Minimum: &zero,
 											Maximum: &hundred,
 										},
+										"contenderReplicas": apiextensionv1beta1.JSONSchemaProps{
+											Type: "object",
+											Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+												"perCluster": apiextensionv1beta1.JSONSchemaProps{
+													Type:    "integer",
+													Minimum: &zero,
+												},
+												"total": apiextensionv1beta1.JSONSchemaProps{
+													Type:    "integer",
+													Minimum: &zero,
+												},
+											},
+										},
 									},
 								},
 								"traffic": apiextensionv1beta1.JSONSchemaProps{
@@ -121,5 +217,50 @@ var environmentValidation = apiextensionv1beta1.JSONSchemaProps{
 		"values": apiextensionv1beta1.JSONSchemaProps{
 			Type: "object",
 		},
+		"trafficBackend": trafficBackendValidation,
+	},
+}
+
+var trafficBackendValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type: "string",
+	Enum: []apiextensionv1beta1.JSON{
+		apiextensionv1beta1.JSON{Raw: []byte(`"podLabels"`)},
+		apiextensionv1beta1.JSON{Raw: []byte(`"istio"`)},
+	},
+}
+
+var clusterAffinityValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type: "object",
+	Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+		"required": apiextensionv1beta1.JSONSchemaProps{
+			Type: "array",
+			Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+				Schema: &apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+				},
+			},
+		},
+		"preferred": apiextensionv1beta1.JSONSchemaProps{
+			Type: "array",
+			Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+				Schema: &apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+					Required: []string{
+						"weight",
+						"selector",
+					},
+					Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+						"weight": apiextensionv1beta1.JSONSchemaProps{
+							Type:    "integer",
+							Minimum: &one,
+							Maximum: &hundred,
+						},
+						"selector": apiextensionv1beta1.JSONSchemaProps{
+							Type: "object",
+						},
+					},
+				},
+			},
+		},
 	},
 }
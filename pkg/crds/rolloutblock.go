@@ -53,6 +53,64 @@ var RolloutBlock = &apiextensionv1beta1.CustomResourceDefinition{
 									},
 								},
 							},
+							"startTime": apiextensionv1beta1.JSONSchemaProps{
+								Type:   "string",
+								Format: "date-time",
+							},
+							"endTime": apiextensionv1beta1.JSONSchemaProps{
+								Type:   "string",
+								Format: "date-time",
+							},
+							"schedule": apiextensionv1beta1.JSONSchemaProps{
+								Type: "object",
+								Required: []string{
+									"start",
+									"duration",
+								},
+								Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+									"start": apiextensionv1beta1.JSONSchemaProps{
+										Type: "string",
+									},
+									"duration": apiextensionv1beta1.JSONSchemaProps{
+										Type: "string",
+									},
+									"timeZone": apiextensionv1beta1.JSONSchemaProps{
+										Type: "string",
+									},
+								},
+							},
+							"scope": apiextensionv1beta1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+									"selector": apiextensionv1beta1.JSONSchemaProps{
+										Type: "object",
+									},
+									"clusters": apiextensionv1beta1.JSONSchemaProps{
+										Type: "array",
+										Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+											Schema: &apiextensionv1beta1.JSONSchemaProps{
+												Type: "string",
+											},
+										},
+									},
+									"regions": apiextensionv1beta1.JSONSchemaProps{
+										Type: "array",
+										Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+											Schema: &apiextensionv1beta1.JSONSchemaProps{
+												Type: "string",
+											},
+										},
+									},
+									"charts": apiextensionv1beta1.JSONSchemaProps{
+										Type: "array",
+										Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+											Schema: &apiextensionv1beta1.JSONSchemaProps{
+												Type: "string",
+											},
+										},
+									},
+								},
+							},
 						},
 					},
 				},
@@ -66,6 +124,13 @@ var RolloutBlock = &apiextensionv1beta1.CustomResourceDefinition{
 				JSONPath:    ".spec.message",
 				Priority:    0,
 			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Active",
+				Type:        "boolean",
+				Description: "Whether this rollout block is currently in effect.",
+				JSONPath:    ".status.active",
+				Priority:    0,
+			},
 			apiextensionv1beta1.CustomResourceColumnDefinition{
 				Name:        "Author Type",
 				Type:        "string",
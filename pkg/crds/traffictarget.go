@@ -63,6 +63,7 @@ var TrafficTarget = &apiextensionv1beta1.CustomResourceDefinition{
 							"clusters",
 						},
 						Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+							"backend": trafficBackendValidation,
 							"clusters": apiextensionv1beta1.JSONSchemaProps{
 								Type: "array",
 								Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
@@ -0,0 +1,45 @@
+package errors
+
+import (
+	"fmt"
+)
+
+type AnalysisQueryError struct {
+	query string
+	err   error
+}
+
+func (e AnalysisQueryError) Error() string {
+	return fmt.Sprintf("failed to run analysis query %q: %s", e.query, e.err)
+}
+
+func (e AnalysisQueryError) ShouldRetry() bool {
+	return true
+}
+
+func NewAnalysisQueryError(query string, err error) AnalysisQueryError {
+	return AnalysisQueryError{
+		query: query,
+		err:   err,
+	}
+}
+
+type InvalidAnalysisError struct {
+	name string
+	err  error
+}
+
+func (e InvalidAnalysisError) Error() string {
+	return fmt.Sprintf("invalid analysis %q: %s", e.name, e.err)
+}
+
+func (e InvalidAnalysisError) ShouldRetry() bool {
+	return false
+}
+
+func NewInvalidAnalysisError(name string, err error) InvalidAnalysisError {
+	return InvalidAnalysisError{
+		name: name,
+		err:  err,
+	}
+}
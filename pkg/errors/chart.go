@@ -104,7 +104,7 @@ type WrongChartDeploymentsError struct {
 
 func (e WrongChartDeploymentsError) Error() string {
 	return fmt.Sprintf(
-		"chart %s-%s should have exactly 1 Deployment object, but it has %d",
+		"chart %s-%s should have exactly 1 Deployment or StatefulSet object, but it has %d",
 		e.chartName,
 		e.chartVersion,
 		e.deploymentCount,
@@ -0,0 +1,27 @@
+package errors
+
+import (
+	"fmt"
+)
+
+type NotificationDeliveryError struct {
+	endpoint string
+	err      error
+	retry    bool
+}
+
+func (e NotificationDeliveryError) Error() string {
+	return fmt.Sprintf("failed to deliver notification to endpoint %q: %s", e.endpoint, e.err)
+}
+
+func (e NotificationDeliveryError) ShouldRetry() bool {
+	return e.retry
+}
+
+func NewNotificationDeliveryError(endpoint string, err error, retry bool) NotificationDeliveryError {
+	return NotificationDeliveryError{
+		endpoint: endpoint,
+		err:      err,
+		retry:    retry,
+	}
+}
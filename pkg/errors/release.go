@@ -128,6 +128,53 @@ func NewNotEnoughClustersInRegionError(region string, required, available int) N
 	}
 }
 
+type NotEnoughZonesInRegionError struct {
+	region    string
+	required  int
+	available int
+}
+
+func (e NotEnoughZonesInRegionError) Error() string {
+	return fmt.Sprintf("Not enough zones in region %q. Required: %d / Available: %d", e.region, e.required, e.available)
+}
+
+func (e NotEnoughZonesInRegionError) ShouldRetry() bool {
+	return false
+}
+
+func NewNotEnoughZonesInRegionError(region string, required, available int) NotEnoughZonesInRegionError {
+	return NotEnoughZonesInRegionError{
+		region:    region,
+		required:  required,
+		available: available,
+	}
+}
+
+type InvalidZoneSpreadError struct {
+	region   string
+	zones    int
+	replicas int
+}
+
+func (e InvalidZoneSpreadError) Error() string {
+	return fmt.Sprintf(
+		"Region %q requires clusters in %d zones, but only %d replicas",
+		e.region, e.zones, e.replicas,
+	)
+}
+
+func (e InvalidZoneSpreadError) ShouldRetry() bool {
+	return false
+}
+
+func NewInvalidZoneSpreadError(region string, zones, replicas int) InvalidZoneSpreadError {
+	return InvalidZoneSpreadError{
+		region:   region,
+		zones:    zones,
+		replicas: replicas,
+	}
+}
+
 type NotEnoughCapableClustersInRegionError struct {
 	region       string
 	capabilities []string
@@ -8,6 +8,7 @@ import (
 	"k8s.io/client-go/rest"
 
 	"github.com/bookingcom/shipper/pkg/clusterclientstore"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
 )
 
 // FakeClusterClientStore is a fake implementation of a ClusterClientStore,
@@ -62,9 +63,18 @@ func (s *FakeClusterClientStore) GetClient(clusterName string, ua string) (kuber
 }
 
 func (s *FakeClusterClientStore) GetConfig(clusterName string) (*rest.Config, error) {
+	if _, ok := s.clusters[clusterName]; !ok {
+		return nil, shippererrors.NewClusterNotInStoreError(clusterName)
+	}
+
 	return &rest.Config{}, nil
 }
 
 func (s *FakeClusterClientStore) GetInformerFactory(clusterName string) (informers.SharedInformerFactory, error) {
-	return s.clusters[clusterName].InformerFactory, nil
+	cluster, ok := s.clusters[clusterName]
+	if !ok {
+		return nil, shippererrors.NewClusterNotInStoreError(clusterName)
+	}
+
+	return cluster.InformerFactory, nil
 }
@@ -13,6 +13,11 @@ import (
 const (
 	AnchorSuffix          = "-anchor"
 	InstallationTargetUID = "InstallationTargetUID"
+
+	// PodTrafficStatus holds the value of shipper.PodTrafficStatusLabel
+	// that new pods of a release should start with, as last decided by
+	// the traffic controller.
+	PodTrafficStatus = "PodTrafficStatus"
 )
 
 func BelongsToInstallationTarget(configMap *corev1.ConfigMap) bool {
@@ -51,5 +56,11 @@ func CreateConfigMapAnchor(it *shipper.InstallationTarget) *corev1.ConfigMap {
 }
 
 func CreateAnchorName(it *shipper.InstallationTarget) string {
-	return fmt.Sprintf("%s%s", it.Name, AnchorSuffix)
+	return ReleaseAnchorName(it.Name)
+}
+
+// ReleaseAnchorName returns the name of the anchor of a release in an
+// application cluster. Installation targets are named after their release.
+func ReleaseAnchorName(releaseName string) string {
+	return fmt.Sprintf("%s%s", releaseName, AnchorSuffix)
 }
@@ -0,0 +1,12 @@
+package application
+
+import (
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// RebalancingEnabled returns whether an application opted into having its
+// releases moved to other clusters when their clusters are drained or
+// reweighted.
+func RebalancingEnabled(app *shipper.Application) bool {
+	return app.Annotations[shipper.AppRebalanceAnnotation] == shipper.True
+}
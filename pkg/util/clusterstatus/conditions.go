@@ -76,3 +76,14 @@ func IsClusterInstallationReady(conditions []shipper.ClusterInstallationConditio
 
 	return true, ""
 }
+
+// IsClusterInService returns whether a cluster passed its last health check.
+// Clusters that were never probed, e.g. because the cluster controller is not
+// running, are considered in service.
+func IsClusterInService(cluster *shipper.Cluster) bool {
+	if len(cluster.Status.Conditions) == 0 {
+		return true
+	}
+
+	return cluster.Status.InService
+}
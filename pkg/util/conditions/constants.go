@@ -9,4 +9,6 @@ const (
 	BrokenReleaseGeneration             = "BrokenReleaseGeneration"
 	BrokenApplicationObservedGeneration = "BrokenApplicationObservedGeneration"
 	StrategyExecutionFailed             = "StrategyExecutionFailed"
+	AnalysisFailed                      = "AnalysisFailed"
+	ProgressDeadlineExceeded            = "ProgressDeadlineExceeded"
 )
@@ -0,0 +1,154 @@
+package release
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+const (
+	minPreferredClusterWeight = 1
+	maxPreferredClusterWeight = 100
+)
+
+// ValidateClusterAffinity checks that the cluster affinity and anti-affinity
+// of a release are well formed, and that they don't obviously exclude every
+// cluster, such as when the anti-affinity rejects all the clusters the
+// affinity requires.
+func ValidateClusterAffinity(requirements shipper.ClusterRequirements) error {
+	for _, affinity := range []struct {
+		name     string
+		affinity *shipper.ClusterAffinity
+	}{
+		{"cluster affinity", requirements.Affinity},
+		{"cluster anti-affinity", requirements.AntiAffinity},
+	} {
+		if affinity.affinity == nil {
+			continue
+		}
+
+		for _, selector := range affinity.affinity.Required {
+			if _, err := metav1.LabelSelectorAsSelector(&selector); err != nil {
+				return fmt.Errorf("invalid required %s: %s", affinity.name, err)
+			}
+		}
+
+		for _, preferred := range affinity.affinity.Preferred {
+			if _, err := metav1.LabelSelectorAsSelector(&preferred.Selector); err != nil {
+				return fmt.Errorf("invalid preferred %s: %s", affinity.name, err)
+			}
+
+			if preferred.Weight < minPreferredClusterWeight || preferred.Weight > maxPreferredClusterWeight {
+				return fmt.Errorf("invalid preferred %s: weight must be between %d and %d, got %d",
+					affinity.name, minPreferredClusterWeight, maxPreferredClusterWeight, preferred.Weight)
+			}
+		}
+	}
+
+	required := map[string]string{}
+	if requirements.Affinity != nil {
+		for _, selector := range requirements.Affinity.Required {
+			for key, value := range selector.MatchLabels {
+				if other, ok := required[key]; ok && other != value {
+					return fmt.Errorf("cluster affinity can't be satisfied: label %q is required to be both %q and %q",
+						key, other, value)
+				}
+				required[key] = value
+			}
+		}
+	}
+
+	if requirements.AntiAffinity != nil {
+		for _, selector := range requirements.AntiAffinity.Required {
+			if len(selector.MatchExpressions) > 0 {
+				continue
+			}
+
+			excludesAll := true
+			for key, value := range selector.MatchLabels {
+				if v, ok := required[key]; !ok || v != value {
+					excludesAll = false
+					break
+				}
+			}
+
+			if excludesAll {
+				return fmt.Errorf("cluster anti-affinity %q excludes every cluster the release could be scheduled on",
+					labels.Set(selector.MatchLabels).String())
+			}
+		}
+	}
+
+	return nil
+}
+
+// ClusterMatchesAffinity returns whether a cluster satisfies the required
+// cluster affinity and anti-affinity of a release. If it doesn't, the reason
+// is returned as well.
+func ClusterMatchesAffinity(requirements shipper.ClusterRequirements, cluster *shipper.Cluster) (string, bool, error) {
+	clusterLabels := labels.Set(cluster.Labels)
+
+	if requirements.Affinity != nil {
+		for _, s := range requirements.Affinity.Required {
+			selector, err := metav1.LabelSelectorAsSelector(&s)
+			if err != nil {
+				return "", false, err
+			}
+
+			if !selector.Matches(clusterLabels) {
+				return fmt.Sprintf("does not match cluster affinity %q", selector.String()), false, nil
+			}
+		}
+	}
+
+	if requirements.AntiAffinity != nil {
+		for _, s := range requirements.AntiAffinity.Required {
+			selector, err := metav1.LabelSelectorAsSelector(&s)
+			if err != nil {
+				return "", false, err
+			}
+
+			if selector.Matches(clusterLabels) {
+				return fmt.Sprintf("matches cluster anti-affinity %q", selector.String()), false, nil
+			}
+		}
+	}
+
+	return "", true, nil
+}
+
+// ClusterAffinityScore returns how much a release prefers a cluster: the sum
+// of the weights of the preferred affinity selectors the cluster matches,
+// minus the weights of the preferred anti-affinity selectors it matches.
+func ClusterAffinityScore(requirements shipper.ClusterRequirements, cluster *shipper.Cluster) (int, error) {
+	clusterLabels := labels.Set(cluster.Labels)
+	score := 0
+
+	for _, affinity := range []struct {
+		affinity *shipper.ClusterAffinity
+		sign     int
+	}{
+		{requirements.Affinity, 1},
+		{requirements.AntiAffinity, -1},
+	} {
+		if affinity.affinity == nil {
+			continue
+		}
+
+		for _, preferred := range affinity.affinity.Preferred {
+			selector, err := metav1.LabelSelectorAsSelector(&preferred.Selector)
+			if err != nil {
+				return 0, err
+			}
+
+			if selector.Matches(clusterLabels) {
+				score += affinity.sign * int(preferred.Weight)
+			}
+		}
+	}
+
+	return score, nil
+}
@@ -0,0 +1,147 @@
+package release
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func matchLabels(labels map[string]string) metav1.LabelSelector {
+	return metav1.LabelSelector{MatchLabels: labels}
+}
+
+func TestValidateClusterAffinity(t *testing.T) {
+	var tests = []struct {
+		title        string
+		affinity     *shipper.ClusterAffinity
+		antiAffinity *shipper.ClusterAffinity
+		expectedErr  bool
+	}{
+		{
+			"no affinity",
+			nil,
+			nil,
+			false,
+		},
+		{
+			"affinity and anti-affinity on different labels",
+			&shipper.ClusterAffinity{
+				Required: []metav1.LabelSelector{matchLabels(map[string]string{"tier": "gold"})},
+			},
+			&shipper.ClusterAffinity{
+				Required: []metav1.LabelSelector{matchLabels(map[string]string{"legacy": "true"})},
+				Preferred: []shipper.PreferredClusterSelector{
+					{Weight: 50, Selector: matchLabels(map[string]string{"busy": "true"})},
+				},
+			},
+			false,
+		},
+		{
+			"malformed selector",
+			&shipper.ClusterAffinity{
+				Required: []metav1.LabelSelector{
+					{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "tier", Operator: "Maybe", Values: []string{"gold"}},
+						},
+					},
+				},
+			},
+			nil,
+			true,
+		},
+		{
+			"preferred weight out of range",
+			&shipper.ClusterAffinity{
+				Preferred: []shipper.PreferredClusterSelector{
+					{Weight: 0, Selector: matchLabels(map[string]string{"tier": "gold"})},
+				},
+			},
+			nil,
+			true,
+		},
+		{
+			"conflicting required labels",
+			&shipper.ClusterAffinity{
+				Required: []metav1.LabelSelector{
+					matchLabels(map[string]string{"tier": "gold"}),
+					matchLabels(map[string]string{"tier": "silver"}),
+				},
+			},
+			nil,
+			true,
+		},
+		{
+			"anti-affinity excluding what affinity requires",
+			&shipper.ClusterAffinity{
+				Required: []metav1.LabelSelector{matchLabels(map[string]string{"tier": "legacy", "gpu": "true"})},
+			},
+			&shipper.ClusterAffinity{
+				Required: []metav1.LabelSelector{matchLabels(map[string]string{"tier": "legacy"})},
+			},
+			true,
+		},
+		{
+			"anti-affinity on an empty label value nothing requires",
+			nil,
+			&shipper.ClusterAffinity{
+				Required: []metav1.LabelSelector{matchLabels(map[string]string{"legacy": ""})},
+			},
+			false,
+		},
+		{
+			"anti-affinity excluding every cluster",
+			nil,
+			&shipper.ClusterAffinity{
+				Required: []metav1.LabelSelector{{}},
+			},
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidateClusterAffinity(shipper.ClusterRequirements{
+			Affinity:     test.affinity,
+			AntiAffinity: test.antiAffinity,
+		})
+		if test.expectedErr && err == nil {
+			t.Errorf("testing %s: expected an error, got none", test.title)
+		} else if !test.expectedErr && err != nil {
+			t.Errorf("testing %s: unexpected error: %s", test.title, err)
+		}
+	}
+}
+
+func TestClusterAffinityScore(t *testing.T) {
+	requirements := shipper.ClusterRequirements{
+		Affinity: &shipper.ClusterAffinity{
+			Preferred: []shipper.PreferredClusterSelector{
+				{Weight: 50, Selector: matchLabels(map[string]string{"ssd": "true"})},
+				{Weight: 20, Selector: matchLabels(map[string]string{"tier": "gold"})},
+			},
+		},
+		AntiAffinity: &shipper.ClusterAffinity{
+			Preferred: []shipper.PreferredClusterSelector{
+				{Weight: 30, Selector: matchLabels(map[string]string{"tier": "gold"})},
+			},
+		},
+	}
+
+	cluster := &shipper.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "cluster-a",
+			Labels: map[string]string{"ssd": "true", "tier": "gold"},
+		},
+	}
+
+	score, err := ClusterAffinityScore(requirements, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := 40; score != expected {
+		t.Errorf("expected score %d, got %d", expected, score)
+	}
+}
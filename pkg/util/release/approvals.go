@@ -0,0 +1,80 @@
+package release
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// AddApproval records an approval of a strategy step of a release. Who
+// approved it is left for the webhook to fill in with the user Kubernetes
+// authenticates the request as.
+func AddApproval(rel *shipper.Release, step int32, now metav1.Time) error {
+	strategy := Strategy(rel)
+	if strategy == nil || step < 0 || int(step) >= len(strategy.Steps) {
+		return fmt.Errorf("release %s/%s has no step %d", rel.Namespace, rel.Name, step)
+	}
+
+	if strategy.Steps[step].Approvals == 0 {
+		return fmt.Errorf("step %q of release %s/%s does not require approvals",
+			strategy.Steps[step].Name, rel.Namespace, rel.Name)
+	}
+
+	rel.Status.Approvals = append(rel.Status.Approvals, shipper.StepApproval{
+		Step: step,
+		Time: now,
+	})
+
+	return nil
+}
+
+// StepApprovers returns the distinct users that approved a strategy step of
+// a release, in the order they approved it.
+func StepApprovers(rel *shipper.Release, step int32) []string {
+	seen := make(map[string]struct{})
+	approvers := []string{}
+	for _, approval := range rel.Status.Approvals {
+		if approval.Step != step {
+			continue
+		}
+
+		if _, ok := seen[approval.User]; ok {
+			continue
+		}
+
+		seen[approval.User] = struct{}{}
+		approvers = append(approvers, approval.User)
+	}
+
+	return approvers
+}
+
+// MissingApprovals returns how many more approvals a strategy step of a
+// release needs before the release can target it.
+func MissingApprovals(rel *shipper.Release, step int32) int32 {
+	strategy := Strategy(rel)
+	if strategy == nil || step < 0 || int(step) >= len(strategy.Steps) {
+		return 0
+	}
+
+	missing := strategy.Steps[step].Approvals - int32(len(StepApprovers(rel, step)))
+	if missing < 0 {
+		return 0
+	}
+
+	return missing
+}
+
+// ApprovedTargetStep returns the highest step up to the target step of a
+// release that every step before it has enough approvals to reach.
+func ApprovedTargetStep(rel *shipper.Release) int32 {
+	for step := int32(1); step <= rel.Spec.TargetStep; step++ {
+		if MissingApprovals(rel, step) > 0 {
+			return step - 1
+		}
+	}
+
+	return rel.Spec.TargetStep
+}
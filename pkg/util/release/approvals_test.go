@@ -0,0 +1,62 @@
+package release
+
+import (
+	"testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func buildGatedRelease(targetStep int32, approvals ...shipper.StepApproval) *shipper.Release {
+	return &shipper.Release{
+		Spec: shipper.ReleaseSpec{
+			TargetStep: targetStep,
+			Environment: shipper.ReleaseEnvironment{
+				Strategy: &shipper.RolloutStrategy{
+					Steps: []shipper.RolloutStrategyStep{
+						{Name: "staging"},
+						{Name: "50/50", Approvals: 1},
+						{Name: "full on", Approvals: 2},
+					},
+				},
+			},
+		},
+		Status: shipper.ReleaseStatus{
+			Approvals: approvals,
+		},
+	}
+}
+
+func TestApprovedTargetStep(t *testing.T) {
+	alice50 := shipper.StepApproval{Step: 1, User: "alice"}
+	aliceFull := shipper.StepApproval{Step: 2, User: "alice"}
+	bobFull := shipper.StepApproval{Step: 2, User: "bob"}
+
+	var tests = []struct {
+		title    string
+		rel      *shipper.Release
+		expected int32
+	}{
+		{"first step", buildGatedRelease(0), 0},
+		{"no approvals", buildGatedRelease(2), 0},
+		{"first gate approved", buildGatedRelease(2, alice50), 1},
+		{"duplicate approvals", buildGatedRelease(2, alice50, aliceFull, aliceFull), 1},
+		{"all gates approved", buildGatedRelease(2, alice50, aliceFull, bobFull), 2},
+		{"later gate approved only", buildGatedRelease(2, aliceFull, bobFull), 0},
+	}
+	for _, test := range tests {
+		actual := ApprovedTargetStep(test.rel)
+		if actual != test.expected {
+			t.Fatalf("testing %s: expected %d, actual %d", test.title, test.expected, actual)
+		}
+	}
+}
+
+func TestMissingApprovals(t *testing.T) {
+	rel := buildGatedRelease(2, shipper.StepApproval{Step: 2, User: "alice"})
+
+	for step, expected := range []int32{0, 1, 1} {
+		if actual := MissingApprovals(rel, int32(step)); actual != expected {
+			t.Fatalf("step %d: expected %d missing approvals, got %d", step, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package release
+
+import (
+	"fmt"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+const (
+	BlueGreenStepGreen int32 = iota
+	BlueGreenStepSwitch
+	BlueGreenStepRetire
+)
+
+// BlueGreenSteps returns the strategy steps a blue/green rollout goes
+// through: the contender is brought to full capacity without any traffic,
+// traffic is then switched over to it while the incumbent is retained at full
+// capacity, and the incumbent is finally scaled down.
+func BlueGreenSteps(blueGreen *shipper.BlueGreenStrategy) []shipper.RolloutStrategyStep {
+	full := shipper.RolloutStrategyStepValue{Incumbent: 100, Contender: 100}
+
+	return []shipper.RolloutStrategyStep{
+		BlueGreenStepGreen: {
+			Name:     "green",
+			Capacity: full,
+			Traffic:  shipper.RolloutStrategyStepValue{Incumbent: 100, Contender: 0},
+		},
+		BlueGreenStepSwitch: {
+			Name:     "switch",
+			Capacity: full,
+			Traffic:  shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+			Pause:    blueGreen.IncumbentRetention,
+		},
+		BlueGreenStepRetire: {
+			Name:     "retire",
+			Capacity: shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+			Traffic:  shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+		},
+	}
+}
+
+// Strategy returns the strategy a release rolls out with. Blue/green
+// strategies get their steps generated, so this should be used instead of
+// looking at the steps in the release environment.
+func Strategy(rel *shipper.Release) *shipper.RolloutStrategy {
+	strategy := rel.Spec.Environment.Strategy
+	if strategy == nil || strategy.BlueGreen == nil {
+		return strategy
+	}
+
+	strategy = strategy.DeepCopy()
+	strategy.Steps = BlueGreenSteps(strategy.BlueGreen)
+
+	return strategy
+}
+
+// ValidateBlueGreen checks that a blue/green strategy does not define any
+// steps of its own and that its retention period is well formed.
+func ValidateBlueGreen(strategy *shipper.RolloutStrategy) error {
+	if strategy.BlueGreen == nil {
+		return nil
+	}
+
+	if len(strategy.Steps) > 0 {
+		return fmt.Errorf("blue/green strategies must not define steps")
+	}
+
+	retention := BlueGreenSteps(strategy.BlueGreen)[BlueGreenStepSwitch]
+	if _, _, err := StepPauseDuration(retention); err != nil {
+		return fmt.Errorf("invalid incumbent retention: %s", err)
+	}
+
+	return nil
+}
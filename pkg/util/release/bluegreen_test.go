@@ -0,0 +1,81 @@
+package release
+
+import (
+	"testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestBlueGreenStrategy(t *testing.T) {
+	rel := &shipper.Release{
+		Spec: shipper.ReleaseSpec{
+			Environment: shipper.ReleaseEnvironment{
+				Strategy: &shipper.RolloutStrategy{
+					BlueGreen: &shipper.BlueGreenStrategy{IncumbentRetention: "1h"},
+				},
+			},
+		},
+	}
+
+	strategy := Strategy(rel)
+	if len(strategy.Steps) != 3 {
+		t.Fatalf("expected 3 generated steps, got %d", len(strategy.Steps))
+	}
+
+	switchStep := strategy.Steps[BlueGreenStepSwitch]
+	if switchStep.Pause != "1h" {
+		t.Fatalf("expected the incumbent to be retained for 1h, got pause %q", switchStep.Pause)
+	}
+
+	if switchStep.Capacity.Incumbent != 100 || switchStep.Traffic.Incumbent != 0 {
+		t.Fatalf("expected the incumbent to keep full capacity without traffic, got %+v", switchStep)
+	}
+
+	if len(rel.Spec.Environment.Strategy.Steps) != 0 {
+		t.Fatalf("expected the release strategy to be left untouched")
+	}
+}
+
+func TestValidateBlueGreen(t *testing.T) {
+	var tests = []struct {
+		title    string
+		strategy *shipper.RolloutStrategy
+		valid    bool
+	}{
+		{
+			"steps only",
+			&shipper.RolloutStrategy{Steps: []shipper.RolloutStrategyStep{{Name: "full on"}}},
+			true,
+		},
+		{
+			"manual retention",
+			&shipper.RolloutStrategy{BlueGreen: &shipper.BlueGreenStrategy{}},
+			true,
+		},
+		{
+			"timed retention",
+			&shipper.RolloutStrategy{BlueGreen: &shipper.BlueGreenStrategy{IncumbentRetention: "30m"}},
+			true,
+		},
+		{
+			"invalid retention",
+			&shipper.RolloutStrategy{BlueGreen: &shipper.BlueGreenStrategy{IncumbentRetention: "forever"}},
+			false,
+		},
+		{
+			"steps and blue/green",
+			&shipper.RolloutStrategy{
+				Steps:     []shipper.RolloutStrategyStep{{Name: "full on"}},
+				BlueGreen: &shipper.BlueGreenStrategy{},
+			},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		err := ValidateBlueGreen(tt.strategy)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid %t, got error %v", tt.title, tt.valid, err)
+		}
+	}
+}
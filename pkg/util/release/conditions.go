@@ -109,6 +109,11 @@ func ReleaseComplete(release *shipper.Release) bool {
 	return releasedCond != nil && releasedCond.Status == corev1.ConditionTrue
 }
 
+func ReleaseAborted(release *shipper.Release) bool {
+	abortedCond := GetReleaseCondition(release.Status, shipper.ReleaseConditionTypeAborted)
+	return abortedCond != nil && abortedCond.Status == corev1.ConditionTrue
+}
+
 func ReleaseProgressing(release *shipper.Release) bool {
 	return !(ReleaseComplete(release))
 }
@@ -1,6 +1,13 @@
 package release
 
 import (
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 )
 
@@ -21,7 +28,7 @@ func ReleaseAchievedTargetStep(rel *shipper.Release) bool {
 
 func IsLastStrategyStep(rel *shipper.Release) bool {
 	targetStep := rel.Spec.TargetStep
-	numSteps := len(rel.Spec.Environment.Strategy.Steps)
+	numSteps := len(Strategy(rel).Steps)
 	return targetStep == int32(numSteps-1)
 }
 
@@ -31,3 +38,236 @@ func IsReleaseSteppingBackwards(achievedStep *shipper.AchievedStep, targetStep i
 	}
 	return achievedStep.Step > targetStep
 }
+
+// StepPauseDuration returns how long a release should hold the given step
+// before progressing on its own. The boolean result is false if the step
+// requires a manual command to progress.
+func StepPauseDuration(step shipper.RolloutStrategyStep) (time.Duration, bool, error) {
+	if step.Pause == "" || step.Pause == shipper.RolloutStrategyStepPauseManual {
+		return 0, false, nil
+	}
+
+	d, err := time.ParseDuration(step.Pause)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid pause %q in step %q: %s", step.Pause, step.Name, err)
+	}
+
+	if d < 0 {
+		return 0, false, fmt.Errorf("invalid pause %q in step %q: must not be negative", step.Pause, step.Name)
+	}
+
+	return d, true, nil
+}
+
+// ValidateStepClusters checks that the cluster selection of every step is
+// well formed, and that the last step applies to all clusters, as a release
+// should not be considered complete before every cluster has been rolled
+// out to.
+func ValidateStepClusters(strategy *shipper.RolloutStrategy) error {
+	for i, step := range strategy.Steps {
+		if step.Clusters == nil {
+			continue
+		}
+
+		if i == len(strategy.Steps)-1 {
+			return fmt.Errorf("last step %q must apply to all clusters", step.Name)
+		}
+
+		if step.Clusters.Selector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(step.Clusters.Selector); err != nil {
+				return fmt.Errorf("invalid cluster selector in step %q: %s", step.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// StepAppliesToCluster returns whether a strategy step selects the given
+// cluster. Steps without a cluster selection apply to all clusters.
+func StepAppliesToCluster(step shipper.RolloutStrategyStep, cluster *shipper.Cluster) (bool, error) {
+	if step.Clusters == nil {
+		return true, nil
+	}
+
+	for _, name := range step.Clusters.Names {
+		if name == cluster.Name {
+			return true, nil
+		}
+	}
+
+	if step.Clusters.Selector == nil {
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(step.Clusters.Selector)
+	if err != nil {
+		return false, err
+	}
+
+	return selector.Matches(labels.Set(cluster.Labels)), nil
+}
+
+// ClusterStepValues returns the capacity and traffic a cluster should have
+// at the given step of a strategy. These are the values of the latest step up
+// to the given one that applies to the cluster. A cluster no step has applied
+// to yet keeps all of its capacity and traffic on the incumbent.
+func ClusterStepValues(
+	strategy *shipper.RolloutStrategy,
+	step int32,
+	cluster *shipper.Cluster,
+) (shipper.RolloutStrategyStepValue, shipper.RolloutStrategyStepValue, error) {
+	for i := step; i >= 0; i-- {
+		s := strategy.Steps[i]
+		applies, err := StepAppliesToCluster(s, cluster)
+		if err != nil {
+			return shipper.RolloutStrategyStepValue{}, shipper.RolloutStrategyStepValue{}, err
+		}
+
+		if applies {
+			return s.Capacity, s.Traffic, nil
+		}
+	}
+
+	untouched := shipper.RolloutStrategyStepValue{Incumbent: 100, Contender: 0}
+	return untouched, untouched, nil
+}
+
+// ValidateStepApprovals checks that approvals are not required for the first
+// step of a strategy, as releases always start rolling out from it.
+func ValidateStepApprovals(strategy *shipper.RolloutStrategy) error {
+	for i, step := range strategy.Steps {
+		if step.Approvals < 0 {
+			return fmt.Errorf("invalid approvals in step %q: must not be negative", step.Name)
+		}
+
+		if i == 0 && step.Approvals > 0 {
+			return fmt.Errorf("first step %q can not require approvals", step.Name)
+		}
+	}
+
+	return nil
+}
+
+// ValidateStepReplicas checks that absolute replica counts are only used for
+// contender capacity, and that they are well formed.
+func ValidateStepReplicas(strategy *shipper.RolloutStrategy) error {
+	for _, step := range strategy.Steps {
+		if step.Traffic.ContenderReplicas != nil {
+			return fmt.Errorf("invalid traffic in step %q: replica counts are only valid for capacity", step.Name)
+		}
+
+		count := step.Capacity.ContenderReplicas
+		if count == nil {
+			continue
+		}
+
+		if (count.PerCluster == nil) == (count.Total == nil) {
+			return fmt.Errorf("invalid contender replicas in step %q: exactly one of perCluster and total must be set", step.Name)
+		}
+
+		if (count.PerCluster != nil && *count.PerCluster < 0) || (count.Total != nil && *count.Total < 0) {
+			return fmt.Errorf("invalid contender replicas in step %q: must not be negative", step.Name)
+		}
+	}
+
+	return nil
+}
+
+// HasContenderReplicas returns whether any step of a strategy asks for an
+// absolute number of contender replicas.
+func HasContenderReplicas(strategy *shipper.RolloutStrategy) bool {
+	if strategy == nil {
+		return false
+	}
+
+	for _, step := range strategy.Steps {
+		if step.Capacity.ContenderReplicas != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateStepReplicasFit checks that the absolute replica counts of a
+// strategy fit in the replicas a release requests, given the number of
+// replicas in its chart. A release requests the replicas of the chart in
+// each of its clusters, but regions splitting their replicas between their
+// clusters can give any one of them up to all the replicas of the region.
+func ValidateStepReplicasFit(
+	strategy *shipper.RolloutStrategy,
+	requirements shipper.ClusterRequirements,
+	chartReplicaCount int32,
+) error {
+	var clusterCount int32
+	maxPerCluster := chartReplicaCount
+	for _, region := range requirements.Regions {
+		regionClusters := int32(1)
+		if region.Replicas != nil {
+			regionClusters = *region.Replicas
+		}
+		clusterCount += regionClusters
+
+		distribution := region.ReplicaDistribution
+		if distribution != nil && distribution.Mode != shipper.ReplicaDistributionModeEven {
+			if regionReplicas := chartReplicaCount * regionClusters; regionReplicas > maxPerCluster {
+				maxPerCluster = regionReplicas
+			}
+		}
+	}
+
+	for _, step := range strategy.Steps {
+		count := step.Capacity.ContenderReplicas
+		if count == nil {
+			continue
+		}
+
+		if count.PerCluster != nil && *count.PerCluster > maxPerCluster {
+			return fmt.Errorf("%d contender replicas per cluster in step %q, but the release only requests %d in any cluster",
+				*count.PerCluster, step.Name, maxPerCluster)
+		}
+
+		if total := chartReplicaCount * clusterCount; count.Total != nil && *count.Total > total {
+			return fmt.Errorf("%d contender replicas in total in step %q, but the release only requests %d",
+				*count.Total, step.Name, total)
+		}
+	}
+
+	return nil
+}
+
+// ContenderReplicas returns the absolute number of contender replicas a
+// capacity value asks for in a cluster, given all the clusters a release is
+// scheduled on. Totals are spread as evenly as possible, with the remainder
+// going to the clusters that come first by name. It returns nil if the
+// capacity is a percentage.
+func ContenderReplicas(capacity shipper.RolloutStrategyStepValue, clusterName string, clusterNames []string) *int32 {
+	count := capacity.ContenderReplicas
+	if count == nil {
+		return nil
+	}
+
+	if count.PerCluster != nil {
+		replicas := *count.PerCluster
+		return &replicas
+	}
+
+	if count.Total == nil || len(clusterNames) == 0 {
+		return nil
+	}
+
+	sorted := make([]string, len(clusterNames))
+	copy(sorted, clusterNames)
+	sort.Strings(sorted)
+
+	n := int32(len(sorted))
+	replicas := *count.Total / n
+	for i, name := range sorted {
+		if name == clusterName && int32(i) < *count.Total%n {
+			replicas++
+		}
+	}
+
+	return &replicas
+}
@@ -2,6 +2,9 @@ package release
 
 import (
 	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 )
@@ -60,3 +63,248 @@ func TestIsReleaseSteppingBackwards(t *testing.T) {
 		}
 	}
 }
+
+func TestStepPauseDuration(t *testing.T) {
+	var tests = []struct {
+		title       string
+		pause       string
+		expected    time.Duration
+		expectedOk  bool
+		expectedErr bool
+	}{
+		{"no pause", "", 0, false, false},
+		{"manual pause", shipper.RolloutStrategyStepPauseManual, 0, false, false},
+		{"timed pause", "15m", 15 * time.Minute, true, false},
+		{"zero pause", "0s", 0, true, false},
+		{"negative pause", "-1m", 0, false, true},
+		{"malformed pause", "forever", 0, false, true},
+	}
+	for _, test := range tests {
+		step := shipper.RolloutStrategyStep{Name: "staging", Pause: test.pause}
+		actual, ok, err := StepPauseDuration(step)
+		if (err != nil) != test.expectedErr {
+			t.Fatalf("testing %s: expected error %t, got %v", test.title, test.expectedErr, err)
+		}
+		if actual != test.expected || ok != test.expectedOk {
+			t.Fatalf("testing %s: expected (%s, %t), actual (%s, %t)", test.title, test.expected, test.expectedOk, actual, ok)
+		}
+	}
+}
+
+func TestClusterStepValues(t *testing.T) {
+	strategy := &shipper.RolloutStrategy{
+		Steps: []shipper.RolloutStrategyStep{
+			{
+				Name:     "canary",
+				Capacity: shipper.RolloutStrategyStepValue{Incumbent: 100, Contender: 10},
+				Traffic:  shipper.RolloutStrategyStepValue{Incumbent: 90, Contender: 10},
+				Clusters: &shipper.RolloutStrategyStepClusters{
+					Names: []string{"kube-a"},
+				},
+			},
+			{
+				Name:     "first wave",
+				Capacity: shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+				Traffic:  shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+				Clusters: &shipper.RolloutStrategyStepClusters{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"wave": "first"},
+					},
+				},
+			},
+			{
+				Name:     "full on",
+				Capacity: shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+				Traffic:  shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+			},
+		},
+	}
+
+	untouched := shipper.RolloutStrategyStepValue{Incumbent: 100, Contender: 0}
+	fullOn := shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100}
+
+	var tests = []struct {
+		title            string
+		step             int32
+		cluster          string
+		labels           map[string]string
+		expectedCapacity shipper.RolloutStrategyStepValue
+		expectedTraffic  shipper.RolloutStrategyStepValue
+	}{
+		{"named cluster in first step", 0, "kube-a", nil, strategy.Steps[0].Capacity, strategy.Steps[0].Traffic},
+		{"other cluster in first step", 0, "kube-b", map[string]string{"wave": "first"}, untouched, untouched},
+		{"named cluster keeps previous values", 1, "kube-a", nil, strategy.Steps[0].Capacity, strategy.Steps[0].Traffic},
+		{"selected cluster in second step", 1, "kube-b", map[string]string{"wave": "first"}, fullOn, fullOn},
+		{"unselected cluster in second step", 1, "kube-c", nil, untouched, untouched},
+		{"last step applies everywhere", 2, "kube-c", nil, fullOn, fullOn},
+	}
+	for _, test := range tests {
+		cluster := &shipper.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: test.cluster, Labels: test.labels},
+		}
+		capacity, traffic, err := ClusterStepValues(strategy, test.step, cluster)
+		if err != nil {
+			t.Fatalf("testing %s: unexpected error: %s", test.title, err)
+		}
+		if capacity != test.expectedCapacity || traffic != test.expectedTraffic {
+			t.Fatalf("testing %s: expected (%v, %v), actual (%v, %v)", test.title, test.expectedCapacity, test.expectedTraffic, capacity, traffic)
+		}
+	}
+}
+
+func TestValidateStepClusters(t *testing.T) {
+	restricted := &shipper.RolloutStrategyStepClusters{Names: []string{"kube-a"}}
+	malformed := &shipper.RolloutStrategyStepClusters{
+		Selector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "wave", Operator: "Sometimes"},
+			},
+		},
+	}
+
+	var tests = []struct {
+		title       string
+		steps       []shipper.RolloutStrategyStep
+		expectedErr bool
+	}{
+		{"no waves", []shipper.RolloutStrategyStep{{Name: "a"}, {Name: "b"}}, false},
+		{"restricted first step", []shipper.RolloutStrategyStep{{Name: "a", Clusters: restricted}, {Name: "b"}}, false},
+		{"restricted last step", []shipper.RolloutStrategyStep{{Name: "a"}, {Name: "b", Clusters: restricted}}, true},
+		{"malformed selector", []shipper.RolloutStrategyStep{{Name: "a", Clusters: malformed}, {Name: "b"}}, true},
+	}
+	for _, test := range tests {
+		err := ValidateStepClusters(&shipper.RolloutStrategy{Steps: test.steps})
+		if (err != nil) != test.expectedErr {
+			t.Fatalf("testing %s: expected error %t, got %v", test.title, test.expectedErr, err)
+		}
+	}
+}
+
+func TestValidateStepReplicas(t *testing.T) {
+	two, negative := int32(2), int32(-1)
+
+	var tests = []struct {
+		title       string
+		step        shipper.RolloutStrategyStep
+		expectedErr bool
+	}{
+		{"percentages", shipper.RolloutStrategyStep{Name: "a"}, false},
+		{
+			"per cluster",
+			shipper.RolloutStrategyStep{Name: "a", Capacity: shipper.RolloutStrategyStepValue{
+				ContenderReplicas: &shipper.StepReplicaCount{PerCluster: &two},
+			}},
+			false,
+		},
+		{
+			"both per cluster and total",
+			shipper.RolloutStrategyStep{Name: "a", Capacity: shipper.RolloutStrategyStepValue{
+				ContenderReplicas: &shipper.StepReplicaCount{PerCluster: &two, Total: &two},
+			}},
+			true,
+		},
+		{
+			"neither per cluster nor total",
+			shipper.RolloutStrategyStep{Name: "a", Capacity: shipper.RolloutStrategyStepValue{
+				ContenderReplicas: &shipper.StepReplicaCount{},
+			}},
+			true,
+		},
+		{
+			"negative total",
+			shipper.RolloutStrategyStep{Name: "a", Capacity: shipper.RolloutStrategyStepValue{
+				ContenderReplicas: &shipper.StepReplicaCount{Total: &negative},
+			}},
+			true,
+		},
+		{
+			"traffic",
+			shipper.RolloutStrategyStep{Name: "a", Traffic: shipper.RolloutStrategyStepValue{
+				ContenderReplicas: &shipper.StepReplicaCount{PerCluster: &two},
+			}},
+			true,
+		},
+	}
+	for _, test := range tests {
+		strategy := &shipper.RolloutStrategy{Steps: []shipper.RolloutStrategyStep{test.step}}
+		err := ValidateStepReplicas(strategy)
+		if (err != nil) != test.expectedErr {
+			t.Fatalf("testing %s: expected error %t, got %v", test.title, test.expectedErr, err)
+		}
+	}
+}
+
+func TestValidateStepReplicasFit(t *testing.T) {
+	two, three, six, seven, nine := int32(2), int32(3), int32(6), int32(7), int32(9)
+	chartReplicaCount := int32(3)
+
+	twoClusters := shipper.ClusterRequirements{
+		Regions: []shipper.RegionRequirement{{Name: "eu-west", Replicas: &two}},
+	}
+	weighted := shipper.ClusterRequirements{
+		Regions: []shipper.RegionRequirement{{
+			Name:                "eu-west",
+			Replicas:            &two,
+			ReplicaDistribution: &shipper.ReplicaDistribution{Mode: shipper.ReplicaDistributionModeWeight},
+		}},
+	}
+
+	var tests = []struct {
+		title        string
+		requirements shipper.ClusterRequirements
+		count        shipper.StepReplicaCount
+		expectedErr  bool
+	}{
+		{"per cluster fits", twoClusters, shipper.StepReplicaCount{PerCluster: &three}, false},
+		{"per cluster too many", twoClusters, shipper.StepReplicaCount{PerCluster: &seven}, true},
+		{"per cluster fits a weighted region", weighted, shipper.StepReplicaCount{PerCluster: &six}, false},
+		{"total fits", twoClusters, shipper.StepReplicaCount{Total: &three}, false},
+		{"total too many", twoClusters, shipper.StepReplicaCount{Total: &nine}, true},
+	}
+
+	for _, test := range tests {
+		strategy := &shipper.RolloutStrategy{Steps: []shipper.RolloutStrategyStep{
+			{Name: "a", Capacity: shipper.RolloutStrategyStepValue{ContenderReplicas: &test.count}},
+		}}
+
+		err := ValidateStepReplicasFit(strategy, test.requirements, chartReplicaCount)
+		if (err != nil) != test.expectedErr {
+			t.Errorf("testing %s: expected error %t, got %v", test.title, test.expectedErr, err)
+		}
+	}
+}
+
+func TestContenderReplicas(t *testing.T) {
+	two, five := int32(2), int32(5)
+	clusters := []string{"kube-c", "kube-a", "kube-b"}
+
+	var tests = []struct {
+		title    string
+		count    *shipper.StepReplicaCount
+		expected map[string]int32
+	}{
+		{
+			"per cluster",
+			&shipper.StepReplicaCount{PerCluster: &two},
+			map[string]int32{"kube-a": 2, "kube-b": 2, "kube-c": 2},
+		},
+		{
+			"total",
+			&shipper.StepReplicaCount{Total: &five},
+			map[string]int32{"kube-a": 2, "kube-b": 2, "kube-c": 1},
+		},
+	}
+	for _, test := range tests {
+		capacity := shipper.RolloutStrategyStepValue{ContenderReplicas: test.count}
+		for cluster, expected := range test.expected {
+			replicas := ContenderReplicas(capacity, cluster, clusters)
+			if replicas == nil || *replicas != expected {
+				t.Fatalf("testing %s: expected %d replicas in %q, got %v", test.title, expected, cluster, replicas)
+			}
+		}
+	}
+
+	if replicas := ContenderReplicas(shipper.RolloutStrategyStepValue{Contender: 50}, "kube-a", clusters); replicas != nil {
+		t.Fatalf("expected no replica count for percentages, got %d", *replicas)
+	}
+}
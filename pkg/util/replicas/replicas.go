@@ -2,6 +2,9 @@ package replicas
 
 import (
 	"math"
+	"sort"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 )
 
 // CalculateDesiredNumberOfReplicas extracts the optimal replica count for
@@ -37,3 +40,69 @@ func AchievedDesiredReplicaPercentage(totalReplicaCount, currentReplicaCount, de
 
 	return uint(currentReplicaCount) == CalculateDesiredReplicaCount(uint(totalReplicaCount), float64(desiredPercentage))
 }
+
+// DesiredReplicaCount returns the number of replicas a cluster capacity target
+// asks for: either its absolute replica count when set, or the configured
+// percentage of its total replica count.
+func DesiredReplicaCount(spec shipper.ClusterCapacityTarget) int32 {
+	if spec.Replicas != nil {
+		return *spec.Replicas
+	}
+
+	return int32(CalculateDesiredReplicaCount(uint(spec.TotalReplicaCount), float64(spec.Percent)))
+}
+
+// DistributeReplicas splits totalReplicaCount in proportion to weights,
+// using the largest remainder method so that the parts always add up to
+// totalReplicaCount. Ties go to the earliest weights. Replicas are split
+// evenly if no weight is positive.
+func DistributeReplicas(totalReplicaCount int32, weights []int32) []int32 {
+	parts := make([]int32, len(weights))
+	if len(weights) == 0 {
+		return parts
+	}
+
+	var sum int64
+	for _, weight := range weights {
+		if weight > 0 {
+			sum += int64(weight)
+		}
+	}
+
+	if sum == 0 {
+		even := make([]int32, len(weights))
+		for i := range even {
+			even[i] = 1
+		}
+		return DistributeReplicas(totalReplicaCount, even)
+	}
+
+	type remainder struct {
+		index     int
+		remainder int64
+	}
+
+	remainders := make([]remainder, 0, len(weights))
+	var assigned int32
+	for i, weight := range weights {
+		if weight <= 0 {
+			continue
+		}
+
+		share := int64(totalReplicaCount) * int64(weight)
+		parts[i] = int32(share / sum)
+		assigned += parts[i]
+		remainders = append(remainders, remainder{index: i, remainder: share % sum})
+	}
+
+	sort.SliceStable(remainders, func(i, j int) bool {
+		return remainders[i].remainder > remainders[j].remainder
+	})
+
+	for i := 0; assigned < totalReplicaCount; i++ {
+		parts[remainders[i].index]++
+		assigned++
+	}
+
+	return parts
+}
@@ -0,0 +1,29 @@
+package replicas
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDistributeReplicas(t *testing.T) {
+	var tests = []struct {
+		title    string
+		total    int32
+		weights  []int32
+		expected []int32
+	}{
+		{"no weights", 10, []int32{}, []int32{}},
+		{"proportional", 20, []int32{100, 300}, []int32{5, 15}},
+		{"largest remainder", 10, []int32{1, 1, 1}, []int32{4, 3, 3}},
+		{"zero weight", 10, []int32{0, 100, 100}, []int32{0, 5, 5}},
+		{"all zero weights", 4, []int32{0, 0}, []int32{2, 2}},
+		{"fewer replicas than clusters", 1, []int32{100, 200}, []int32{0, 1}},
+	}
+
+	for _, test := range tests {
+		actual := DistributeReplicas(test.total, test.weights)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("testing %s: expected %v, got %v", test.title, test.expected, actual)
+		}
+	}
+}
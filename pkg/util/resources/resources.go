@@ -0,0 +1,166 @@
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// SchedulingResources are the resources Shipper takes into account when
+// deciding whether a release fits in a cluster.
+var SchedulingResources = []corev1.ResourceName{
+	corev1.ResourceCPU,
+	corev1.ResourceMemory,
+}
+
+// PodRequests returns the resources requested by a pod, following the same
+// rules as Kubernetes: containers with limits but no requests get requests
+// equal to their limits, and since init containers run one at a time before
+// the regular containers, a pod requests the largest of the sum of its
+// containers' requests and of any of its init containers' requests.
+func PodRequests(spec corev1.PodSpec) corev1.ResourceList {
+	return podResources(spec, containerRequests)
+}
+
+// PodLimits returns the resource limits of a pod, following the same rules
+// as PodRequests.
+func PodLimits(spec corev1.PodSpec) corev1.ResourceList {
+	return podResources(spec, func(container corev1.Container) corev1.ResourceList {
+		return container.Resources.Limits
+	})
+}
+
+func podResources(spec corev1.PodSpec, containerResources func(corev1.Container) corev1.ResourceList) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	for _, container := range spec.Containers {
+		Add(list, containerResources(container))
+	}
+
+	for _, container := range spec.InitContainers {
+		Max(list, containerResources(container))
+	}
+
+	return list
+}
+
+func containerRequests(container corev1.Container) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for name, limit := range container.Resources.Limits {
+		requests[name] = limit
+	}
+
+	for name, request := range container.Resources.Requests {
+		requests[name] = request
+	}
+
+	return requests
+}
+
+// Scheduling returns only the scheduling resources in list.
+func Scheduling(list corev1.ResourceList) corev1.ResourceList {
+	scheduling := corev1.ResourceList{}
+	for _, name := range SchedulingResources {
+		if quantity, ok := list[name]; ok {
+			scheduling[name] = quantity.DeepCopy()
+		}
+	}
+
+	return scheduling
+}
+
+// Add adds the resources in b to a.
+func Add(a, b corev1.ResourceList) {
+	for name, quantity := range b {
+		sum := a[name]
+		sum.Add(quantity)
+		a[name] = sum
+	}
+}
+
+// Max sets each resource in a to the largest of itself and the same
+// resource in b.
+func Max(a, b corev1.ResourceList) {
+	for name, quantity := range b {
+		if current, ok := a[name]; !ok || quantity.Cmp(current) > 0 {
+			a[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// Multiply returns the resources in list, times n.
+func Multiply(list corev1.ResourceList, n int32) corev1.ResourceList {
+	product := corev1.ResourceList{}
+	for name, quantity := range list {
+		// Quantities can't be multiplied, but they can be converted to
+		// and from milli-units without losing precision for anything
+		// a pod would reasonably request.
+		product[name] = *resource.NewMilliQuantity(quantity.MilliValue()*int64(n), quantity.Format)
+	}
+
+	return product
+}
+
+// Available returns how much of allocatable is not yet requested.
+func Available(allocatable, requested corev1.ResourceList) corev1.ResourceList {
+	available := corev1.ResourceList{}
+	for _, name := range SchedulingResources {
+		quantity, ok := allocatable[name]
+		if !ok {
+			continue
+		}
+
+		quantity = quantity.DeepCopy()
+		if used, ok := requested[name]; ok {
+			quantity.Sub(used)
+		}
+		available[name] = quantity
+	}
+
+	return available
+}
+
+// Insufficient returns the scheduling resources for which available is
+// smaller than required. Resources missing from available are not taken
+// into account, as there's nothing to compare against.
+func Insufficient(required, available corev1.ResourceList) []corev1.ResourceName {
+	var insufficient []corev1.ResourceName
+	for _, name := range SchedulingResources {
+		want, ok := required[name]
+		if !ok {
+			continue
+		}
+
+		have, ok := available[name]
+		if !ok {
+			continue
+		}
+
+		if want.Cmp(have) > 0 {
+			insufficient = append(insufficient, name)
+		}
+	}
+
+	return insufficient
+}
+
+// String returns a human readable representation of the resources in
+// list, sorted by name, e.g. "cpu=2,memory=1Gi".
+func String(list corev1.ResourceList) string {
+	names := make([]string, 0, len(list))
+	for name := range list {
+		names = append(names, string(name))
+	}
+
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		quantity := list[corev1.ResourceName(name)]
+		parts = append(parts, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+
+	return strings.Join(parts, ",")
+}
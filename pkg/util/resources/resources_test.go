@@ -0,0 +1,104 @@
+package resources
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func requests(cpu, memory string) corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		},
+	}
+}
+
+func TestPodRequests(t *testing.T) {
+	spec := corev1.PodSpec{
+		InitContainers: []corev1.Container{
+			{Resources: requests("2", "64Mi")},
+		},
+		Containers: []corev1.Container{
+			{Resources: requests("500m", "128Mi")},
+			{Resources: requests("250m", "128Mi")},
+		},
+	}
+
+	expected := "cpu=2,memory=256Mi"
+	if got := String(PodRequests(spec)); got != expected {
+		t.Errorf("expected pod requests %q, got %q", expected, got)
+	}
+}
+
+func TestPodRequestsDefaultToLimits(t *testing.T) {
+	spec := corev1.PodSpec{
+		InitContainers: []corev1.Container{
+			{Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+			}},
+		},
+		Containers: []corev1.Container{
+			{Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("256Mi"),
+				},
+			}},
+		},
+	}
+
+	expectedRequests := "cpu=500m,memory=512Mi"
+	if got := String(PodRequests(spec)); got != expectedRequests {
+		t.Errorf("expected pod requests %q, got %q", expectedRequests, got)
+	}
+
+	expectedLimits := "cpu=1,memory=512Mi"
+	if got := String(PodLimits(spec)); got != expectedLimits {
+		t.Errorf("expected pod limits %q, got %q", expectedLimits, got)
+	}
+}
+
+func TestMultiply(t *testing.T) {
+	list := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("250m"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	}
+
+	expected := "cpu=2500m,memory=10Gi"
+	if got := String(Multiply(list, 10)); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestInsufficient(t *testing.T) {
+	allocatable := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("8"),
+		corev1.ResourceMemory: resource.MustParse("16Gi"),
+	}
+	requested := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("7"),
+		corev1.ResourceMemory: resource.MustParse("4Gi"),
+	}
+	available := Available(allocatable, requested)
+
+	fits := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1"),
+		corev1.ResourceMemory: resource.MustParse("12Gi"),
+	}
+	if insufficient := Insufficient(fits, available); len(insufficient) != 0 {
+		t.Errorf("expected requests to fit, got insufficient %v", insufficient)
+	}
+
+	doesNotFit := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1500m"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	}
+	insufficient := Insufficient(doesNotFit, available)
+	if len(insufficient) != 1 || insufficient[0] != corev1.ResourceCPU {
+		t.Errorf("expected insufficient cpu, got %v", insufficient)
+	}
+}
@@ -0,0 +1,73 @@
+package rolloutblock
+
+import (
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// OverrideHistoryLimit is how many override records a rollout block keeps in
+// its status.
+const OverrideHistoryLimit = 20
+
+// AddedOverrides returns the rollout blocks obj overrides that old did not.
+// old may be nil for objects that are being created.
+func AddedOverrides(old, obj metav1.Object) ObjectNameList {
+	overrides := NewObjectNameList(obj.GetAnnotations()[shipper.RolloutBlocksOverrideAnnotation])
+	if old == nil {
+		return overrides
+	}
+
+	oldOverrides := NewObjectNameList(old.GetAnnotations()[shipper.RolloutBlocksOverrideAnnotation])
+	return overrides.Diff(oldOverrides)
+}
+
+// OverrideRecord builds an audit record out of the annotations of an object
+// overriding rollout blocks. It returns false if the overrides were never
+// stamped by the webhook.
+func OverrideRecord(kind string, key string, obj metav1.Object) (shipper.RolloutBlockOverrideRecord, bool) {
+	annotations := obj.GetAnnotations()
+
+	timestamp, err := time.Parse(time.RFC3339, annotations[shipper.RolloutBlocksOverrideTimestampAnnotation])
+	if err != nil {
+		return shipper.RolloutBlockOverrideRecord{}, false
+	}
+
+	return shipper.RolloutBlockOverrideRecord{
+		Kind:          kind,
+		Name:          key,
+		Author:        annotations[shipper.RolloutBlocksOverrideAuthorAnnotation],
+		Timestamp:     metav1.NewTime(timestamp),
+		Justification: annotations[shipper.RolloutBlocksOverrideJustificationAnnotation],
+	}, true
+}
+
+// RecordOverride adds a record to the override history of a rollout block,
+// dropping the oldest ones past OverrideHistoryLimit. It returns false if the
+// record was already there, or is too old to make it into the history.
+func RecordOverride(status *shipper.RolloutBlockStatus, record shipper.RolloutBlockOverrideRecord) bool {
+	history := status.OverrideHistory
+	for _, r := range history {
+		if r.Kind == record.Kind && r.Name == record.Name && r.Timestamp.Equal(&record.Timestamp) {
+			return false
+		}
+	}
+
+	if len(history) >= OverrideHistoryLimit && record.Timestamp.Before(&history[0].Timestamp) {
+		return false
+	}
+
+	status.OverrideHistory = append(history, record)
+	sort.SliceStable(status.OverrideHistory, func(i, j int) bool {
+		return status.OverrideHistory[i].Timestamp.Before(&status.OverrideHistory[j].Timestamp)
+	})
+
+	if excess := len(status.OverrideHistory) - OverrideHistoryLimit; excess > 0 {
+		status.OverrideHistory = status.OverrideHistory[excess:]
+	}
+
+	return true
+}
@@ -0,0 +1,101 @@
+package rolloutblock
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func objectWithOverrides(overrides string) metav1.Object {
+	return &metav1.ObjectMeta{
+		Annotations: map[string]string{
+			shipper.RolloutBlocksOverrideAnnotation: overrides,
+		},
+	}
+}
+
+func TestAddedOverrides(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Old      metav1.Object
+		New      metav1.Object
+		Expected string
+	}{
+		{
+			"created with overrides",
+			nil,
+			objectWithOverrides("ns/block-a"),
+			"ns/block-a",
+		},
+		{
+			"override added",
+			objectWithOverrides("ns/block-a"),
+			objectWithOverrides("ns/block-a,ns/block-b"),
+			"ns/block-b",
+		},
+		{
+			"override removed",
+			objectWithOverrides("ns/block-a,ns/block-b"),
+			objectWithOverrides("ns/block-a"),
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := AddedOverrides(tt.Old, tt.New).String(); got != tt.Expected {
+				t.Errorf("expected added overrides %q, got %q", tt.Expected, got)
+			}
+		})
+	}
+}
+
+func TestOverrideRecordWithoutTimestamp(t *testing.T) {
+	if _, ok := OverrideRecord("Application", "ns/app", objectWithOverrides("ns/block-a")); ok {
+		t.Errorf("expected no record for overrides that were never stamped")
+	}
+}
+
+func TestRecordOverride(t *testing.T) {
+	base := time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC)
+	record := func(name string, minutes int) shipper.RolloutBlockOverrideRecord {
+		return shipper.RolloutBlockOverrideRecord{
+			Kind:      "Release",
+			Name:      name,
+			Timestamp: metav1.NewTime(base.Add(time.Duration(minutes) * time.Minute)),
+		}
+	}
+
+	status := &shipper.RolloutBlockStatus{}
+	for i := 0; i < OverrideHistoryLimit; i++ {
+		if !RecordOverride(status, record(fmt.Sprintf("ns/rel-%d", i), i+1)) {
+			t.Fatalf("expected record %d to be added", i)
+		}
+	}
+
+	if RecordOverride(status, record("ns/rel-0", 1)) {
+		t.Errorf("expected a duplicate record not to be added")
+	}
+
+	if RecordOverride(status, record("ns/rel-old", 0)) {
+		t.Errorf("expected a record older than a full history not to be added")
+	}
+
+	if !RecordOverride(status, record("ns/rel-new", OverrideHistoryLimit+1)) {
+		t.Errorf("expected a new record to be added")
+	}
+
+	history := status.OverrideHistory
+	if len(history) != OverrideHistoryLimit {
+		t.Fatalf("expected history to be limited to %d records, got %d", OverrideHistoryLimit, len(history))
+	}
+
+	if history[0].Name != "ns/rel-1" || history[len(history)-1].Name != "ns/rel-new" {
+		t.Errorf("expected the oldest record to be dropped, got history from %q to %q",
+			history[0].Name, history[len(history)-1].Name)
+	}
+}
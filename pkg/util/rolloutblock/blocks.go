@@ -1,6 +1,8 @@
 package rolloutblock
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -32,7 +34,8 @@ func BlocksRollout(rolloutBlockLister shipperlisters.RolloutBlockLister, obj met
 		return false, events, err
 	}
 
-	existingBlocks := NewObjectNameListFromRolloutBlocksList(append(nsBlocks, globalBlocks...))
+	blocks := append(nsBlocks, globalBlocks...)
+	existingBlocks := NewObjectNameListFromRolloutBlocksList(blocks)
 	obsoleteBlocks := overrides.Diff(existingBlocks)
 
 	if len(obsoleteBlocks) > 0 {
@@ -40,7 +43,12 @@ func BlocksRollout(rolloutBlockLister shipperlisters.RolloutBlockLister, obj met
 		return true, events, err
 	}
 
-	effectiveBlocks := existingBlocks.Diff(overrides)
+	// Overrides are checked against all blocks, so that overriding a block
+	// that is not in effect right now or is scoped to other objects remains
+	// valid, but only blocks that are in effect and apply to obj actually
+	// block rollouts.
+	activeBlocks := NewObjectNameListFromRolloutBlocksList(ScopedBlocks(ActiveBlocks(blocks, time.Now()), obj))
+	effectiveBlocks := activeBlocks.Diff(overrides)
 
 	if len(effectiveBlocks) == 0 {
 		if len(overrides) > 0 {
@@ -60,19 +68,36 @@ func BlocksRollout(rolloutBlockLister shipperlisters.RolloutBlockLister, obj met
 	}
 }
 
-func GetAllBlocks(rolloutBlockLister shipperlisters.RolloutBlockLister, obj metav1.Object) (ObjectNameList, ObjectNameList, error) {
+// GetAllBlocks returns the blocks an object overrides, all the blocks in its
+// namespace and the global ones, and the ones among them that are currently
+// in effect and apply to it.
+func GetAllBlocks(rolloutBlockLister shipperlisters.RolloutBlockLister, obj metav1.Object) (ObjectNameList, ObjectNameList, ObjectNameList, error) {
 	annotations := obj.GetAnnotations()
 	overrides := NewObjectNameList(annotations[shipper.RolloutBlocksOverrideAnnotation])
 	nsBlocks, err := rolloutBlockLister.RolloutBlocks(obj.GetNamespace()).List(labels.Everything())
 	if err != nil {
-		return overrides, nil, err
+		return overrides, nil, nil, err
 	}
 	globalBlocks, err := rolloutBlockLister.RolloutBlocks(shipper.GlobalRolloutBlockNamespace).List(labels.Everything())
 	if err != nil {
-		return overrides, nil, err
+		return overrides, nil, nil, err
+	}
+	blocks := append(nsBlocks, globalBlocks...)
+	existingBlocks := NewObjectNameListFromRolloutBlocksList(blocks)
+	activeBlocks := NewObjectNameListFromRolloutBlocksList(ScopedBlocks(ActiveBlocks(blocks, time.Now()), obj))
+	return overrides, existingBlocks, activeBlocks, nil
+}
+
+// ActiveBlocks filters out the rollout blocks that are not in effect at the
+// given time.
+func ActiveBlocks(rbs []*shipper.RolloutBlock, now time.Time) []*shipper.RolloutBlock {
+	active := make([]*shipper.RolloutBlock, 0, len(rbs))
+	for _, rb := range rbs {
+		if IsActive(rb, now) {
+			active = append(active, rb)
+		}
 	}
-	existingBlocks := NewObjectNameListFromRolloutBlocksList(append(nsBlocks, globalBlocks...))
-	return overrides, existingBlocks, nil
+	return active
 }
 
 func ValidateBlocks(existing, overrides ObjectNameList) error {
@@ -0,0 +1,273 @@
+package rolloutblock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// cronSchedule is a parsed cron expression in the usual 5-field format:
+// minute, hour, day of month, month and day of week.
+type cronSchedule struct {
+	minute, hour, dom, month, dow map[int]bool
+	domStar, dowStar              bool
+	location                      *time.Location
+}
+
+var (
+	monthNames = map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}
+	dayNames = map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}
+)
+
+func parseCron(expr string, location *time.Location) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	s := &cronSchedule{location: location}
+
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid minute in %q: %s", expr, err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid hour in %q: %s", expr, err)
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid day of month in %q: %s", expr, err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("invalid month in %q: %s", expr, err)
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("invalid day of week in %q: %s", expr, err)
+	}
+
+	// Both 0 and 7 are Sunday.
+	if s.dow[7] {
+		s.dow[0] = true
+	}
+
+	s.domStar = fields[2] == "*"
+	s.dowStar = fields[4] == "*"
+
+	return s, nil
+}
+
+func parseCronField(field string, min, max int, names map[string]int) (map[int]bool, error) {
+	values := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step %q", part[i+1:])
+			}
+			part = part[:i]
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+
+			var err error
+			if lo, err = parseCronValue(bounds[0], names); err != nil {
+				return nil, err
+			}
+
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = parseCronValue(bounds[1], names); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
+
+func parseCronValue(value string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(value)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+
+	return v, nil
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom, dow := s.dom[t.Day()], s.dow[int(t.Weekday())]
+
+	// Like in cron, a day matches either of the day of month and day of
+	// week fields when both are restricted.
+	if !s.domStar && !s.dowStar {
+		return dom || dow
+	}
+
+	return dom && dow
+}
+
+// next returns the first time after t the schedule matches, or a zero time
+// if it doesn't match in the next few years.
+func (s *cronSchedule) next(t time.Time) time.Time {
+	t = t.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		y, m, d := t.Date()
+
+		if !s.month[int(m)] {
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+
+		if !s.dayMatches(t) {
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+
+		if !s.hour[t.Hour()] {
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, s.location)
+			continue
+		}
+
+		if !s.minute[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func parseSchedule(schedule *shipper.RolloutBlockSchedule) (*cronSchedule, time.Duration, error) {
+	location := time.UTC
+	if schedule.TimeZone != "" {
+		var err error
+		location, err = time.LoadLocation(schedule.TimeZone)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid time zone %q: %s", schedule.TimeZone, err)
+		}
+	}
+
+	cron, err := parseCron(schedule.Start, location)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	duration, err := time.ParseDuration(schedule.Duration)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid duration %q: %s", schedule.Duration, err)
+	}
+
+	if duration <= 0 {
+		return nil, 0, fmt.Errorf("invalid duration %q: must be positive", schedule.Duration)
+	}
+
+	return cron, duration, nil
+}
+
+// ValidateSpec checks that the time window and schedule of a rollout block
+// are well formed.
+func ValidateSpec(spec shipper.RolloutBlockSpec) error {
+	if spec.StartTime != nil && spec.EndTime != nil && !spec.StartTime.Before(spec.EndTime) {
+		return fmt.Errorf("rollout block must start before it ends")
+	}
+
+	if spec.Schedule != nil {
+		if _, _, err := parseSchedule(spec.Schedule); err != nil {
+			return fmt.Errorf("invalid rollout block schedule: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// Activity returns whether a rollout block is in effect at the given time,
+// and when that is going to change next. The returned time is zero if the
+// block is never going to change state again.
+func Activity(spec shipper.RolloutBlockSpec, now time.Time) (bool, time.Time, error) {
+	var start, end time.Time
+	if spec.StartTime != nil {
+		start = spec.StartTime.Time
+	}
+	if spec.EndTime != nil {
+		end = spec.EndTime.Time
+	}
+
+	if !end.IsZero() && !now.Before(end) {
+		return false, time.Time{}, nil
+	}
+
+	if !start.IsZero() && now.Before(start) {
+		return false, start, nil
+	}
+
+	if spec.Schedule == nil {
+		return true, end, nil
+	}
+
+	cron, duration, err := parseSchedule(spec.Schedule)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	// Look for the latest window that opened before now, and see if it is
+	// still open. Windows can overlap, so the block stays in effect until
+	// the last one of them closes.
+	var windowEnd time.Time
+	for s := cron.next(now.Add(-duration)); !s.IsZero() && !s.After(now); s = cron.next(s) {
+		windowEnd = s.Add(duration)
+	}
+
+	if windowEnd.After(now) {
+		if !end.IsZero() && end.Before(windowEnd) {
+			windowEnd = end
+		}
+		return true, windowEnd, nil
+	}
+
+	next := cron.next(now)
+	if next.IsZero() || (!end.IsZero() && !next.Before(end)) {
+		return false, time.Time{}, nil
+	}
+
+	return false, next, nil
+}
+
+// IsActive returns whether a rollout block is in effect at the given time.
+// Blocks with a malformed schedule are considered to be in effect, so a
+// typo doesn't silently lift a change freeze.
+func IsActive(rb *shipper.RolloutBlock, now time.Time) bool {
+	active, _, err := Activity(rb.Spec, now)
+	if err != nil {
+		return true
+	}
+
+	return active
+}
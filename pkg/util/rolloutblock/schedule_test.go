@@ -0,0 +1,249 @@
+package rolloutblock
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func mustParseTime(t *testing.T, value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("failed to parse time %q: %s", value, err)
+	}
+
+	return parsed
+}
+
+func TestActivity(t *testing.T) {
+	// 2020-01-10 is a Friday.
+	start := metav1.NewTime(time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(time.Date(2020, 1, 20, 12, 0, 0, 0, time.UTC))
+	sunday := metav1.NewTime(time.Date(2020, 1, 19, 12, 0, 0, 0, time.UTC))
+	weekend := &shipper.RolloutBlockSchedule{
+		Start:    "0 16 * * FRI",
+		Duration: "64h",
+	}
+
+	tests := []struct {
+		Name           string
+		Spec           shipper.RolloutBlockSpec
+		Now            string
+		ExpectedActive bool
+		ExpectedNext   string
+	}{
+		{
+			"block without a time window is always active",
+			shipper.RolloutBlockSpec{},
+			"2020-01-10T00:00:00Z",
+			true,
+			"",
+		},
+		{
+			"block before its start time",
+			shipper.RolloutBlockSpec{StartTime: &start, EndTime: &end},
+			"2020-01-09T00:00:00Z",
+			false,
+			"2020-01-10T12:00:00Z",
+		},
+		{
+			"block within its time window",
+			shipper.RolloutBlockSpec{StartTime: &start, EndTime: &end},
+			"2020-01-15T00:00:00Z",
+			true,
+			"2020-01-20T12:00:00Z",
+		},
+		{
+			"block after its end time",
+			shipper.RolloutBlockSpec{StartTime: &start, EndTime: &end},
+			"2020-01-20T12:00:00Z",
+			false,
+			"",
+		},
+		{
+			"scheduled block before its window opens",
+			shipper.RolloutBlockSpec{Schedule: weekend},
+			"2020-01-10T15:59:00Z",
+			false,
+			"2020-01-10T16:00:00Z",
+		},
+		{
+			"scheduled block when its window opens",
+			shipper.RolloutBlockSpec{Schedule: weekend},
+			"2020-01-10T16:00:00Z",
+			true,
+			"2020-01-13T08:00:00Z",
+		},
+		{
+			"scheduled block within its window",
+			shipper.RolloutBlockSpec{Schedule: weekend},
+			"2020-01-12T10:00:00Z",
+			true,
+			"2020-01-13T08:00:00Z",
+		},
+		{
+			"scheduled block after its window closes",
+			shipper.RolloutBlockSpec{Schedule: weekend},
+			"2020-01-13T08:00:00Z",
+			false,
+			"2020-01-17T16:00:00Z",
+		},
+		{
+			"scheduled block window is cut short by its end time",
+			shipper.RolloutBlockSpec{Schedule: weekend, EndTime: &sunday},
+			"2020-01-17T17:00:00Z",
+			true,
+			"2020-01-19T12:00:00Z",
+		},
+		{
+			"scheduled block doesn't open a window after its end time",
+			shipper.RolloutBlockSpec{Schedule: weekend, EndTime: &start},
+			"2020-01-10T11:00:00Z",
+			false,
+			"",
+		},
+		{
+			"scheduled block in a different time zone",
+			shipper.RolloutBlockSpec{
+				Schedule: &shipper.RolloutBlockSchedule{
+					Start:    "0 9 * * *",
+					Duration: "1h",
+					TimeZone: "Europe/Amsterdam",
+				},
+			},
+			"2020-01-10T08:30:00Z",
+			true,
+			"2020-01-10T09:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			now := mustParseTime(t, tt.Now)
+
+			active, next, err := Activity(tt.Spec, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if active != tt.ExpectedActive {
+				t.Errorf("expected active to be %t, got %t", tt.ExpectedActive, active)
+			}
+
+			var expectedNext time.Time
+			if tt.ExpectedNext != "" {
+				expectedNext = mustParseTime(t, tt.ExpectedNext)
+			}
+
+			if !next.Equal(expectedNext) {
+				t.Errorf("expected next transition at %s, got %s", expectedNext, next)
+			}
+		})
+	}
+}
+
+func TestIsActiveWithInvalidSchedule(t *testing.T) {
+	rb := &shipper.RolloutBlock{
+		Spec: shipper.RolloutBlockSpec{
+			Schedule: &shipper.RolloutBlockSchedule{
+				Start:    "0 16 * * FRIDAY",
+				Duration: "64h",
+			},
+		},
+	}
+
+	if !IsActive(rb, time.Now()) {
+		t.Errorf("expected a block with an invalid schedule to be active")
+	}
+}
+
+func TestValidateSpec(t *testing.T) {
+	start := metav1.NewTime(time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(time.Date(2020, 1, 20, 12, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		Name        string
+		Spec        shipper.RolloutBlockSpec
+		ExpectError bool
+	}{
+		{
+			"no time window",
+			shipper.RolloutBlockSpec{},
+			false,
+		},
+		{
+			"valid time window",
+			shipper.RolloutBlockSpec{StartTime: &start, EndTime: &end},
+			false,
+		},
+		{
+			"time window ending before it starts",
+			shipper.RolloutBlockSpec{StartTime: &end, EndTime: &start},
+			true,
+		},
+		{
+			"valid schedule",
+			shipper.RolloutBlockSpec{
+				Schedule: &shipper.RolloutBlockSchedule{
+					Start:    "*/15 9-17 1,15 JAN-JUN MON-FRI",
+					Duration: "10m",
+					TimeZone: "America/New_York",
+				},
+			},
+			false,
+		},
+		{
+			"schedule with too few fields",
+			shipper.RolloutBlockSpec{
+				Schedule: &shipper.RolloutBlockSchedule{Start: "0 16 * *", Duration: "1h"},
+			},
+			true,
+		},
+		{
+			"schedule with an out of range value",
+			shipper.RolloutBlockSpec{
+				Schedule: &shipper.RolloutBlockSchedule{Start: "0 24 * * *", Duration: "1h"},
+			},
+			true,
+		},
+		{
+			"schedule with an invalid duration",
+			shipper.RolloutBlockSpec{
+				Schedule: &shipper.RolloutBlockSchedule{Start: "0 16 * * *", Duration: "forever"},
+			},
+			true,
+		},
+		{
+			"schedule with a negative duration",
+			shipper.RolloutBlockSpec{
+				Schedule: &shipper.RolloutBlockSchedule{Start: "0 16 * * *", Duration: "-1h"},
+			},
+			true,
+		},
+		{
+			"schedule with an unknown time zone",
+			shipper.RolloutBlockSpec{
+				Schedule: &shipper.RolloutBlockSchedule{
+					Start:    "0 16 * * *",
+					Duration: "1h",
+					TimeZone: "Mars/Olympus_Mons",
+				},
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := ValidateSpec(tt.Spec)
+			if tt.ExpectError && err == nil {
+				t.Errorf("expected an error, got none")
+			} else if !tt.ExpectError && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
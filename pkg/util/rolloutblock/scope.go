@@ -0,0 +1,111 @@
+package rolloutblock
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// target is what a rollout block scope is matched against.
+type target struct {
+	labels   labels.Set
+	chart    string
+	regions  []string
+	clusters []string
+}
+
+func newTarget(obj metav1.Object) target {
+	t := target{labels: labels.Set(obj.GetLabels())}
+
+	var env *shipper.ReleaseEnvironment
+	switch o := obj.(type) {
+	case *shipper.Application:
+		env = &o.Spec.Template
+	case *shipper.Release:
+		env = &o.Spec.Environment
+		if clusters := o.GetAnnotations()[shipper.ReleaseClustersAnnotation]; clusters != "" {
+			t.clusters = strings.Split(clusters, ",")
+		}
+	}
+
+	if env != nil {
+		t.chart = env.Chart.Name
+		for _, region := range env.ClusterRequirements.Regions {
+			t.regions = append(t.regions, region.Name)
+		}
+	}
+
+	return t
+}
+
+// ValidateScope checks that the scope of a rollout block is well formed.
+func ValidateScope(scope *shipper.RolloutBlockScope) error {
+	if scope == nil || scope.Selector == nil {
+		return nil
+	}
+
+	if _, err := metav1.LabelSelectorAsSelector(scope.Selector); err != nil {
+		return fmt.Errorf("invalid rollout block selector: %s", err)
+	}
+
+	return nil
+}
+
+// AppliesTo returns whether a rollout block applies to an application or a
+// release according to its scope. Blocks with a malformed selector are
+// considered to apply to everything.
+func AppliesTo(rb *shipper.RolloutBlock, obj metav1.Object) bool {
+	scope := rb.Spec.Scope
+	if scope == nil {
+		return true
+	}
+
+	t := newTarget(obj)
+
+	if scope.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(scope.Selector)
+		if err == nil && !selector.Matches(t.labels) {
+			return false
+		}
+	}
+
+	if len(scope.Charts) > 0 && !containsAny(scope.Charts, []string{t.chart}) {
+		return false
+	}
+
+	if len(scope.Regions) > 0 && !containsAny(scope.Regions, t.regions) {
+		return false
+	}
+
+	if len(scope.Clusters) > 0 && !containsAny(scope.Clusters, t.clusters) {
+		return false
+	}
+
+	return true
+}
+
+// ScopedBlocks filters out the rollout blocks that don't apply to an object.
+func ScopedBlocks(rbs []*shipper.RolloutBlock, obj metav1.Object) []*shipper.RolloutBlock {
+	scoped := make([]*shipper.RolloutBlock, 0, len(rbs))
+	for _, rb := range rbs {
+		if AppliesTo(rb, obj) {
+			scoped = append(scoped, rb)
+		}
+	}
+	return scoped
+}
+
+func containsAny(list, values []string) bool {
+	for _, v := range values {
+		for _, item := range list {
+			if item == v {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package rolloutblock
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestAppliesTo(t *testing.T) {
+	env := shipper.ReleaseEnvironment{
+		Chart: shipper.Chart{Name: "nginx"},
+		ClusterRequirements: shipper.ClusterRequirements{
+			Regions: []shipper.RegionRequirement{{Name: "eu-west"}},
+		},
+	}
+
+	app := &shipper.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"team": "frontend"},
+		},
+		Spec: shipper.ApplicationSpec{Template: env},
+	}
+
+	unscheduledRel := &shipper.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"team": "frontend"},
+		},
+		Spec: shipper.ReleaseSpec{Environment: env},
+	}
+
+	rel := unscheduledRel.DeepCopy()
+	rel.Annotations = map[string]string{
+		shipper.ReleaseClustersAnnotation: "kube-a,kube-b",
+	}
+
+	tests := []struct {
+		Name     string
+		Scope    *shipper.RolloutBlockScope
+		Obj      metav1.Object
+		Expected bool
+	}{
+		{
+			"block without a scope",
+			nil,
+			rel,
+			true,
+		},
+		{
+			"matching selector",
+			&shipper.RolloutBlockScope{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "frontend"},
+				},
+			},
+			app,
+			true,
+		},
+		{
+			"non matching selector",
+			&shipper.RolloutBlockScope{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "backend"},
+				},
+			},
+			rel,
+			false,
+		},
+		{
+			"matching chart",
+			&shipper.RolloutBlockScope{Charts: []string{"redis", "nginx"}},
+			app,
+			true,
+		},
+		{
+			"non matching chart",
+			&shipper.RolloutBlockScope{Charts: []string{"redis"}},
+			rel,
+			false,
+		},
+		{
+			"matching region",
+			&shipper.RolloutBlockScope{Regions: []string{"eu-west"}},
+			app,
+			true,
+		},
+		{
+			"non matching region",
+			&shipper.RolloutBlockScope{Regions: []string{"us-east"}},
+			rel,
+			false,
+		},
+		{
+			"matching cluster",
+			&shipper.RolloutBlockScope{Clusters: []string{"kube-b"}},
+			rel,
+			true,
+		},
+		{
+			"non matching cluster",
+			&shipper.RolloutBlockScope{Clusters: []string{"kube-c"}},
+			rel,
+			false,
+		},
+		{
+			"cluster scope on an unscheduled release",
+			&shipper.RolloutBlockScope{Clusters: []string{"kube-a"}},
+			unscheduledRel,
+			false,
+		},
+		{
+			"cluster scope on an application",
+			&shipper.RolloutBlockScope{Clusters: []string{"kube-a"}},
+			app,
+			false,
+		},
+		{
+			"all criteria need to match",
+			&shipper.RolloutBlockScope{
+				Charts:   []string{"nginx"},
+				Clusters: []string{"kube-c"},
+			},
+			rel,
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			rb := &shipper.RolloutBlock{
+				Spec: shipper.RolloutBlockSpec{Scope: tt.Scope},
+			}
+
+			if got := AppliesTo(rb, tt.Obj); got != tt.Expected {
+				t.Errorf("expected AppliesTo() to return %t, got %t", tt.Expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateScope(t *testing.T) {
+	valid := &shipper.RolloutBlockScope{
+		Selector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "team", Operator: metav1.LabelSelectorOpIn, Values: []string{"frontend"}},
+			},
+		},
+	}
+	if err := ValidateScope(valid); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	invalid := &shipper.RolloutBlockScope{
+		Selector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "team", Operator: "Resembles", Values: []string{"frontend"}},
+			},
+		},
+	}
+	if err := ValidateScope(invalid); err == nil {
+		t.Errorf("expected an error for an invalid selector, got none")
+	}
+}
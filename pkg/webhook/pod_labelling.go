@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admission "k8s.io/api/admission/v1beta1"
+	kubeclient "k8s.io/api/admission/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kubeinformers "k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/util/anchor"
+)
+
+const (
+	PodLabellingAgentName = "pod-labelling-webhook"
+)
+
+// PodLabellingWebhook runs in application clusters, and labels new pods of a
+// release with the traffic status the traffic controller last published on
+// the release's anchor. This way, pods replaced while Shipper can not reach
+// the cluster still get traffic if their release does.
+type PodLabellingWebhook struct {
+	configMapsLister corelisters.ConfigMapLister
+	configMapsSynced cache.InformerSynced
+
+	bindAddr string
+	bindPort string
+
+	tlsCertFile       string
+	tlsPrivateKeyFile string
+}
+
+func NewPodLabellingWebhook(
+	bindAddr, bindPort, tlsPrivateKeyFile, tlsCertFile string,
+	kubeInformerFactory kubeinformers.SharedInformerFactory,
+) *PodLabellingWebhook {
+	configMapsInformer := kubeInformerFactory.Core().V1().ConfigMaps()
+
+	return &PodLabellingWebhook{
+		configMapsLister: configMapsInformer.Lister(),
+		configMapsSynced: configMapsInformer.Informer().HasSynced,
+
+		bindAddr: bindAddr,
+		bindPort: bindPort,
+
+		tlsPrivateKeyFile: tlsPrivateKeyFile,
+		tlsCertFile:       tlsCertFile,
+	}
+}
+
+func (c *PodLabellingWebhook) Run(stopCh <-chan struct{}) {
+	addr := c.bindAddr + ":" + c.bindPort
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mutate", adaptHandler(c.mutateHandlerFunc))
+	server := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+
+	if !cache.WaitForCacheSync(stopCh, c.configMapsSynced) {
+		klog.Fatalf("failed to wait for caches to sync")
+		return
+	}
+
+	go func() {
+		var serverError error
+		if c.tlsCertFile == "" || c.tlsPrivateKeyFile == "" {
+			serverError = server.ListenAndServe()
+		} else {
+			serverError = server.ListenAndServeTLS(c.tlsCertFile, c.tlsPrivateKeyFile)
+		}
+
+		if serverError != nil && serverError != http.ErrServerClosed {
+			klog.Fatalf("failed to start the pod labelling webhook: %v", serverError)
+		}
+	}()
+
+	klog.V(2).Info("Started the pod labelling WebHook")
+
+	<-stopCh
+
+	klog.V(2).Info("Shutting down the pod labelling WebHook")
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		klog.Errorf(`HTTP server Shutdown: %v`, err)
+	}
+}
+
+// mutateHandlerFunc never rejects a pod: if the traffic status of its release
+// can't be figured out, the pod is let through as is, and is left for the
+// traffic controller to label.
+func (c *PodLabellingWebhook) mutateHandlerFunc(review *admission.AdmissionReview) *admission.AdmissionResponse {
+	request := review.Request
+	response := &admission.AdmissionResponse{
+		Allowed: true,
+	}
+
+	if request.Operation != kubeclient.Create || request.Kind.Kind != "Pod" {
+		return response
+	}
+
+	patch, err := c.podTrafficStatusPatch(request)
+	if err != nil {
+		klog.Warningf("not labelling pod %s/%s: %s", request.Namespace, request.Name, err)
+		return response
+	}
+
+	if patch != nil {
+		patchType := admission.PatchTypeJSONPatch
+		response.Patch = patch
+		response.PatchType = &patchType
+	}
+
+	return response
+}
+
+// podTrafficStatusPatch returns a JSON patch setting the traffic status label
+// of the pod in an admission request, or nil if it doesn't need one.
+func (c *PodLabellingWebhook) podTrafficStatusPatch(request *admission.AdmissionRequest) ([]byte, error) {
+	pod, _, err := objectMetadata(request)
+	if err != nil {
+		return nil, err
+	}
+
+	podLabels := pod.GetLabels()
+	releaseName, ok := podLabels[shipper.ReleaseLabel]
+	if !ok {
+		return nil, nil
+	}
+
+	// Pods created by a controller usually only get a name once they
+	// are admitted, and a namespace from the request.
+	namespace := request.Namespace
+	name := anchor.ReleaseAnchorName(releaseName)
+	configMap, err := c.configMapsLister.ConfigMaps(namespace).Get(name)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	value, ok := configMap.Data[anchor.PodTrafficStatus]
+	if !ok || podLabels[shipper.PodTrafficStatusLabel] == value {
+		return nil, nil
+	}
+
+	return json.Marshal([]map[string]interface{}{
+		{
+			"op":    "add",
+			"path":  fmt.Sprintf("/metadata/labels/%s", shipper.PodTrafficStatusLabel),
+			"value": value,
+		},
+	})
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	admission "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/util/anchor"
+)
+
+const (
+	testNamespace = "test-namespace"
+	testRelease   = "test-release"
+)
+
+func TestPodTrafficStatusPatch(t *testing.T) {
+	var tests = []struct {
+		title            string
+		podLabels        map[string]string
+		podTrafficStatus string
+		expectedPatch    string
+	}{
+		{
+			"pod of a release with traffic gets labeled",
+			map[string]string{shipper.ReleaseLabel: testRelease},
+			shipper.Enabled,
+			`[{"op":"add","path":"/metadata/labels/shipper-traffic-status","value":"enabled"}]`,
+		},
+		{
+			"pod of a release without traffic gets labeled",
+			map[string]string{
+				shipper.ReleaseLabel:          testRelease,
+				shipper.PodTrafficStatusLabel: shipper.Enabled,
+			},
+			shipper.Disabled,
+			`[{"op":"add","path":"/metadata/labels/shipper-traffic-status","value":"disabled"}]`,
+		},
+		{
+			"pod already labeled is left alone",
+			map[string]string{
+				shipper.ReleaseLabel:          testRelease,
+				shipper.PodTrafficStatusLabel: shipper.Enabled,
+			},
+			shipper.Enabled,
+			"",
+		},
+		{
+			"pod of a release without a published traffic status is left alone",
+			map[string]string{shipper.ReleaseLabel: testRelease},
+			"",
+			"",
+		},
+		{
+			"pod of another release is left alone",
+			map[string]string{shipper.ReleaseLabel: "another-release"},
+			shipper.Enabled,
+			"",
+		},
+		{
+			"pod not managed by shipper is left alone",
+			map[string]string{},
+			shipper.Enabled,
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      anchor.ReleaseAnchorName(testRelease),
+				Namespace: testNamespace,
+			},
+			Data: map[string]string{},
+		}
+		if test.podTrafficStatus != "" {
+			configMap.Data[anchor.PodTrafficStatus] = test.podTrafficStatus
+		}
+
+		informerFactory := kubeinformers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+		informerFactory.Core().V1().ConfigMaps().Informer().GetIndexer().Add(configMap)
+		webhook := NewPodLabellingWebhook("", "", "", "", informerFactory)
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: testRelease + "-",
+				Labels:       test.podLabels,
+			},
+		}
+		raw, err := json.Marshal(pod)
+		if err != nil {
+			t.Fatalf("testing %s: could not marshal pod: %s", test.title, err)
+		}
+
+		response := webhook.mutateHandlerFunc(&admission.AdmissionReview{
+			Request: &admission.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+				Namespace: testNamespace,
+				Operation: admission.Create,
+				Object:    runtime.RawExtension{Raw: raw},
+			},
+		})
+
+		if !response.Allowed {
+			t.Errorf("testing %s: expected pod to be allowed", test.title)
+		}
+
+		if string(response.Patch) != test.expectedPatch {
+			t.Errorf("testing %s: expected patch %q, got %q",
+				test.title, test.expectedPatch, string(response.Patch))
+		}
+	}
+}
@@ -14,17 +14,22 @@ import (
 
 	admission "k8s.io/api/admission/v1beta1"
 	kubeclient "k8s.io/api/admission/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 
+	"github.com/bookingcom/shipper/pkg/analysis"
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperchart "github.com/bookingcom/shipper/pkg/chart"
+	"github.com/bookingcom/shipper/pkg/chart/repo"
 	clientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
 	informers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	listers "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
 	"github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
 	"github.com/bookingcom/shipper/pkg/util/rolloutblock"
 )
 
@@ -36,6 +41,11 @@ type Webhook struct {
 	shipperClientset    clientset.Interface
 	rolloutBlocksLister listers.RolloutBlockLister
 	rolloutBlocksSynced cache.InformerSynced
+	applicationsLister  listers.ApplicationLister
+	applicationsSynced  cache.InformerSynced
+
+	chartVersionResolver repo.ChartVersionResolver
+	chartFetcher         repo.ChartFetcher
 
 	bindAddr string
 	bindPort string
@@ -45,6 +55,11 @@ type Webhook struct {
 
 	webhookHealthMetric prometheus.WebhookMetric
 	heartbeatPeriod     time.Duration
+
+	// shipperUsername is the user Shipper itself makes requests as. The
+	// rollout block overrides it copies from releases to applications
+	// keep the author and timestamp of the release.
+	shipperUsername string
 }
 
 var (
@@ -59,13 +74,19 @@ func NewWebhook(
 	shipperInformerFactory informers.SharedInformerFactory,
 	webhookMetric prometheus.WebhookMetric,
 	heartbeatPeriod time.Duration,
+	shipperUsername string,
+	chartVersionResolver repo.ChartVersionResolver,
+	chartFetcher repo.ChartFetcher,
 ) *Webhook {
 	rolloutBlocksInformer := shipperInformerFactory.Shipper().V1alpha1().RolloutBlocks()
+	applicationsInformer := shipperInformerFactory.Shipper().V1alpha1().Applications()
 
 	return &Webhook{
 		shipperClientset:    shipperClientset,
 		rolloutBlocksLister: rolloutBlocksInformer.Lister(),
 		rolloutBlocksSynced: rolloutBlocksInformer.Informer().HasSynced,
+		applicationsLister:  applicationsInformer.Lister(),
+		applicationsSynced:  applicationsInformer.Informer().HasSynced,
 
 		bindAddr: bindAddr,
 		bindPort: bindPort,
@@ -75,6 +96,11 @@ func NewWebhook(
 
 		webhookHealthMetric: webhookMetric,
 		heartbeatPeriod:     heartbeatPeriod,
+
+		shipperUsername: shipperUsername,
+
+		chartVersionResolver: chartVersionResolver,
+		chartFetcher:         chartFetcher,
 	}
 }
 
@@ -86,7 +112,7 @@ func (c *Webhook) Run(stopCh <-chan struct{}) {
 		Handler: mux,
 	}
 
-	if !cache.WaitForCacheSync(stopCh, c.rolloutBlocksSynced) {
+	if !cache.WaitForCacheSync(stopCh, c.rolloutBlocksSynced, c.applicationsSynced) {
 		klog.Fatalf("failed to wait for caches to sync")
 		return
 	}
@@ -154,6 +180,7 @@ func (c *Webhook) startHeartbeatRoutine(ctx context.Context, host string) {
 func (c *Webhook) initializeHandlers() *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/validate", adaptHandler(c.validateHandlerFunc))
+	mux.HandleFunc("/mutate", adaptHandler(c.mutateHandlerFunc))
 	return mux
 }
 
@@ -239,18 +266,274 @@ func (c *Webhook) validateHandlerFunc(review *admission.AdmissionReview) *admiss
 	}
 }
 
+// mutateHandlerFunc stamps applications and releases adding rollout block
+// overrides with who added them and when, for the rolloutblock controller to
+// keep an audit trail, and approvals added to releases with who approved.
+func (c *Webhook) mutateHandlerFunc(review *admission.AdmissionReview) *admission.AdmissionResponse {
+	request := review.Request
+	response := &admission.AdmissionResponse{
+		Allowed: true,
+	}
+
+	if request.Operation != kubeclient.Create && request.Operation != kubeclient.Update {
+		return response
+	}
+
+	if request.Kind.Kind != "Application" && request.Kind.Kind != "Release" {
+		return response
+	}
+
+	now := time.Now()
+	ops, err := c.overrideAuditPatch(request, now)
+	if err == nil && request.Kind.Kind == "Release" {
+		var approvalOps []map[string]interface{}
+		approvalOps, err = approvalsPatch(request, now)
+		ops = append(ops, approvalOps...)
+	}
+
+	var patch []byte
+	if err == nil && len(ops) > 0 {
+		patch, err = json.Marshal(ops)
+	}
+
+	if err != nil {
+		return &admission.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if patch != nil {
+		patchType := admission.PatchTypeJSONPatch
+		response.Patch = patch
+		response.PatchType = &patchType
+	}
+
+	return response
+}
+
+func (c *Webhook) overrideAuditPatch(request *admission.AdmissionRequest, now time.Time) ([]map[string]interface{}, error) {
+	obj, old, err := objectMetadata(request)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := c.overridesBefore(request, obj, old)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rolloutblock.AddedOverrides(before, obj)) == 0 {
+		return nil, nil
+	}
+
+	// Shipper only adds overrides to applications on behalf of whoever
+	// added them to a release, and brings their audit trail along.
+	annotations := obj.GetAnnotations()
+	if c.shipperUsername != "" && request.UserInfo.Username == c.shipperUsername &&
+		annotations[shipper.RolloutBlocksOverrideAuthorAnnotation] != "" &&
+		annotations[shipper.RolloutBlocksOverrideTimestampAnnotation] != "" {
+		return nil, nil
+	}
+
+	annotations = make(map[string]string)
+	for k, v := range obj.GetAnnotations() {
+		annotations[k] = v
+	}
+	annotations[shipper.RolloutBlocksOverrideAuthorAnnotation] = request.UserInfo.Username
+	annotations[shipper.RolloutBlocksOverrideTimestampAnnotation] = now.UTC().Format(time.RFC3339)
+
+	return []map[string]interface{}{
+		{
+			"op":    "add",
+			"path":  "/metadata/annotations",
+			"value": annotations,
+		},
+	}, nil
+}
+
+// approvalsPatch records the user Kubernetes authenticated the request as,
+// and the current time, on the approvals it adds to a release. Clients can't
+// be trusted to know, let alone tell, who their user is.
+func approvalsPatch(request *admission.AdmissionRequest, now time.Time) ([]map[string]interface{}, error) {
+	var release shipper.Release
+	if err := json.Unmarshal(request.Object.Raw, &release); err != nil {
+		return nil, err
+	}
+
+	var oldApprovals []shipper.StepApproval
+	if request.Operation == kubeclient.Update {
+		var oldRelease shipper.Release
+		if err := json.Unmarshal(request.OldObject.Raw, &oldRelease); err != nil {
+			return nil, err
+		}
+		oldApprovals = oldRelease.Status.Approvals
+	}
+
+	var ops []map[string]interface{}
+	for i := len(oldApprovals); i < len(release.Status.Approvals); i++ {
+		ops = append(ops, map[string]interface{}{
+			"op":   "replace",
+			"path": fmt.Sprintf("/status/approvals/%d", i),
+			"value": shipper.StepApproval{
+				Step: release.Status.Approvals[i].Step,
+				User: request.UserInfo.Username,
+				Time: metav1.NewTime(now.UTC().Truncate(time.Second)),
+			},
+		})
+	}
+
+	return ops, nil
+}
+
+// objectMetadata returns the metadata of the object in an admission request,
+// and of the object it replaces if it's an update.
+func objectMetadata(request *admission.AdmissionRequest) (metav1.Object, metav1.Object, error) {
+	var obj metav1.PartialObjectMetadata
+	if err := json.Unmarshal(request.Object.Raw, &obj); err != nil {
+		return nil, nil, err
+	}
+
+	if request.Operation != kubeclient.Update {
+		return &obj, nil, nil
+	}
+
+	var old metav1.PartialObjectMetadata
+	if err := json.Unmarshal(request.OldObject.Raw, &old); err != nil {
+		return nil, nil, err
+	}
+
+	return &obj, &old, nil
+}
+
+// overridesBefore returns the object whose rollout block overrides obj had
+// before the request: the object it replaces, or for a release being created,
+// its application. Releases carry over the overrides of their application,
+// which were accounted for when they were added to it, or predate audit
+// trails altogether.
+func (c *Webhook) overridesBefore(request *admission.AdmissionRequest, obj, old metav1.Object) (metav1.Object, error) {
+	if old != nil || request.Kind.Kind != "Release" {
+		return old, nil
+	}
+
+	appName, ok := obj.GetLabels()[shipper.AppLabel]
+	if !ok {
+		return nil, nil
+	}
+
+	app, err := c.applicationsLister.Applications(request.Namespace).Get(appName)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+// validateOverrideAudit makes sure that rollout block overrides can be
+// accounted for: adding them requires a justification, and the author and
+// timestamp the webhook stamps them with can not be tampered with.
+func (c *Webhook) validateOverrideAudit(request *admission.AdmissionRequest) error {
+	obj, old, err := objectMetadata(request)
+	if err != nil {
+		return err
+	}
+
+	before, err := c.overridesBefore(request, obj, old)
+	if err != nil {
+		return err
+	}
+
+	annotations := obj.GetAnnotations()
+	if len(rolloutblock.AddedOverrides(before, obj)) > 0 {
+		if annotations[shipper.RolloutBlocksOverrideJustificationAnnotation] == "" {
+			return fmt.Errorf("overriding rollout blocks requires a justification in the %q annotation",
+				shipper.RolloutBlocksOverrideJustificationAnnotation)
+		}
+
+		return nil
+	}
+
+	if old == nil {
+		return nil
+	}
+
+	oldAnnotations := old.GetAnnotations()
+	for _, annotation := range []string{
+		shipper.RolloutBlocksOverrideAuthorAnnotation,
+		shipper.RolloutBlocksOverrideTimestampAnnotation,
+	} {
+		if annotations[annotation] != oldAnnotations[annotation] {
+			return fmt.Errorf("the %q annotation can only be set by adding rollout block overrides", annotation)
+		}
+	}
+
+	return nil
+}
+
 func (c *Webhook) validateCreateUpdate(request *kubeclient.AdmissionRequest) error {
 	var err error
 	switch request.Kind.Kind {
 	case "Application":
 		var application shipper.Application
 		err = json.Unmarshal(request.Object.Raw, &application)
+		if err == nil {
+			err = validateStrategy(application.Spec.Template.Strategy)
+		}
+		if err == nil {
+			err = releaseutil.ValidateClusterAffinity(application.Spec.Template.ClusterRequirements)
+		}
+		if err == nil {
+			err = validateTrafficBackend(application.Spec.Template.TrafficBackend)
+		}
+		if err == nil {
+			err = c.validateOverrideAudit(request)
+		}
+		if err == nil {
+			var oldEnv *shipper.ReleaseEnvironment
+			if request.Operation == kubeclient.Update {
+				var oldApplication shipper.Application
+				err = json.Unmarshal(request.OldObject.Raw, &oldApplication)
+				oldEnv = &oldApplication.Spec.Template
+			}
+			if err == nil {
+				err = c.validateStepReplicasFit(request, application.Name, application.Spec.Template, oldEnv)
+			}
+		}
 		if err == nil {
 			err = c.validateBlocksForApplication(request, application)
 		}
 	case "Release":
 		var release shipper.Release
 		err = json.Unmarshal(request.Object.Raw, &release)
+		if err == nil {
+			err = validateStrategy(release.Spec.Environment.Strategy)
+		}
+		if err == nil {
+			err = releaseutil.ValidateClusterAffinity(release.Spec.Environment.ClusterRequirements)
+		}
+		if err == nil {
+			err = validateTrafficBackend(release.Spec.Environment.TrafficBackend)
+		}
+		if err == nil {
+			err = validateApprovals(request, release)
+		}
+		if err == nil {
+			err = c.validateOverrideAudit(request)
+		}
+		if err == nil {
+			var oldEnv *shipper.ReleaseEnvironment
+			if request.Operation == kubeclient.Update {
+				var oldRelease shipper.Release
+				err = json.Unmarshal(request.OldObject.Raw, &oldRelease)
+				oldEnv = &oldRelease.Spec.Environment
+			}
+			if err == nil {
+				err = c.validateStepReplicasFit(request, release.Labels[shipper.AppLabel], release.Spec.Environment, oldEnv)
+			}
+		}
 		if err == nil {
 			err = c.validateBlocksForRelease(request, release)
 		}
@@ -263,12 +546,24 @@ func (c *Webhook) validateCreateUpdate(request *kubeclient.AdmissionRequest) err
 	case "CapacityTarget":
 		var capacityTarget shipper.CapacityTarget
 		err = json.Unmarshal(request.Object.Raw, &capacityTarget)
+		if err == nil {
+			err = validateCapacityTarget(capacityTarget)
+		}
 	case "TrafficTarget":
 		var trafficTarget shipper.TrafficTarget
 		err = json.Unmarshal(request.Object.Raw, &trafficTarget)
+		if err == nil {
+			err = validateTrafficBackend(trafficTarget.Spec.Backend)
+		}
 	case "RolloutBlock":
 		var rolloutBlock shipper.RolloutBlock
 		err = json.Unmarshal(request.Object.Raw, &rolloutBlock)
+		if err == nil {
+			err = rolloutblock.ValidateSpec(rolloutBlock.Spec)
+		}
+		if err == nil {
+			err = rolloutblock.ValidateScope(rolloutBlock.Spec.Scope)
+		}
 	}
 	return err
 }
@@ -294,9 +589,204 @@ func (c *Webhook) validateDelete(request *kubeclient.AdmissionRequest) error {
 	return c.validateBlocksForObject(obj)
 }
 
+func validateStrategy(strategy *shipper.RolloutStrategy) error {
+	if strategy == nil {
+		return nil
+	}
+
+	if err := releaseutil.ValidateBlueGreen(strategy); err != nil {
+		return err
+	}
+
+	if strategy.BlueGreen == nil && len(strategy.Steps) == 0 {
+		return fmt.Errorf("strategy must define either steps or blueGreen")
+	}
+
+	if err := releaseutil.ValidateStepClusters(strategy); err != nil {
+		return err
+	}
+
+	if err := releaseutil.ValidateStepApprovals(strategy); err != nil {
+		return err
+	}
+
+	if err := releaseutil.ValidateStepReplicas(strategy); err != nil {
+		return err
+	}
+
+	for _, step := range strategy.Steps {
+		if _, _, err := releaseutil.StepPauseDuration(step); err != nil {
+			return err
+		}
+
+		for _, check := range step.Analysis {
+			if err := analysis.Validate(check); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateStepReplicasFit makes sure that the absolute replica counts in the
+// strategy of an application or release fit in the replicas it requests. The
+// chart has to be rendered to know how many that is, so it's only done when
+// the environment changes.
+func (c *Webhook) validateStepReplicasFit(
+	request *admission.AdmissionRequest,
+	appName string,
+	env shipper.ReleaseEnvironment,
+	oldEnv *shipper.ReleaseEnvironment,
+) error {
+	if !releaseutil.HasContenderReplicas(env.Strategy) {
+		return nil
+	}
+
+	if oldEnv != nil && reflect.DeepEqual(*oldEnv, env) {
+		return nil
+	}
+
+	chartSpec := env.Chart
+	chartVersion, err := c.chartVersionResolver(&chartSpec)
+	if err != nil {
+		return fmt.Errorf("cannot resolve chart to check replica counts: %s", err)
+	}
+	chartSpec.Version = chartVersion.Version
+
+	chart, err := c.chartFetcher(&chartSpec)
+	if err != nil {
+		return fmt.Errorf("cannot fetch chart to check replica counts: %s", err)
+	}
+
+	rendered, err := shipperchart.Render(chart, appName, request.Namespace, env.Values)
+	if err != nil {
+		return fmt.Errorf("cannot render chart to check replica counts: %s", err)
+	}
+
+	chartReplicaCount, err := renderedReplicaCount(rendered)
+	if err != nil {
+		return err
+	}
+
+	return releaseutil.ValidateStepReplicasFit(env.Strategy, env.ClusterRequirements, chartReplicaCount)
+}
+
+// renderedReplicaCount returns the replicas of the single Deployment or
+// StatefulSet of a rendered chart, which default to 1.
+func renderedReplicaCount(rendered []string) (int32, error) {
+	deployments := shipperchart.GetDeployments(rendered)
+	statefulSets := shipperchart.GetStatefulSets(rendered)
+	if n := len(deployments) + len(statefulSets); n != 1 {
+		return 0, fmt.Errorf("chart must contain exactly one Deployment or StatefulSet, found %d", n)
+	}
+
+	replicas := int32(1)
+	if len(statefulSets) == 1 && statefulSets[0].Spec.Replicas != nil {
+		replicas = *statefulSets[0].Spec.Replicas
+	} else if len(deployments) == 1 && deployments[0].Spec.Replicas != nil {
+		replicas = *deployments[0].Spec.Replicas
+	}
+
+	return replicas, nil
+}
+
+// validateCapacityTarget makes sure that absolute replica counts fit in the
+// number of replicas requested by the release in every cluster.
+func validateCapacityTarget(ct shipper.CapacityTarget) error {
+	for _, spec := range ct.Spec.Clusters {
+		if spec.Replicas == nil {
+			continue
+		}
+
+		if *spec.Replicas < 0 || *spec.Replicas > spec.TotalReplicaCount {
+			return fmt.Errorf("%d replicas requested in cluster %q, but the release only requests %d",
+				*spec.Replicas, spec.Name, spec.TotalReplicaCount)
+		}
+	}
+
+	return nil
+}
+
+// validateTrafficBackend makes sure that traffic is shifted by a backend the
+// traffic controller knows about. An empty backend means pod labels.
+func validateTrafficBackend(backend shipper.TrafficBackendType) error {
+	switch backend {
+	case "", shipper.TrafficBackendPodLabels, shipper.TrafficBackendIstio:
+		return nil
+	default:
+		return fmt.Errorf("unknown traffic backend %q, must be one of %q or %q",
+			backend, shipper.TrafficBackendPodLabels, shipper.TrafficBackendIstio)
+	}
+}
+
+// validateApprovals makes sure that approvals recorded in the release status
+// are only ever added, by the user they name (which mutateHandlerFunc takes
+// care of), and that the target step of a release is not bumped past a step
+// that does not have enough approvals.
+func validateApprovals(request *admission.AdmissionRequest, release shipper.Release) error {
+	var oldApprovals []shipper.StepApproval
+	var oldTargetStep int32
+
+	if request.Operation == kubeclient.Update {
+		var oldRelease shipper.Release
+		if err := json.Unmarshal(request.OldObject.Raw, &oldRelease); err != nil {
+			return err
+		}
+		oldApprovals = oldRelease.Status.Approvals
+		oldTargetStep = oldRelease.Spec.TargetStep
+	}
+
+	newApprovals := release.Status.Approvals
+	if len(newApprovals) < len(oldApprovals) {
+		return fmt.Errorf("approvals of Release %q can not be modified or removed", release.Name)
+	}
+
+	for i, approval := range oldApprovals {
+		if !reflect.DeepEqual(newApprovals[i], approval) {
+			return fmt.Errorf("approvals of Release %q can not be modified or removed", release.Name)
+		}
+	}
+
+	user := request.UserInfo.Username
+	seen := make(map[shipper.StepApproval]struct{})
+	for i, approval := range newApprovals {
+		key := shipper.StepApproval{Step: approval.Step, User: approval.User}
+		_, duplicate := seen[key]
+		seen[key] = struct{}{}
+
+		if i < len(oldApprovals) {
+			continue
+		}
+
+		if approval.User != user {
+			return fmt.Errorf("approval of step %d by %q can not be recorded by %q", approval.Step, approval.User, user)
+		}
+
+		if duplicate {
+			return fmt.Errorf("step %d was already approved by %q", approval.Step, user)
+		}
+
+		strategy := releaseutil.Strategy(&release)
+		if strategy == nil || approval.Step < 0 || int(approval.Step) >= len(strategy.Steps) ||
+			strategy.Steps[approval.Step].Approvals == 0 {
+			return fmt.Errorf("step %d of Release %q does not require approvals", approval.Step, release.Name)
+		}
+	}
+
+	for step := oldTargetStep + 1; step <= release.Spec.TargetStep; step++ {
+		if missing := releaseutil.MissingApprovals(&release, step); missing > 0 {
+			return fmt.Errorf("step %d of Release %q requires %d more approval(s); approve it with `shipperctl approve release`",
+				step, release.Name, missing)
+		}
+	}
+
+	return nil
+}
+
 func (c *Webhook) validateBlocksForRelease(request *admission.AdmissionRequest, release shipper.Release) error {
 	var err error
-	overrides, existingBlocks, err := rolloutblock.GetAllBlocks(c.rolloutBlocksLister, &release)
+	overrides, existingBlocks, activeBlocks, err := rolloutblock.GetAllBlocks(c.rolloutBlocksLister, &release)
 	if err != nil {
 		return err
 	}
@@ -305,7 +795,7 @@ func (c *Webhook) validateBlocksForRelease(request *admission.AdmissionRequest,
 	}
 	switch request.Operation {
 	case kubeclient.Create:
-		err = rolloutblock.ValidateBlocks(existingBlocks, overrides)
+		err = rolloutblock.ValidateBlocks(activeBlocks, overrides)
 	case kubeclient.Update:
 		var oldRelease shipper.Release
 		err = json.Unmarshal(request.OldObject.Raw, &oldRelease)
@@ -315,7 +805,7 @@ func (c *Webhook) validateBlocksForRelease(request *admission.AdmissionRequest,
 
 		// validate against rollout blocks
 		if !reflect.DeepEqual(release.Spec, oldRelease.Spec) {
-			err = rolloutblock.ValidateBlocks(existingBlocks, overrides)
+			err = rolloutblock.ValidateBlocks(activeBlocks, overrides)
 		}
 
 		// make sure the environment wasn't changed
@@ -329,7 +819,7 @@ func (c *Webhook) validateBlocksForRelease(request *admission.AdmissionRequest,
 
 func (c *Webhook) validateBlocksForApplication(request *admission.AdmissionRequest, application shipper.Application) error {
 	var err error
-	overrides, existingBlocks, err := rolloutblock.GetAllBlocks(c.rolloutBlocksLister, &application)
+	overrides, existingBlocks, activeBlocks, err := rolloutblock.GetAllBlocks(c.rolloutBlocksLister, &application)
 	if err != nil {
 		return err
 	}
@@ -338,7 +828,7 @@ func (c *Webhook) validateBlocksForApplication(request *admission.AdmissionReque
 	}
 	switch request.Operation {
 	case kubeclient.Create:
-		err = rolloutblock.ValidateBlocks(existingBlocks, overrides)
+		err = rolloutblock.ValidateBlocks(activeBlocks, overrides)
 	case kubeclient.Update:
 		var oldApp shipper.Application
 		err = json.Unmarshal(request.OldObject.Raw, &oldApp)
@@ -347,7 +837,7 @@ func (c *Webhook) validateBlocksForApplication(request *admission.AdmissionReque
 		}
 
 		if !reflect.DeepEqual(application.Spec, oldApp.Spec) {
-			err = rolloutblock.ValidateBlocks(existingBlocks, overrides)
+			err = rolloutblock.ValidateBlocks(activeBlocks, overrides)
 		}
 	}
 
@@ -355,14 +845,14 @@ func (c *Webhook) validateBlocksForApplication(request *admission.AdmissionReque
 }
 
 func (c *Webhook) validateBlocksForObject(obj metav1.Object) error {
-	overrides, existingBlocks, err := rolloutblock.GetAllBlocks(c.rolloutBlocksLister, obj)
+	overrides, existingBlocks, activeBlocks, err := rolloutblock.GetAllBlocks(c.rolloutBlocksLister, obj)
 	if err != nil {
 		return err
 	}
 	if err := rolloutblock.ValidateAnnotations(existingBlocks, overrides); err != nil {
 		return err
 	}
-	if err := rolloutblock.ValidateBlocks(existingBlocks, overrides); err != nil {
+	if err := rolloutblock.ValidateBlocks(activeBlocks, overrides); err != nil {
 		return err
 	}
 
@@ -0,0 +1,405 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	admission "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	helmchart "k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/repo"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
+	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+// TestApprovalRoundTrip verifies that an approval added the way `shipperctl
+// approve release` adds it gets recorded as given by the user Kubernetes
+// authenticated the request as, whatever the client claimed, and that the
+// same user can not approve the same step twice.
+func TestApprovalRoundTrip(t *testing.T) {
+	const (
+		authenticatedUser = "jdoe@example.com"
+		approvedStep      = 1
+	)
+
+	webhook := newTestWebhook()
+
+	rel := buildGatedRelease()
+	approved := approveAs(t, webhook, rel, approvedStep, "", authenticatedUser)
+
+	approvals := approved.Status.Approvals
+	if len(approvals) != 1 {
+		t.Fatalf("expected 1 approval, got %v", approvals)
+	}
+
+	if approvals[0].User != authenticatedUser || approvals[0].Step != approvedStep {
+		t.Errorf("expected step %d to be approved by %q, got step %d approved by %q",
+			approvedStep, authenticatedUser, approvals[0].Step, approvals[0].User)
+	}
+
+	// Whoever the client claims to be, the approval goes to the user
+	// making the request, so approving again is a duplicate.
+	forged := approved.DeepCopy()
+	if err := releaseutil.AddApproval(forged, approvedStep, metav1.Now()); err != nil {
+		t.Fatal(err)
+	}
+	forged.Status.Approvals[1].User = "alice"
+
+	review := buildReleaseReview(approved, forged, authenticatedUser)
+	patched := applyMutation(t, webhook, review)
+	review.Request.Object.Raw = patched
+
+	response := webhook.validateHandlerFunc(review)
+	if response.Allowed {
+		t.Errorf("expected a second approval of step %d by %q to be rejected", approvedStep, authenticatedUser)
+	}
+}
+
+// TestOverrideAuditCopiedByShipper verifies that rollout block overrides
+// Shipper copies from a release to its application keep the author and
+// timestamp of the release, while anyone else adding overrides gets stamped as
+// their author.
+func TestOverrideAuditCopiedByShipper(t *testing.T) {
+	const (
+		shipperUser = "system:serviceaccount:shipper-system:shipper-mgmt-cluster"
+		author      = "jdoe@example.com"
+		timestamp   = "2026-10-18T12:00:00Z"
+	)
+
+	rolloutBlock := &shipper.RolloutBlock{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "outage",
+			Namespace: testNamespace,
+		},
+	}
+
+	webhook := newTestWebhook(rolloutBlock)
+	webhook.shipperUsername = shipperUser
+
+	oldApp := &shipper.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-app",
+			Namespace:   testNamespace,
+			Annotations: map[string]string{},
+		},
+	}
+
+	newApp := oldApp.DeepCopy()
+	newApp.Annotations[shipper.RolloutBlocksOverrideAnnotation] = testNamespace + "/outage"
+	newApp.Annotations[shipper.RolloutBlocksOverrideJustificationAnnotation] = "hotfix for an outage"
+	newApp.Annotations[shipper.RolloutBlocksOverrideAuthorAnnotation] = author
+	newApp.Annotations[shipper.RolloutBlocksOverrideTimestampAnnotation] = timestamp
+
+	var tests = []struct {
+		title          string
+		user           string
+		expectedAuthor string
+	}{
+		{"copied by shipper", shipperUser, author},
+		{"added by someone else", "mallory@example.com", "mallory@example.com"},
+	}
+
+	for _, test := range tests {
+		review := &admission.AdmissionReview{
+			Request: &admission.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Group: shipper.SchemeGroupVersion.Group, Version: shipper.SchemeGroupVersion.Version, Kind: "Application"},
+				Operation: admission.Update,
+				Object:    runtime.RawExtension{Raw: mustMarshal(newApp)},
+				OldObject: runtime.RawExtension{Raw: mustMarshal(oldApp)},
+				UserInfo:  authenticationv1.UserInfo{Username: test.user},
+			},
+		}
+
+		patched := applyMutation(t, webhook, review)
+		review.Request.Object.Raw = patched
+
+		response := webhook.validateHandlerFunc(review)
+		if !response.Allowed {
+			t.Errorf("testing %s: expected overrides to be allowed, got: %s", test.title, response.Result.Message)
+			continue
+		}
+
+		var stored shipper.Application
+		if err := json.Unmarshal(patched, &stored); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := stored.Annotations[shipper.RolloutBlocksOverrideAuthorAnnotation]; got != test.expectedAuthor {
+			t.Errorf("testing %s: expected override author %q, got %q", test.title, test.expectedAuthor, got)
+		}
+	}
+}
+
+// TestReleaseCreationKeepsApplicationOverrides verifies that releases can be
+// created with the rollout block overrides of their application, even ones
+// added before overrides needed a justification, but that overrides the
+// application doesn't have still need one.
+func TestReleaseCreationKeepsApplicationOverrides(t *testing.T) {
+	const shipperUser = "system:serviceaccount:shipper-system:shipper-mgmt-cluster"
+
+	rolloutBlock := &shipper.RolloutBlock{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "outage",
+			Namespace: testNamespace,
+		},
+	}
+
+	var tests = []struct {
+		title        string
+		appOverrides string
+		allowed      bool
+	}{
+		{"overrides of the application", testNamespace + "/outage", true},
+		{"overrides the application doesn't have", "", false},
+	}
+
+	for _, test := range tests {
+		app := &shipper.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-app",
+				Namespace: testNamespace,
+				Annotations: map[string]string{
+					shipper.RolloutBlocksOverrideAnnotation: test.appOverrides,
+				},
+			},
+		}
+
+		webhook := newTestWebhook(rolloutBlock, app)
+		webhook.shipperUsername = shipperUser
+
+		rel := buildGatedRelease()
+		rel.Annotations[shipper.RolloutBlocksOverrideAnnotation] = testNamespace + "/outage"
+
+		review := &admission.AdmissionReview{
+			Request: &admission.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Group: shipper.SchemeGroupVersion.Group, Version: shipper.SchemeGroupVersion.Version, Kind: "Release"},
+				Operation: admission.Create,
+				Namespace: testNamespace,
+				Object:    runtime.RawExtension{Raw: mustMarshal(rel)},
+				UserInfo:  authenticationv1.UserInfo{Username: shipperUser},
+			},
+		}
+
+		review.Request.Object.Raw = applyMutation(t, webhook, review)
+
+		response := webhook.validateHandlerFunc(review)
+		if response.Allowed != test.allowed {
+			message := ""
+			if response.Result != nil {
+				message = response.Result.Message
+			}
+			t.Errorf("testing %s: expected allowed to be %t, got %t: %s",
+				test.title, test.allowed, response.Allowed, message)
+		}
+	}
+}
+
+// TestValidateStepReplicasFit verifies that applications and releases can
+// only ask for as many contender replicas as their chart has.
+func TestValidateStepReplicasFit(t *testing.T) {
+	webhook := newTestWebhook()
+	webhook.chartVersionResolver = func(chartspec *shipper.Chart) (*repo.ChartVersion, error) {
+		return &repo.ChartVersion{Metadata: &helmchart.Metadata{Name: chartspec.Name, Version: "0.0.1"}}, nil
+	}
+	webhook.chartFetcher = func(chartspec *shipper.Chart) (*helmchart.Chart, error) {
+		return &helmchart.Chart{
+			Metadata: &helmchart.Metadata{Name: chartspec.Name, Version: chartspec.Version},
+			Templates: []*helmchart.Template{
+				{
+					Name: "templates/deployment.yaml",
+					Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test-app
+spec:
+  replicas: 3
+`),
+				},
+			},
+		}, nil
+	}
+
+	var tests = []struct {
+		title    string
+		replicas int32
+		allowed  bool
+	}{
+		{"replicas fit", 3, true},
+		{"too many replicas", 4, false},
+	}
+
+	for _, test := range tests {
+		replicas := test.replicas
+		app := &shipper.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-app",
+				Namespace: testNamespace,
+			},
+			Spec: shipper.ApplicationSpec{
+				Template: shipper.ReleaseEnvironment{
+					Chart: shipper.Chart{Name: "test-app", Version: "~0.0.1"},
+					Strategy: &shipper.RolloutStrategy{
+						Steps: []shipper.RolloutStrategyStep{
+							{
+								Name: "canary",
+								Capacity: shipper.RolloutStrategyStepValue{
+									ContenderReplicas: &shipper.StepReplicaCount{PerCluster: &replicas},
+								},
+							},
+							{Name: "full on"},
+						},
+					},
+				},
+			},
+		}
+
+		review := &admission.AdmissionReview{
+			Request: &admission.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Group: shipper.SchemeGroupVersion.Group, Version: shipper.SchemeGroupVersion.Version, Kind: "Application"},
+				Operation: admission.Create,
+				Namespace: testNamespace,
+				Object:    runtime.RawExtension{Raw: mustMarshal(app)},
+			},
+		}
+
+		response := webhook.validateHandlerFunc(review)
+		if response.Allowed != test.allowed {
+			message := ""
+			if response.Result != nil {
+				message = response.Result.Message
+			}
+			t.Errorf("testing %s: expected allowed to be %t, got %t: %s",
+				test.title, test.allowed, response.Allowed, message)
+		}
+	}
+}
+
+func newTestWebhook(objects ...runtime.Object) *Webhook {
+	client := shipperfake.NewSimpleClientset()
+	informerFactory := shipperinformers.NewSharedInformerFactory(client, 0)
+	rolloutBlocksInformer := informerFactory.Shipper().V1alpha1().RolloutBlocks()
+	applicationsInformer := informerFactory.Shipper().V1alpha1().Applications()
+
+	for _, object := range objects {
+		switch object.(type) {
+		case *shipper.RolloutBlock:
+			rolloutBlocksInformer.Informer().GetIndexer().Add(object)
+		case *shipper.Application:
+			applicationsInformer.Informer().GetIndexer().Add(object)
+		}
+	}
+
+	return &Webhook{
+		rolloutBlocksLister: rolloutBlocksInformer.Lister(),
+		applicationsLister:  applicationsInformer.Lister(),
+	}
+}
+
+// approveAs adds an approval to rel as `shipperctl approve release` does,
+// claiming to be claimedUser, and runs the update through the mutating and
+// validating webhooks as authenticatedUser. It returns the release as it
+// would be stored.
+func approveAs(
+	t *testing.T,
+	webhook *Webhook,
+	rel *shipper.Release,
+	step int32,
+	claimedUser, authenticatedUser string,
+) *shipper.Release {
+	newRel := rel.DeepCopy()
+	if err := releaseutil.AddApproval(newRel, step, metav1.Now()); err != nil {
+		t.Fatal(err)
+	}
+	newRel.Status.Approvals[len(newRel.Status.Approvals)-1].User = claimedUser
+
+	review := buildReleaseReview(rel, newRel, authenticatedUser)
+	patched := applyMutation(t, webhook, review)
+	review.Request.Object.Raw = patched
+
+	response := webhook.validateHandlerFunc(review)
+	if !response.Allowed {
+		t.Fatalf("expected approval to be allowed, got: %s", response.Result.Message)
+	}
+
+	var stored shipper.Release
+	if err := json.Unmarshal(patched, &stored); err != nil {
+		t.Fatal(err)
+	}
+
+	return &stored
+}
+
+func applyMutation(t *testing.T, webhook *Webhook, review *admission.AdmissionReview) []byte {
+	response := webhook.mutateHandlerFunc(review)
+	if !response.Allowed {
+		t.Fatalf("expected mutation to be allowed, got: %s", response.Result.Message)
+	}
+
+	raw := review.Request.Object.Raw
+	if response.Patch == nil {
+		return raw
+	}
+
+	patch, err := jsonpatch.DecodePatch(response.Patch)
+	if err != nil {
+		t.Fatalf("could not decode patch %s: %s", response.Patch, err)
+	}
+
+	patched, err := patch.Apply(raw)
+	if err != nil {
+		t.Fatalf("could not apply patch %s: %s", response.Patch, err)
+	}
+
+	return patched
+}
+
+func buildReleaseReview(oldRel, newRel *shipper.Release, user string) *admission.AdmissionReview {
+	return &admission.AdmissionReview{
+		Request: &admission.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind{Group: shipper.SchemeGroupVersion.Group, Version: shipper.SchemeGroupVersion.Version, Kind: "Release"},
+			Operation: admission.Update,
+			Object:    runtime.RawExtension{Raw: mustMarshal(newRel)},
+			OldObject: runtime.RawExtension{Raw: mustMarshal(oldRel)},
+			UserInfo:  authenticationv1.UserInfo{Username: user},
+		},
+	}
+}
+
+func buildGatedRelease() *shipper.Release {
+	return &shipper.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testRelease,
+			Namespace: testNamespace,
+			Labels: map[string]string{
+				shipper.AppLabel:     "test-app",
+				shipper.ReleaseLabel: testRelease,
+			},
+			Annotations: map[string]string{},
+		},
+		Spec: shipper.ReleaseSpec{
+			Environment: shipper.ReleaseEnvironment{
+				Strategy: &shipper.RolloutStrategy{
+					Steps: []shipper.RolloutStrategyStep{
+						{Name: "staging"},
+						{Name: "full on", Approvals: 2},
+					},
+				},
+			},
+		},
+	}
+}
+
+func mustMarshal(obj interface{}) []byte {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		panic(err)
+	}
+
+	return raw
+}